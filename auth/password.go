@@ -0,0 +1,29 @@
+// Package auth holds the credential primitives shared by users, API keys and protected links,
+// and carries the authenticated caller through a request context.
+package auth
+
+import (
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MaxPasswordLength is the most bytes bcrypt hashes; longer passwords are rejected rather than
+// silently cut short.
+const MaxPasswordLength = 72
+
+// passwordCost is the bcrypt work factor. Hashes record their cost, so it can be raised later
+// without invalidating stored hashes.
+const passwordCost = bcrypt.DefaultCost
+
+// HashPassword returns a salted bcrypt hash of password.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), passwordCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// VerifyPassword reports whether password matches a hash produced by HashPassword.
+func VerifyPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
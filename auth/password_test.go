@@ -0,0 +1,27 @@
+package auth_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Kritvi0208/ShortEdge/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashPassword(t *testing.T) {
+	hash, err := auth.HashPassword("correct horse")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$2a$"), "bcrypt hash")
+
+	other, err := auth.HashPassword("correct horse")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other, "salt must differ per hash")
+
+	assert.True(t, auth.VerifyPassword(hash, "correct horse"))
+	assert.False(t, auth.VerifyPassword(hash, "battery staple"))
+	assert.False(t, auth.VerifyPassword("garbage", "correct horse"))
+
+	_, err = auth.HashPassword(strings.Repeat("x", auth.MaxPasswordLength+1))
+	assert.Error(t, err, "bcrypt ignores bytes past the limit")
+}
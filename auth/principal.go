@@ -0,0 +1,65 @@
+package auth
+
+import "context"
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Scopes an API key can carry.
+const (
+	ScopeLinksRead     = "links:read"
+	ScopeLinksWrite    = "links:write"
+	ScopeAnalyticsRead = "analytics:read"
+	ScopeAdmin         = "admin"
+)
+
+var AllScopes = []string{ScopeLinksRead, ScopeLinksWrite, ScopeAnalyticsRead, ScopeAdmin}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	UserID    int64
+	Email     string
+	Role      string
+	SessionID string   // hash of the session token, empty for other credentials
+	APIKeyID  int64    // set when authenticated with an API key
+	Scopes    []string // nil for sessions, which act with the full rights of the user's role
+	// WorkspaceID is the user's default workspace, 0 if they have none.
+	WorkspaceID int64
+}
+
+// IsAdmin reports admin rights. An admin's API key only carries them with the admin scope.
+func (p Principal) IsAdmin() bool {
+	return p.Role == RoleAdmin && (p.Scopes == nil || contains(p.Scopes, ScopeAdmin))
+}
+
+// HasScope reports whether the caller may perform operations guarded by scope. The admin scope
+// implies every other scope.
+func (p Principal) HasScope(scope string) bool {
+	if p.Scopes == nil {
+		return scope != ScopeAdmin || p.Role == RoleAdmin
+	}
+	return contains(p.Scopes, scope) || contains(p.Scopes, ScopeAdmin)
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the caller, ok is false for anonymous requests.
+func FromContext(ctx context.Context) (p Principal, ok bool) {
+	p, ok = ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
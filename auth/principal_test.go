@@ -0,0 +1,22 @@
+package auth_test
+
+import (
+	"testing"
+
+	"github.com/Kritvi0208/ShortEdge/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipalScopes(t *testing.T) {
+	session := auth.Principal{Role: auth.RoleUser}
+	assert.True(t, session.HasScope(auth.ScopeLinksWrite))
+	assert.False(t, session.HasScope(auth.ScopeAdmin))
+
+	adminKey := auth.Principal{Role: auth.RoleAdmin, Scopes: []string{auth.ScopeLinksRead}}
+	assert.False(t, adminKey.IsAdmin())
+	assert.False(t, adminKey.HasScope(auth.ScopeLinksWrite))
+
+	fullKey := auth.Principal{Role: auth.RoleAdmin, Scopes: []string{auth.ScopeAdmin}}
+	assert.True(t, fullKey.IsAdmin())
+	assert.True(t, fullKey.HasScope(auth.ScopeAnalyticsRead))
+}
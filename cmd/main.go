@@ -7,15 +7,33 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Kritvi0208/ShortEdge/auth"
+	"github.com/Kritvi0208/ShortEdge/codegen"
+	"github.com/Kritvi0208/ShortEdge/codepolicy"
 	_ "github.com/Kritvi0208/ShortEdge/docs" 
 	"github.com/Kritvi0208/ShortEdge/factory"
+	"github.com/Kritvi0208/ShortEdge/geo"
 	"github.com/Kritvi0208/ShortEdge/handler"
+	"github.com/Kritvi0208/ShortEdge/health"
+	"github.com/Kritvi0208/ShortEdge/metadata"
 	"github.com/Kritvi0208/ShortEdge/middleware"
+	"github.com/Kritvi0208/ShortEdge/oidc"
+	"github.com/Kritvi0208/ShortEdge/ratelimit"
+	"github.com/Kritvi0208/ShortEdge/reqinfo"
+	"github.com/Kritvi0208/ShortEdge/safehttp"
+	"github.com/Kritvi0208/ShortEdge/screening"
 	"github.com/Kritvi0208/ShortEdge/service"
+	"github.com/Kritvi0208/ShortEdge/urlnorm"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/joho/godotenv"
 	//"github.com/prometheus/client_golang/prometheus/promhttp"
 	//httpSwagger "github.com/swaggo/http-swagger"
@@ -35,28 +53,400 @@ func main() {
 
 	app := gofr.New()
 
+	app.UseMiddleware(middleware.RequestInfo(app.Config.Get("TRUST_PROXY_HEADERS") == "true"))
+
+	// User Account Dependencies
+	userStore := factory.NewUserStore(app)
+	workspaceStore := factory.NewWorkspaceStore(app)
+	userService := service.NewUserService(userStore,
+		time.Duration(configInt(app, "SESSION_TTL_HOURS", 24*7))*time.Hour)
+
+	// "promote-admin <email>" makes an existing account a platform admin and exits
+	if len(os.Args) == 3 && os.Args[1] == "promote-admin" {
+		user, err := userService.PromoteAdmin(context.Background(), os.Args[2])
+		if err != nil {
+			log.Fatalf("❌ Could not promote %s: %v", os.Args[2], err)
+		}
+		log.Printf("✅ %s is now an admin", user.Email)
+		return
+	}
+	userHandler := handler.NewUserHandler(userService)
+
+	// API Key Dependencies
+	apiKeyService := service.NewAPIKeyService(factory.NewAPIKeyStore(app), userStore)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+
+	// Single Sign-On Dependencies
+	var ssoService service.SSOService
+	if jwksURL := app.Config.Get("OAUTH_JWKS_URL"); jwksURL != "" {
+		keys, err := oidc.NewKeySet(context.Background(), jwksURL, nil)
+		if err != nil {
+			log.Printf("⚠️ Could not load OAuth signing keys yet: %v", err)
+		}
+		go keys.Run(context.Background(), time.Duration(configInt(app, "OAUTH_JWKS_REFRESH_SECONDS", 300))*time.Second)
+
+		var opts []jwt.ParserOption
+		if issuer := app.Config.Get("OAUTH_ISSUER"); issuer != "" {
+			opts = append(opts, jwt.WithIssuer(issuer))
+		}
+		if audience := app.Config.Get("OAUTH_AUDIENCE"); audience != "" {
+			opts = append(opts, jwt.WithAudience(audience))
+		}
+
+		groupRoles, err := service.ParseGroupRoles(configList(app, "OAUTH_GROUP_ROLES"))
+		if err != nil {
+			log.Fatalf("❌ Invalid OAUTH_GROUP_ROLES: %v", err)
+		}
+		ssoService = service.NewSSOService(userStore, workspaceStore, service.ClaimMapping{
+			EmailClaim:  app.Config.Get("OAUTH_EMAIL_CLAIM"),
+			GroupsClaim: app.Config.Get("OAUTH_GROUPS_CLAIM"),
+			AdminGroups: configList(app, "OAUTH_ADMIN_GROUPS"),
+			GroupRoles:  groupRoles,
+		})
+
+		// gofr's JWT validation, fed by a key set that is usable right away.
+		app.UseMiddleware(middleware.OAuth(keys, app.Config.Get("OAUTH_REQUIRED") == "true", opts...))
+	}
+	app.UseMiddleware(middleware.Authenticate(userService, apiKeyService, ssoService))
+
+	// Rate Limiting, per client and class of route
+	createClass := middleware.RateClass{Name: "create", Limit: configLimit(app, "RATE_LIMIT_CREATE", "30/m")}
+	analyticsClass := middleware.RateClass{Name: "analytics", Limit: configLimit(app, "RATE_LIMIT_ANALYTICS", "60/m")}
+	redirectClass := middleware.RateClass{Name: "redirect", Limit: configLimit(app, "RATE_LIMIT_REDIRECT", "300/m")}
+	rateLimits := factory.NewRateLimitBackend(app)
+	app.UseMiddleware(middleware.RateLimit(rateLimits, middleware.RouteClasses(
+		map[string]middleware.RateClass{
+			"POST /shorten":         createClass,
+			"GET /analytics":        analyticsClass,
+			"GET /analytics/{code}": analyticsClass,
+			"GET /{code}":           redirectClass,
+			"GET /{code}+":          redirectClass,
+			"POST /{code}":          redirectClass,
+		},
+		middleware.RateClass{Name: "default", Limit: configLimit(app, "RATE_LIMIT_DEFAULT", "")})))
+
+	readLinks := middleware.RequireScope(auth.ScopeLinksRead)
+	writeLinks := middleware.RequireScope(auth.ScopeLinksWrite)
+	readAnalytics := middleware.RequireScope(auth.ScopeAnalyticsRead)
+
+	// Tag & Folder Dependencies
+	tagStore := factory.NewTagStore(app)
+	tagHandler := handler.NewTagHandler(service.NewTagService(tagStore))
+	folderStore := factory.NewFolderStore(app)
+	folderHandler := handler.NewFolderHandler(service.NewFolderService(folderStore))
+
+	// Destination Metadata Dependencies
+	fetcher := metadata.NewFetcher(metadata.Config{
+		Timeout:      time.Duration(configInt(app, "METADATA_FETCH_TIMEOUT_SECONDS", 10)) * time.Second,
+		MaxBytes:     int64(configInt(app, "METADATA_MAX_BYTES", 1<<20)),
+		MaxRedirects: configInt(app, "METADATA_MAX_REDIRECTS", 5),
+	})
+	metadataService := service.NewMetadataService(factory.NewMetadataStore(app), fetcher,
+		time.Duration(configInt(app, "METADATA_MAX_AGE_HOURS", 24*7))*time.Hour,
+		configInt(app, "METADATA_REFRESH_BATCH", 100))
+
+	// Refresh outdated metadata and catch links whose initial fetch was skipped.
+	app.AddCronJob(app.Config.GetOrDefault("METADATA_REFRESH_SCHEDULE", "*/30 * * * *"), "refresh-link-metadata",
+		func(ctx *gofr.Context) {
+			n, err := metadataService.RefreshStale(ctx)
+			if err != nil {
+				ctx.Errorf("metadata refresh failed: %v", err)
+				return
+			}
+			ctx.Infof("refreshed metadata of %d links", n)
+		})
+
+	// Workspace Dependencies
+	workspaceHandler := handler.NewWorkspaceHandler(service.NewWorkspaceService(workspaceStore,
+		time.Duration(configInt(app, "INVITATION_TTL_HOURS", 24*7))*time.Hour))
+
+	// Branded Domain Dependencies
+	domainStore := factory.NewDomainStore(app)
+	domainService := service.NewDomainService(domainStore, workspaceStore,
+		safehttp.NewClient(safehttp.Config{
+			Timeout:      10 * time.Second,
+			MaxRedirects: -1,
+			AllowPrivate: app.Config.Get("DOMAIN_VERIFY_ALLOW_PRIVATE") == "true",
+		}),
+		app.Config.Get("DOMAIN_VERIFY_PORT"))
+	domainHandler := handler.NewDomainHandler(domainService)
+	app.UseMiddleware(middleware.DomainRoot(domainService))
+
+	// Quota Dependencies
+	plans, err := service.LoadPlans(app.Config.Get("PLANS_FILE"))
+	if err != nil {
+		log.Fatalf("❌ Invalid PLANS_FILE: %v", err)
+	}
+	quotaService, err := service.NewQuotaService(factory.NewUsageStore(app), workspaceStore, plans,
+		app.Config.GetOrDefault("DEFAULT_PLAN", service.DefaultPlan))
+	if err != nil {
+		log.Fatalf("❌ Invalid DEFAULT_PLAN: %v", err)
+	}
+	usageHandler := handler.NewUsageHandler(quotaService)
+
+	// Visitor countries, for analytics and targeting rules
+	geoResolver := geo.NewIPWhoIs(&http.Client{Timeout: 2 * time.Second}, time.Hour)
+
+	// Destination Screening Dependencies
+	var scanners []screening.Scanner
+	if scannerURL := app.Config.Get("SCREENING_SCANNER_URL"); scannerURL != "" {
+		scanners = append(scanners, screening.NewHTTPScanner(scannerURL, app.Config.Get("SCREENING_SCANNER_TOKEN"),
+			&http.Client{Timeout: 5 * time.Second}))
+	}
+	screener, err := screening.New(screening.Config{
+		Schemes:       configList(app, "SCREENING_ALLOWED_SCHEMES"),
+		BlocklistFile: app.Config.Get("SCREENING_BLOCKLIST_FILE"),
+		AllowIPHosts:  app.Config.Get("SCREENING_ALLOW_IP_HOSTS") == "true",
+		Scanners:      scanners,
+	})
+	if err != nil {
+		log.Fatalf("❌ Invalid SCREENING_BLOCKLIST_FILE: %v", err)
+	}
+
+	// Destination Normalization Dependencies
+	normalizer := urlnorm.New(urlnorm.Config{
+		MaxLength:        configInt(app, "URL_MAX_LENGTH", urlnorm.DefaultMaxLength),
+		StripDefaultPort: app.Config.GetOrDefault("URL_STRIP_DEFAULT_PORT", "true") == "true",
+		StripFragment:    app.Config.Get("URL_STRIP_FRAGMENT") == "true",
+		OwnHosts:         append(configList(app, "SHORTEDGE_HOSTS"), app.Config.Get("BASE_URL")),
+	})
+
+	// Default style of generated codes; workspaces and domains can choose their own
+	codeStyle := app.Config.GetOrDefault("CODE_STYLE", codegen.DefaultSpec)
+	if err := codegen.Validate(codeStyle); err != nil {
+		log.Fatalf("❌ Invalid CODE_STYLE: %v", err)
+	}
+	// Sequential codes are shuffled with this key; changing it could hand out codes again
+	codeIDKey := []byte(app.Config.Get("CODE_ID_KEY"))
+	if len(codeIDKey) > 0 && len(codeIDKey) < 16 {
+		log.Fatalf("❌ CODE_ID_KEY needs at least 16 characters")
+	}
+	// Rules for custom codes; the first segments of routes are reserved as they're registered
+	codePolicy, err := codepolicy.New(codepolicy.Config{
+		MinLength: configInt(app, "CUSTOM_CODE_MIN_LENGTH", codepolicy.DefaultMinLength),
+		MaxLength: configInt(app, "CUSTOM_CODE_MAX_LENGTH", codepolicy.DefaultMaxLength),
+		Extra:     app.Config.Get("CUSTOM_CODE_CHARS"),
+		Case:      app.Config.Get("CUSTOM_CODE_CASE"),
+		Reserved:  configList(app, "RESERVED_CODES"),
+		Profanity: app.Config.Get("CODE_PROFANITY_FILTER") == "true",
+		Blocked:   configList(app, "CODE_BLOCKED_WORDS"),
+	})
+	if err != nil {
+		log.Fatalf("❌ Invalid custom code policy: %v", err)
+	}
+
+	// URL Shortener Dependencies
+	urlStore := factory.NewURLStore(app)
+	healthStore := factory.NewHealthStore(app)
+	variantStore := factory.NewVariantStore(app)
+	internalNetworks, err := reqinfo.ParsePrefixes(strings.Split(app.Config.Get("INTERNAL_IP_RANGES"), ","))
+	if err != nil {
+		log.Fatalf("❌ Invalid INTERNAL_IP_RANGES: %v", err)
+	}
+	urlService := service.New(urlStore, service.WithTagStore(tagStore), service.WithFolderStore(folderStore),
+		service.WithMetadataService(metadataService), service.WithInternalNetworks(internalNetworks),
+		service.WithWorkspaceStore(workspaceStore), service.WithQuotaService(quotaService),
+		service.WithPasswordAttempts(rateLimits, configLimit(app, "LINK_PASSWORD_ATTEMPTS", "5/15m")),
+		service.WithExhaustedURL(app.Config.Get("CLICK_LIMIT_FALLBACK_URL")),
+		service.WithScheduleStore(factory.NewScheduleStore(app)),
+		service.WithComingSoonURL(app.Config.Get("COMING_SOON_URL")),
+		service.WithTargeting(factory.NewTargetingStore(app), geoResolver),
+		service.WithVariantStore(variantStore),
+		service.WithDomainStore(domainStore),
+		service.WithHealthStore(healthStore),
+		service.WithScreener(screener),
+		service.WithNormalizer(normalizer),
+		service.WithDedupe(app.Config.Get("DEDUPE_LINKS") == "true"),
+		service.WithCodeStyle(codeStyle),
+		service.WithIDBlocks(factory.NewIDBlockStore(app), codeIDKey, int64(configInt(app, "CODE_ID_BLOCK_SIZE", codegen.DefaultBlockSize))),
+		service.WithCodePolicy(codePolicy))
+
+	// Apply due destination changes of links that aren't being opened.
+	app.AddCronJob("* * * * *", "apply-scheduled-changes", func(ctx *gofr.Context) {
+		n, err := urlService.ApplyScheduledChanges(ctx)
+		if err != nil {
+			ctx.Errorf("applying scheduled changes failed: %v", err)
+			return
+		}
+		if n > 0 {
+			ctx.Infof("applied scheduled changes to %d links", n)
+		}
+	})
+
+	// Disable links whose destinations were flagged since they were created.
+	app.AddCronJob(app.Config.GetOrDefault("SCREENING_RESCAN_SCHEDULE", "0 * * * *"), "rescreen-links",
+		func(ctx *gofr.Context) {
+			n, err := urlService.RescreenLinks(ctx)
+			if err != nil {
+				ctx.Errorf("rescreening links failed: %v", err)
+				return
+			}
+			if n > 0 {
+				ctx.Infof("disabled %d links with unsafe destinations", n)
+			}
+		})
+
+	// Destination Health Dependencies
+	healthService := service.NewHealthService(healthStore, urlService,
+		health.NewChecker(health.Config{
+			Timeout:      time.Duration(configInt(app, "HEALTH_CHECK_TIMEOUT_SECONDS", 10)) * time.Second,
+			MaxRedirects: configInt(app, "HEALTH_CHECK_MAX_REDIRECTS", 10),
+		}),
+		service.HealthConfig{
+			Interval:    time.Duration(configInt(app, "HEALTH_CHECK_INTERVAL_HOURS", 6)) * time.Hour,
+			Batch:       configInt(app, "HEALTH_CHECK_BATCH", 100),
+			BrokenAfter: configInt(app, "HEALTH_BROKEN_AFTER", 3),
+		})
+	linkHealthHandler := handler.NewLinkHealthHandler(healthService)
+
+	// Check destinations that are due, so broken links show up before customers notice.
+	app.AddCronJob(app.Config.GetOrDefault("HEALTH_CHECK_SCHEDULE", "*/15 * * * *"), "check-link-health",
+		func(ctx *gofr.Context) {
+			n, err := healthService.CheckDue(ctx)
+			if err != nil {
+				ctx.Errorf("link health check failed: %v", err)
+				return
+			}
+			ctx.Infof("checked the destinations of %d links", n)
+		})
+
 	// Visit Analytics Dependencies
 	visitStore := factory.NewVisitStore(app)
-	visitService := service.NewVisitService(visitStore)
+	visitService := service.NewVisitService(visitStore, urlService, service.WithClickQuota(quotaService),
+		service.WithVariantNames(variantStore))
 	visitHandler := handler.NewVisitHandler(visitService)
 
-	// URL Shortener Dependencies
-	urlStore := factory.NewURLStore(app)
-	urlService := service.New(urlStore)
-	urlHandler := handler.NewURLHandler(urlService, visitService)
+	wellKnownHandler := handler.NewWellKnownHandler(configList(app, "IOS_APP_IDS"),
+		app.Config.Get("ANDROID_APP_PACKAGE"), configList(app, "ANDROID_CERT_FINGERPRINTS"))
+
+	urlHandler := handler.NewURLHandler(urlService, visitService, geoResolver)
+	qrHandler := handler.NewQRHandler(urlService, app.Config.Get("BASE_URL"))
 	//fileserver, router.handle, promhttp, metricshandler
 	// Routes
 	//app.Server().Handle("/swagger-ui/", http.StripPrefix("/swagger-ui/", http.FileServer(http.Dir("./swagger-ui"))))
 	//app.GET("/swagger/*", gofrSwagger.NewHandler())
-	app.GET("/all", middleware.RedirectMiddleware(urlHandler.GetAll))
-	app.GET("/health", handler.HealthHandler)
+	routes := reservingRoutes{app: app, policy: codePolicy}
+	routes.GET("/all", readLinks(urlHandler.GetAll))
+	routes.GET("/health", handler.HealthHandler)
+	routes.POST("/register", userHandler.Register)
+	routes.POST("/login", userHandler.Login)
+	routes.POST("/logout", userHandler.Logout)
+	routes.GET("/me", userHandler.Me)
+	routes.GET("/api-keys", apiKeyHandler.GetAll)
+	routes.POST("/api-keys", apiKeyHandler.Create)
+	routes.POST("/api-keys/{id}/rotate", apiKeyHandler.Rotate)
+	routes.DELETE("/api-keys/{id}", apiKeyHandler.Revoke)
 	//app.Router.Handle("/metrics", http.HandlerFunc(promhttp.Handler().ServeHTTP))
 	//app.GET("/metrics", app.MetricsHandler())
-	app.POST("/shorten", middleware.RedirectMiddleware(urlHandler.Shorten))
-	app.PUT("/update/{code}", middleware.RedirectMiddleware(urlHandler.Update))
-	app.DELETE("/delete/{code}", middleware.RedirectMiddleware(urlHandler.Delete))
-	app.GET("/analytics/{code}", middleware.RedirectMiddleware(visitHandler.GetAnalytics))
-	app.GET("/{code}", middleware.RedirectMiddleware(urlHandler.Redirect))
+	routes.POST("/shorten", writeLinks(urlHandler.Shorten))
+	routes.PUT("/update/{code}", writeLinks(urlHandler.Update))
+	routes.DELETE("/delete/{code}", writeLinks(urlHandler.Delete))
+	routes.GET("/analytics", readAnalytics(visitHandler.GetFilteredAnalytics))
+	routes.GET("/analytics/{code}", readAnalytics(visitHandler.GetAnalytics))
+	routes.GET("/analytics/{code}/rules", readAnalytics(visitHandler.RuleBreakdown))
+	routes.GET("/analytics/{code}/variants", readAnalytics(visitHandler.VariantBreakdown))
+	routes.GET("/analytics/{code}/previews", readAnalytics(visitHandler.PreviewStats))
+	routes.GET("/analytics/{code}/sources", readAnalytics(visitHandler.SourceBreakdown))
+	routes.GET("/links/health", readLinks(linkHealthHandler.Report))
+	routes.POST("/links/{code}/tags", writeLinks(urlHandler.AttachTags))
+	routes.DELETE("/links/{code}/tags", writeLinks(urlHandler.DetachTags))
+	routes.POST("/links/{code}/move", writeLinks(urlHandler.Move))
+	routes.GET("/links/{code}/schedule", readLinks(urlHandler.ScheduledChanges))
+	routes.POST("/links/{code}/schedule", writeLinks(urlHandler.ScheduleChange))
+	routes.DELETE("/links/{code}/schedule/{id}", writeLinks(urlHandler.CancelChange))
+	routes.GET("/links/{code}/rules", readLinks(urlHandler.TargetingRules))
+	routes.PUT("/links/{code}/rules", writeLinks(urlHandler.SetTargetingRules))
+	routes.GET("/links/{code}/variants", readLinks(urlHandler.Variants))
+	routes.PUT("/links/{code}/variants", writeLinks(urlHandler.SetVariants))
+	routes.GET("/tags", readLinks(tagHandler.GetAll))
+	routes.POST("/tags", writeLinks(tagHandler.Create))
+	routes.PUT("/tags/{id}", writeLinks(tagHandler.Rename))
+	routes.POST("/tags/{id}/merge", writeLinks(tagHandler.Merge))
+	routes.DELETE("/tags/{id}", writeLinks(tagHandler.Delete))
+	routes.GET("/folders", readLinks(folderHandler.GetAll))
+	routes.POST("/folders", writeLinks(folderHandler.Create))
+	routes.PUT("/folders/{id}", writeLinks(folderHandler.Update))
+	routes.DELETE("/folders/{id}", writeLinks(folderHandler.Delete))
+	routes.GET("/workspaces", readLinks(workspaceHandler.GetAll))
+	routes.POST("/workspaces", writeLinks(workspaceHandler.Create))
+	routes.PUT("/workspaces/{id}", writeLinks(workspaceHandler.Rename))
+	routes.GET("/workspaces/{id}/members", readLinks(workspaceHandler.Members))
+	routes.PUT("/workspaces/{id}/members/{user_id}", writeLinks(workspaceHandler.SetMemberRole))
+	routes.DELETE("/workspaces/{id}/members/{user_id}", writeLinks(workspaceHandler.RemoveMember))
+	routes.GET("/workspaces/{id}/invitations", readLinks(workspaceHandler.Invitations))
+	routes.POST("/workspaces/{id}/invitations", writeLinks(workspaceHandler.Invite))
+	routes.DELETE("/workspaces/{id}/invitations/{invitation_id}", writeLinks(workspaceHandler.RevokeInvitation))
+	routes.PUT("/workspaces/{id}/plan", writeLinks(usageHandler.SetPlan))
+	routes.POST("/invitations/accept", writeLinks(workspaceHandler.AcceptInvitation))
+	routes.GET("/usage", readLinks(usageHandler.Get))
+	routes.GET("/domains", readLinks(domainHandler.GetAll))
+	routes.POST("/domains", writeLinks(domainHandler.Create))
+	routes.PUT("/domains/{id}", writeLinks(domainHandler.Update))
+	routes.DELETE("/domains/{id}", writeLinks(domainHandler.Delete))
+	routes.POST("/domains/{id}/verify", writeLinks(domainHandler.Verify))
+	routes.GET("/qr/{code}", qrHandler.Get)
+	routes.GET("/.well-known/shortedge-verification", domainHandler.VerificationToken)
+	routes.GET("/.well-known/apple-app-site-association", wellKnownHandler.AppleAppSiteAssociation)
+	routes.GET("/.well-known/assetlinks.json", wellKnownHandler.AssetLinks)
+	// The preview route must come first: routes are matched in order and /{code} matches "abc+" too
+	routes.GET("/{code}+", urlHandler.Preview)
+	routes.GET("/{code}", urlHandler.Redirect)
+	routes.POST("/{code}", urlHandler.Unlock)
 
 	app.Run()
 }
+
+// reservingRoutes registers routes and reserves their first segments, so no custom code can
+// hide a route.
+type reservingRoutes struct {
+	app    *gofr.App
+	policy *codepolicy.Policy
+}
+
+func (r reservingRoutes) GET(path string, h gofr.Handler) {
+	r.policy.ReserveRoute(path)
+	r.app.GET(path, h)
+}
+
+func (r reservingRoutes) POST(path string, h gofr.Handler) {
+	r.policy.ReserveRoute(path)
+	r.app.POST(path, h)
+}
+
+func (r reservingRoutes) PUT(path string, h gofr.Handler) {
+	r.policy.ReserveRoute(path)
+	r.app.PUT(path, h)
+}
+
+func (r reservingRoutes) DELETE(path string, h gofr.Handler) {
+	r.policy.ReserveRoute(path)
+	r.app.DELETE(path, h)
+}
+
+func configInt(app *gofr.App, key string, def int) int {
+	v, err := strconv.Atoi(app.Config.Get(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// configLimit reads a rate limit such as "30/m"; an empty value disables the limit.
+func configLimit(app *gofr.App, key, def string) ratelimit.Limit {
+	limit, err := ratelimit.ParseLimit(app.Config.GetOrDefault(key, def))
+	if err != nil {
+		log.Fatalf("❌ Invalid %s: %v", key, err)
+	}
+	return limit
+}
+
+// configList splits a comma separated config value, dropping empty entries.
+func configList(app *gofr.App, key string) []string {
+	var list []string
+	for _, v := range strings.Split(app.Config.Get(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,80 @@
+package codegen
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Adaptive codes start short and grow by a character whenever collisions become common, so
+// codes stay as short as the table allows without retry loops getting long as it fills.
+const (
+	adaptiveWindow    = 100 // candidates the collision rate is measured over
+	adaptiveThreshold = 10  // percent of candidates colliding that makes codes longer
+	adaptiveStreak    = 3   // collisions in a row that make codes longer at once
+)
+
+// Adaptive is a random generator whose length grows from min to max as its codes collide more
+// often. Growth isn't persisted: after a restart it starts at min and grows again as needed.
+type Adaptive struct {
+	alphabet string
+	max      int
+
+	mu         sync.Mutex
+	length     int
+	candidates int
+	collisions int
+	streak     int
+}
+
+func NewAdaptive(alphabet string, min, max int) (*Adaptive, error) {
+	if _, err := NewRandom(alphabet, min); err != nil {
+		return nil, err
+	}
+	if max < min || max > maxLength {
+		return nil, fmt.Errorf("maximum code length must be between %d and %d", min, maxLength)
+	}
+	return &Adaptive{alphabet: alphabet, length: min, max: max}, nil
+}
+
+func (a *Adaptive) Generate(context.Context) (string, error) {
+	return randomString(a.alphabet, a.Length())
+}
+
+// Length is the current code length.
+func (a *Adaptive) Length() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.length
+}
+
+func (a *Adaptive) Collided() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.candidates++
+	a.collisions++
+	a.streak++
+	if a.streak >= adaptiveStreak || a.collisions*100 > adaptiveThreshold*adaptiveWindow {
+		a.grow()
+	}
+}
+
+func (a *Adaptive) Accepted() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.candidates++
+	a.streak = 0
+	if a.candidates >= adaptiveWindow {
+		a.candidates, a.collisions = 0, 0
+	}
+}
+
+// grow lengthens codes and starts measuring afresh; mu must be held.
+func (a *Adaptive) grow() {
+	if a.length < a.max {
+		a.length++
+	}
+	a.candidates, a.collisions, a.streak = 0, 0, 0
+}
@@ -0,0 +1,233 @@
+// Package codegen generates short codes for links. Styles are chosen with specs such as
+// "random:8", "nolookalike:7", "words:3", "adaptive:6-10" or "sequential:7", so they can be
+// configured per deployment, workspace or branded domain.
+package codegen
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Alphabets for random codes. NoLookalike leaves out characters that are easily confused when
+// a code is read out or typed from print: 0/O/o, 1/l/I.
+const (
+	Base62      = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	NoLookalike = "23456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnpqrstuvwxyz"
+)
+
+// DefaultSpec is the style used when none is configured: six crypto-random base62 characters.
+const DefaultSpec = "random:6"
+
+const maxLength = 64
+
+// Generator makes candidate codes; callers check them for collisions themselves unless the
+// generator is CollisionFree.
+type Generator interface {
+	Generate(ctx context.Context) (string, error)
+}
+
+// CollisionFree is implemented by generators that never repeat a code, so callers can skip
+// looking up whether one is taken. Codes chosen by users may still clash with them.
+type CollisionFree interface {
+	CollisionFree()
+}
+
+// Observer is implemented by generators that adapt to how often their codes collide. Callers
+// report every candidate: taken ones with Collided, used ones with Accepted.
+type Observer interface {
+	Collided()
+	Accepted()
+}
+
+// Random draws each character uniformly from an alphabet using crypto/rand.
+type Random struct {
+	alphabet string
+	length   int
+}
+
+func NewRandom(alphabet string, length int) (*Random, error) {
+	if err := validAlphabet(alphabet); err != nil {
+		return nil, err
+	}
+	if length < 1 || length > maxLength {
+		return nil, fmt.Errorf("code length must be between 1 and %d", maxLength)
+	}
+	return &Random{alphabet: alphabet, length: length}, nil
+}
+
+func (r *Random) Generate(context.Context) (string, error) {
+	return randomString(r.alphabet, r.length)
+}
+
+func randomString(alphabet string, length int) (string, error) {
+	b := make([]byte, length)
+	n := big.NewInt(int64(len(alphabet)))
+	for i := range b {
+		// rand.Int is uniform, unlike taking a random byte modulo the alphabet size
+		k, err := rand.Int(rand.Reader, n)
+		if err != nil {
+			return "", err
+		}
+		b[i] = alphabet[k.Int64()]
+	}
+	return string(b), nil
+}
+
+// validAlphabet accepts at least two distinct letters, digits, '-' or '_'; anything else could
+// clash with routing ('/', '+', '@') or need escaping.
+func validAlphabet(alphabet string) error {
+	if len(alphabet) < 2 {
+		return errors.New("alphabet needs at least 2 characters")
+	}
+	seen := make(map[rune]bool, len(alphabet))
+	for _, c := range alphabet {
+		if !isCodeChar(c) {
+			return fmt.Errorf("alphabet can only contain letters, digits, '-' and '_', not %q", c)
+		}
+		if seen[c] {
+			return fmt.Errorf("alphabet repeats %q", c)
+		}
+		seen[c] = true
+	}
+	return nil
+}
+
+func isCodeChar(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_'
+}
+
+// Parser builds generators from specs; Leaser and Key are only needed for sequential codes.
+type Parser struct {
+	Leaser    Leaser
+	Key       []byte // keeps the order of sequential codes secret; at least 16 bytes
+	BlockSize int64  // IDs leased at a time, DefaultBlockSize if 0
+}
+
+// Parse builds a generator from a spec without support for sequential codes.
+func Parse(spec string) (Generator, error) {
+	return Parser{}.Parse(spec)
+}
+
+// Validate checks a spec, including sequential ones, without building a generator.
+func Validate(spec string) error {
+	_, err := Parser{Leaser: LeaserFunc(noLeases), Key: make([]byte, minKeyLength)}.Parse(spec)
+	return err
+}
+
+func noLeases(context.Context, int64) (int64, error) {
+	return 0, errors.New("no ID counter")
+}
+
+// Parse builds a generator from a spec:
+//
+//	random[:length[:alphabet]]   crypto-random base62 or custom alphabet, default length 6
+//	nolookalike[:length]         crypto-random without 0/O/o/1/l/I, default length 7
+//	words[:count]                pronounceable words joined by '-', default 3
+//	adaptive[:min[-max]]         random base62 growing from min to max as collisions rise, default 6-12
+//	sequential[:length]          IDs leased from a counter, shuffled into base62, default length 7
+//
+// An empty spec means DefaultSpec.
+func (p Parser) Parse(spec string) (Generator, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		spec = DefaultSpec
+	}
+
+	parts := strings.SplitN(spec, ":", 3)
+	kind := strings.ToLower(parts[0])
+	arg := func(i int) string {
+		if i < len(parts) {
+			return parts[i]
+		}
+		return ""
+	}
+	number := func(s string, def int) (int, error) {
+		if s == "" {
+			return def, nil
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("code style %q: %q is not a number", spec, s)
+		}
+		return n, nil
+	}
+
+	switch kind {
+	case "random":
+		length, err := number(arg(1), 6)
+		if err != nil {
+			return nil, err
+		}
+		alphabet := arg(2)
+		if alphabet == "" {
+			alphabet = Base62
+		}
+		return generator(NewRandom(alphabet, length))
+
+	case "nolookalike":
+		if len(parts) > 2 {
+			break
+		}
+		length, err := number(arg(1), 7)
+		if err != nil {
+			return nil, err
+		}
+		return generator(NewRandom(NoLookalike, length))
+
+	case "words":
+		if len(parts) > 2 {
+			break
+		}
+		count, err := number(arg(1), 3)
+		if err != nil {
+			return nil, err
+		}
+		return generator(NewWords(count))
+
+	case "adaptive":
+		if len(parts) > 2 {
+			break
+		}
+		lo, hi, _ := strings.Cut(arg(1), "-")
+		minLen, err := number(lo, 6)
+		if err != nil {
+			return nil, err
+		}
+		maxLen, err := number(hi, max(minLen, 12))
+		if err != nil {
+			return nil, err
+		}
+		return generator(NewAdaptive(Base62, minLen, maxLen))
+
+	case "sequential":
+		if len(parts) > 2 {
+			break
+		}
+		length, err := number(arg(1), 7)
+		if err != nil {
+			return nil, err
+		}
+		if p.Leaser == nil {
+			return nil, errors.New("sequential codes are not enabled")
+		}
+		shuffle, err := NewShuffle(p.Key, Base62, length)
+		if err != nil {
+			return nil, err
+		}
+		return NewSequence(p.Leaser, p.BlockSize, shuffle), nil
+	}
+	return nil, fmt.Errorf("unknown code style %q, expected random, nolookalike, words, adaptive or sequential", spec)
+}
+
+// generator keeps a failed constructor's nil pointer from becoming a non-nil Generator.
+func generator[G Generator](g G, err error) (Generator, error) {
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
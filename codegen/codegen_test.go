@@ -0,0 +1,198 @@
+package codegen
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	ctx := context.Background()
+	g, err := Parse("")
+	require.NoError(t, err)
+	code, err := g.Generate(ctx)
+	require.NoError(t, err)
+	assert.Len(t, code, 6)
+	assert.Empty(t, strings.Trim(code, Base62))
+
+	g, err = Parse("nolookalike:9")
+	require.NoError(t, err)
+	for i := 0; i < 50; i++ {
+		code, err := g.Generate(ctx)
+		require.NoError(t, err)
+		assert.Len(t, code, 9)
+		assert.False(t, strings.ContainsAny(code, "0Oo1lI"), code)
+	}
+
+	g, err = Parse("random:4:ab")
+	require.NoError(t, err)
+	code, err = g.Generate(ctx)
+	require.NoError(t, err)
+	assert.Len(t, code, 4)
+	assert.Empty(t, strings.Trim(code, "ab"))
+
+	g, err = Parse("words:2")
+	require.NoError(t, err)
+	code, err = g.Generate(ctx)
+	require.NoError(t, err)
+	words := strings.Split(code, "-")
+	require.Len(t, words, 2)
+	assert.Contains(t, wordList, words[0])
+	assert.Contains(t, wordList, words[1])
+
+	g, err = Parse("adaptive:5-7")
+	require.NoError(t, err)
+	assert.Equal(t, 5, g.(*Adaptive).Length())
+
+	for _, bad := range []string{"random:0", "random:8:a", "random:8:ab/c", "random:8:aab", "random:x",
+		"words:0", "words:9", "adaptive:8-6", "nolookalike:7:abc", "sequential", "snowflake"} {
+		g, err := Parse(bad)
+		assert.Error(t, err, bad)
+		assert.Nil(t, g, bad)
+	}
+}
+
+func TestRandomIsUniform(t *testing.T) {
+	ctx := context.Background()
+	g, err := NewRandom("abcd", 1)
+	require.NoError(t, err)
+
+	counts := make(map[string]int)
+	for i := 0; i < 4000; i++ {
+		code, err := g.Generate(ctx)
+		require.NoError(t, err)
+		counts[code]++
+	}
+	for _, c := range []string{"a", "b", "c", "d"} {
+		assert.InDelta(t, 1000, counts[c], 150, c)
+	}
+}
+
+func TestAdaptiveGrows(t *testing.T) {
+	ctx := context.Background()
+	a, err := NewAdaptive(Base62, 6, 8)
+	require.NoError(t, err)
+
+	// Occasional collisions are fine
+	for i := 0; i < 300; i++ {
+		if i%20 == 0 {
+			a.Collided()
+		} else {
+			a.Accepted()
+		}
+	}
+	assert.Equal(t, 6, a.Length())
+
+	// A streak of them isn't
+	a.Collided()
+	a.Collided()
+	a.Collided()
+	assert.Equal(t, 7, a.Length())
+	code, err := a.Generate(ctx)
+	require.NoError(t, err)
+	assert.Len(t, code, 7)
+
+	// Nor is a high rate without a streak
+	for i := 0; i < adaptiveWindow; i++ {
+		if i%4 == 0 {
+			a.Collided()
+		} else {
+			a.Accepted()
+		}
+	}
+	assert.Equal(t, 8, a.Length())
+
+	// max caps the growth
+	for i := 0; i < 10; i++ {
+		a.Collided()
+	}
+	assert.Equal(t, 8, a.Length())
+}
+
+func TestShuffle(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	s, err := NewShuffle(key, Base62, 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(62*62), s.Space())
+
+	// A permutation of the whole code space
+	seen := make(map[string]bool, s.Space())
+	for id := uint64(0); id < s.Space(); id++ {
+		code, err := s.Encode(id)
+		require.NoError(t, err)
+		require.Len(t, code, 2)
+		require.False(t, seen[code], code)
+		seen[code] = true
+
+		back, err := s.Decode(code)
+		require.NoError(t, err)
+		require.Equal(t, id, back)
+	}
+	_, err = s.Encode(s.Space())
+	assert.Error(t, err)
+
+	// Consecutive IDs don't give consecutive codes, and other keys give other codes
+	s, err = NewShuffle(key, Base62, 7)
+	require.NoError(t, err)
+	a, _ := s.Encode(1000)
+	b, _ := s.Encode(1001)
+	assert.NotEqual(t, a[:5], b[:5])
+	other, err := NewShuffle([]byte("fedcba9876543210"), Base62, 7)
+	require.NoError(t, err)
+	c, _ := other.Encode(1000)
+	assert.NotEqual(t, a, c)
+
+	long, err := NewShuffle(key, Base62, 10)
+	require.NoError(t, err)
+	code, err := long.Encode(long.Space() - 1)
+	require.NoError(t, err)
+	back, err := long.Decode(code)
+	require.NoError(t, err)
+	assert.Equal(t, long.Space()-1, back)
+
+	_, err = NewShuffle(key, Base62, 11)
+	assert.Error(t, err, "62^11 doesn't fit in 63 bits")
+	_, err = NewShuffle([]byte("short"), Base62, 7)
+	assert.Error(t, err)
+}
+
+func TestSequence(t *testing.T) {
+	ctx := context.Background()
+
+	// Two replicas leasing from one counter
+	var counter int64
+	var leases int
+	leaser := LeaserFunc(func(_ context.Context, size int64) (int64, error) {
+		leases++
+		first := counter
+		counter += size
+		return first, nil
+	})
+	p := Parser{Leaser: leaser, Key: []byte("0123456789abcdef"), BlockSize: 10}
+	a, err := p.Parse("sequential:5")
+	require.NoError(t, err)
+	b, err := p.Parse("sequential:5")
+	require.NoError(t, err)
+	assert.Implements(t, (*CollisionFree)(nil), a)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 25; i++ {
+		for _, g := range []Generator{a, b} {
+			code, err := g.Generate(ctx)
+			require.NoError(t, err)
+			assert.Len(t, code, 5)
+			assert.False(t, seen[code], code)
+			seen[code] = true
+		}
+	}
+	assert.Equal(t, 6, leases, "50 IDs in blocks of 10")
+
+	// Sequential codes need a counter, but validate without one
+	_, err = Parse("sequential:7")
+	assert.Error(t, err)
+	assert.NoError(t, Validate("sequential:7"))
+	assert.Error(t, Validate("sequential:11"))
+}
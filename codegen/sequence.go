@@ -0,0 +1,77 @@
+package codegen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DefaultBlockSize is how many IDs a Sequence leases at a time. IDs left in a block when the
+// process stops are never used, which only costs unused codes.
+const DefaultBlockSize = 100
+
+// Leaser hands out blocks of IDs: every call returns the first of size IDs no other call,
+// on any replica, got or will get.
+type Leaser interface {
+	Lease(ctx context.Context, size int64) (first int64, err error)
+}
+
+// LeaserFunc adapts a function to a Leaser.
+type LeaserFunc func(ctx context.Context, size int64) (int64, error)
+
+func (f LeaserFunc) Lease(ctx context.Context, size int64) (int64, error) {
+	return f(ctx, size)
+}
+
+// Sequence generates codes from IDs it leases in blocks, shuffled so they don't look sequential.
+// It only goes to the counter once per block, and since every ID is handed out once its codes
+// never collide with each other.
+type Sequence struct {
+	leaser  Leaser
+	size    int64
+	shuffle *Shuffle
+
+	mu        sync.Mutex
+	next, end int64
+}
+
+func NewSequence(leaser Leaser, blockSize int64, shuffle *Shuffle) *Sequence {
+	if blockSize <= 0 {
+		blockSize = DefaultBlockSize
+	}
+	return &Sequence{leaser: leaser, size: blockSize, shuffle: shuffle}
+}
+
+func (s *Sequence) CollisionFree() {}
+
+func (s *Sequence) Generate(ctx context.Context) (string, error) {
+	id, err := s.id(ctx)
+	if err != nil {
+		return "", err
+	}
+	if uint64(id) >= s.shuffle.Space() {
+		return "", errors.New("sequential codes of this length are used up; choose a longer length")
+	}
+	return s.shuffle.Encode(uint64(id))
+}
+
+func (s *Sequence) id(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.next == s.end {
+		first, err := s.leaser.Lease(ctx, s.size)
+		if err != nil {
+			return 0, fmt.Errorf("leasing code IDs: %w", err)
+		}
+		if first < 0 {
+			return 0, fmt.Errorf("leasing code IDs: got negative ID %d", first)
+		}
+		s.next, s.end = first, first+s.size
+	}
+
+	id := s.next
+	s.next++
+	return id, nil
+}
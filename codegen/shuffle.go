@@ -0,0 +1,126 @@
+package codegen
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"strings"
+)
+
+const (
+	minKeyLength  = 16
+	feistelRounds = 6
+)
+
+// Shuffle maps IDs one-to-one onto fixed-length codes, so consecutive IDs give unrelated
+// looking codes, and back. It's a keyed Feistel network over the smallest even number of bits
+// that covers every code, with values outside the code space walked through the network again
+// until they fall inside it. Without the key the order of codes can't be recovered.
+type Shuffle struct {
+	key      []byte
+	alphabet string
+	length   int
+	space    uint64 // number of codes, len(alphabet)^length
+	half     uint   // bits per Feistel half
+}
+
+func NewShuffle(key []byte, alphabet string, length int) (*Shuffle, error) {
+	if len(key) < minKeyLength {
+		return nil, fmt.Errorf("shuffle key needs at least %d bytes", minKeyLength)
+	}
+	if err := validAlphabet(alphabet); err != nil {
+		return nil, err
+	}
+	if length < 1 {
+		return nil, errors.New("code length must be at least 1")
+	}
+
+	space := uint64(1)
+	for i := 0; i < length; i++ {
+		hi, lo := bits.Mul64(space, uint64(len(alphabet)))
+		if hi != 0 || lo > math.MaxInt64 {
+			return nil, fmt.Errorf("%d characters of a %d character alphabet are too many codes to shuffle",
+				length, len(alphabet))
+		}
+		space = lo
+	}
+
+	n := uint(bits.Len64(space - 1))
+	return &Shuffle{key: key, alphabet: alphabet, length: length, space: space, half: (n + 1) / 2}, nil
+}
+
+// Space is how many IDs can be encoded, from 0 to Space()-1.
+func (s *Shuffle) Space() uint64 {
+	return s.space
+}
+
+func (s *Shuffle) Encode(id uint64) (string, error) {
+	if id >= s.space {
+		return "", fmt.Errorf("id %d doesn't fit in %d character codes", id, s.length)
+	}
+
+	// Cycle walking: the network permutes [0, 2^(2*half)), so repeating it from a value inside
+	// the code space ends up inside it again.
+	x := s.permute(id, false)
+	for x >= s.space {
+		x = s.permute(x, false)
+	}
+
+	b := make([]byte, s.length)
+	base := uint64(len(s.alphabet))
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i] = s.alphabet[x%base]
+		x /= base
+	}
+	return string(b), nil
+}
+
+func (s *Shuffle) Decode(code string) (uint64, error) {
+	if len(code) != s.length {
+		return 0, fmt.Errorf("code must be %d characters", s.length)
+	}
+
+	var x uint64
+	base := uint64(len(s.alphabet))
+	for i := 0; i < len(code); i++ {
+		d := strings.IndexByte(s.alphabet, code[i])
+		if d < 0 {
+			return 0, fmt.Errorf("code contains %q, which isn't in the alphabet", code[i])
+		}
+		x = x*base + uint64(d)
+	}
+
+	x = s.permute(x, true)
+	for x >= s.space {
+		x = s.permute(x, true)
+	}
+	return x, nil
+}
+
+// permute runs x through the Feistel network, or backwards through it when inverse is set.
+func (s *Shuffle) permute(x uint64, inverse bool) uint64 {
+	mask := uint64(1)<<s.half - 1
+	l, r := x>>s.half, x&mask
+	for i := 0; i < feistelRounds; i++ {
+		if inverse {
+			round := feistelRounds - 1 - i
+			l, r = r^s.round(round, l), l
+		} else {
+			l, r = r, l^s.round(i, r)
+		}
+	}
+	return l<<s.half | r
+}
+
+func (s *Shuffle) round(i int, v uint64) uint64 {
+	var msg [9]byte
+	msg[0] = byte(i)
+	binary.BigEndian.PutUint64(msg[1:], v)
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(msg[:])
+	return binary.BigEndian.Uint64(mac.Sum(nil)) & (uint64(1)<<s.half - 1)
+}
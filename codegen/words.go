@@ -0,0 +1,68 @@
+package codegen
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const maxWords = 8
+
+// Words makes codes of short, common English words joined by '-', such as "lake-mint-oak", that
+// are easy to say and remember. Each word adds about 8 bits.
+type Words struct {
+	count int
+}
+
+func NewWords(count int) (*Words, error) {
+	if count < 1 || count > maxWords {
+		return nil, fmt.Errorf("word count must be between 1 and %d", maxWords)
+	}
+	return &Words{count: count}, nil
+}
+
+func (w *Words) Generate(context.Context) (string, error) {
+	picked := make([]string, w.count)
+	n := big.NewInt(int64(len(wordList)))
+	for i := range picked {
+		k, err := rand.Int(rand.Reader, n)
+		if err != nil {
+			return "", err
+		}
+		picked[i] = wordList[k.Int64()]
+	}
+	return strings.Join(picked, "-"), nil
+}
+
+// wordList holds 321 words that are short, distinct when spoken and harmless in any order.
+var wordList = []string{
+	"able", "acid", "aged", "also", "area", "army", "away", "baby", "back", "bake", "ball", "band",
+	"bank", "base", "bath", "bean", "bear", "beat", "bell", "belt", "best", "bird", "blue", "boat",
+	"body", "bold", "bone", "book", "boot", "born", "both", "bowl", "bulk", "busy", "cafe", "cage",
+	"cake", "calm", "camp", "card", "care", "cart", "case", "cash", "cast", "cave", "cell", "chef",
+	"chip", "city", "clay", "clip", "club", "coal", "coat", "code", "coin", "cold", "cook", "cool",
+	"copy", "corn", "cove", "crab", "crew", "crop", "cube", "cure", "curl", "dark", "dash", "data",
+	"dawn", "deal", "deep", "deer", "desk", "dial", "dice", "dish", "dock", "dome", "door", "dove",
+	"down", "draw", "drum", "duck", "dune", "dust", "each", "earn", "east", "easy", "echo", "edge",
+	"epic", "even", "ever", "exit", "face", "fair", "farm", "fast", "fawn", "fern", "film", "find",
+	"fine", "fire", "firm", "fish", "five", "flag", "flat", "foam", "fold", "folk", "font", "food",
+	"fork", "form", "fort", "four", "free", "frog", "fuel", "full", "fund", "gain", "game", "gate",
+	"gear", "gift", "glow", "goal", "gold", "golf", "good", "grid", "grin", "grow", "gulf", "hail",
+	"half", "hall", "halo", "hand", "harp", "hawk", "heat", "herb", "hero", "hike", "hill", "hint",
+	"home", "hood", "hook", "hope", "horn", "host", "huge", "idea", "inch", "iron", "isle", "item",
+	"jade", "jazz", "jump", "june", "keen", "keep", "kelp", "kind", "king", "kite", "kiwi", "knot",
+	"lake", "lamp", "land", "lane", "lark", "last", "lava", "lawn", "leaf", "lens", "lime", "line",
+	"link", "lion", "list", "loaf", "loft", "lone", "loop", "lucky", "lure", "main", "mango",
+	"maple", "mask", "meal", "mild", "mile", "milk", "mind", "mint", "mist", "moon", "moss", "much",
+	"mule", "navy", "near", "neat", "nest", "next", "nice", "noon", "nose", "note", "nova", "oak",
+	"oasis", "ocean", "olive", "opal", "open", "oval", "palm", "park", "path", "peak", "pear",
+	"pine", "pink", "plan", "plum", "poem", "pond", "pony", "pool", "port", "puma", "quay", "quiz",
+	"race", "raft", "rain", "ramp", "rare", "reed", "reef", "rice", "rich", "ride", "ring", "road",
+	"robe", "rock", "roof", "root", "rose", "ruby", "sage", "sail", "salt", "sand", "seal", "seed",
+	"shell", "ship", "silk", "sky", "snow", "sofa", "soft", "song", "soup", "star", "stem", "sun",
+	"surf", "swan", "tale", "tall", "tea", "teal", "tide", "tile", "time", "tiny", "toad", "tree",
+	"tulip", "tuna", "vase", "vast", "vine", "violet", "wave", "west", "wheat", "wild", "wind",
+	"wing", "wise", "wolf", "wood", "yard", "yarn", "year", "yoga", "zeal", "zebra", "zinc", "zone",
+}
@@ -0,0 +1,229 @@
+// Package codepolicy decides which custom codes users may choose: which characters and lengths
+// are allowed, how case is treated, and which words are off limits because they are routes of
+// ShortEdge itself or offensive.
+package codepolicy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Case policies.
+const (
+	CasePreserve = "preserve" // codes keep their case, so "Sale" and "sale" are different links
+	CaseLower    = "lower"    // custom codes are stored in lowercase
+)
+
+const (
+	DefaultMinLength = 3
+	DefaultMaxLength = 64
+	DefaultExtra     = "-_"
+)
+
+// unsafe characters break routing or need escaping: '/' splits paths, '@' qualifies codes with
+// their domain and a trailing '+' opens the preview page.
+const unsafe = "/\\?#%@+&=:;, \t\r\n\"'<>`{}|^[]"
+
+// DefaultReserved are words kept free for ShortEdge besides its registered routes.
+var DefaultReserved = []string{"admin", "api", "assets", "docs", "favicon", "metrics", "robots", "static", "swagger", "www"}
+
+var (
+	ErrReserved = errors.New("is reserved")
+	ErrBlocked  = errors.New("contains a word that isn't allowed")
+)
+
+type Config struct {
+	MinLength int      // 0 means DefaultMinLength
+	MaxLength int      // 0 means DefaultMaxLength
+	Extra     string   // characters allowed besides ASCII letters and digits; empty means DefaultExtra
+	Case      string   // CasePreserve or CaseLower; empty means CasePreserve
+	Reserved  []string // added to DefaultReserved and the routes reserved later
+	Profanity bool     // reject codes containing offensive words
+	Blocked   []string // more words to reject wherever they occur, with or without Profanity
+}
+
+// Policy is safe for concurrent use; routes can be reserved while codes are checked.
+type Policy struct {
+	minLength, maxLength int
+	extra                string
+	lower                bool
+	blocked              []string // matched anywhere
+	blockedParts         []string // matched as a whole part
+
+	mu       sync.RWMutex
+	reserved map[string]bool
+}
+
+func New(cfg Config) (*Policy, error) {
+	if cfg.MinLength <= 0 {
+		cfg.MinLength = DefaultMinLength
+	}
+	if cfg.MaxLength <= 0 {
+		cfg.MaxLength = DefaultMaxLength
+	}
+	if cfg.MinLength > cfg.MaxLength {
+		return nil, fmt.Errorf("minimum code length %d is above the maximum %d", cfg.MinLength, cfg.MaxLength)
+	}
+	if cfg.Extra == "" {
+		cfg.Extra = DefaultExtra
+	}
+	if i := strings.IndexAny(cfg.Extra, unsafe); i >= 0 {
+		return nil, fmt.Errorf("%q can't be allowed in codes", cfg.Extra[i])
+	}
+	for _, c := range cfg.Extra {
+		if c > 0x7e || c < 0x21 {
+			return nil, fmt.Errorf("%q can't be allowed in codes", c)
+		}
+	}
+
+	switch cfg.Case {
+	case "", CasePreserve, CaseLower:
+	default:
+		return nil, fmt.Errorf("unknown case policy %q, expected %s or %s", cfg.Case, CasePreserve, CaseLower)
+	}
+
+	p := &Policy{
+		minLength: cfg.MinLength,
+		maxLength: cfg.MaxLength,
+		extra:     cfg.Extra,
+		lower:     cfg.Case == CaseLower,
+		reserved:  make(map[string]bool),
+	}
+	p.Reserve(DefaultReserved...)
+	p.Reserve(cfg.Reserved...)
+
+	if cfg.Profanity {
+		p.blocked = append(p.blocked, profanity...)
+		p.blockedParts = append(p.blockedParts, profanityParts...)
+	}
+	for _, w := range cfg.Blocked {
+		if w = foldWord(w); w != "" {
+			p.blocked = append(p.blocked, w)
+		}
+	}
+	return p, nil
+}
+
+// Reserve keeps words from being used as codes, in any case.
+func (p *Policy) Reserve(words ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, w := range words {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			p.reserved[w] = true
+		}
+	}
+}
+
+// ReserveRoute reserves the first segment of a route path, such as "analytics" for
+// "/analytics/{code}"; paths starting with a variable reserve nothing.
+func (p *Policy) ReserveRoute(path string) {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if segment != "" && !strings.Contains(segment, "{") {
+		p.Reserve(segment)
+	}
+}
+
+// Normalize checks a custom code and returns it the way it's stored. Errors read as predicates
+// of the code, e.g. "is reserved".
+func (p *Policy) Normalize(code string) (string, error) {
+	code = strings.TrimSpace(code)
+	if p.lower {
+		code = strings.ToLower(code)
+	}
+
+	if n := len(code); n < p.minLength || n > p.maxLength {
+		return "", fmt.Errorf("must be between %d and %d characters long", p.minLength, p.maxLength)
+	}
+	for _, c := range code {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune(p.extra, c)) {
+			return "", fmt.Errorf("can only contain %s, not %q", allowedChars(p.extra), c)
+		}
+	}
+
+	if p.isReserved(code) {
+		return "", ErrReserved
+	}
+	if p.isBlocked(code) {
+		return "", ErrBlocked
+	}
+	return code, nil
+}
+
+// Fold applies the case policy to a code, e.g. to look up a link typed in another case than
+// the one it was stored in.
+func (p *Policy) Fold(code string) string {
+	if p.lower {
+		return strings.ToLower(code)
+	}
+	return code
+}
+
+// Allowed reports whether a generated code can be used: it mustn't be reserved or contain a
+// blocked word. Characters and length are up to the generator.
+func (p *Policy) Allowed(code string) bool {
+	return !p.isReserved(code) && !p.isBlocked(code)
+}
+
+func (p *Policy) isReserved(code string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.reserved[strings.ToLower(code)]
+}
+
+// isBlocked looks for blocked words through separators and digits standing in for letters, so
+// "f-u-c-k" and "sh1t" are caught. Words that often occur inside harmless ones only match a
+// whole part of the code, between separators, or the whole code.
+func (p *Policy) isBlocked(code string) bool {
+	folded := foldWord(code)
+	for _, w := range p.blocked {
+		if strings.Contains(folded, w) {
+			return true
+		}
+	}
+	if len(p.blockedParts) == 0 {
+		return false
+	}
+
+	parts := []string{folded}
+	for _, part := range strings.FieldsFunc(code, func(c rune) bool { return !isLetterOrDigit(c) }) {
+		parts = append(parts, foldWord(part))
+	}
+	for _, w := range p.blockedParts {
+		for _, part := range parts {
+			if part == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// leet maps digits and symbols commonly used for letters back to them.
+var leet = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "8", "b", "$", "s")
+
+// foldWord lowercases s, undoes leetspeak and drops everything that isn't a letter.
+func foldWord(s string) string {
+	s = leet.Replace(strings.ToLower(s))
+	return strings.Map(func(c rune) rune {
+		if c >= 'a' && c <= 'z' {
+			return c
+		}
+		return -1
+	}, s)
+}
+
+func isLetterOrDigit(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// allowedChars describes the allowed characters, e.g. "letters, digits, '-' and '_'".
+func allowedChars(extra string) string {
+	names := []string{"letters", "digits"}
+	for _, c := range extra {
+		names = append(names, fmt.Sprintf("'%c'", c))
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
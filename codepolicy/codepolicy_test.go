@@ -0,0 +1,73 @@
+package codepolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	p, err := New(Config{Reserved: []string{"Pricing"}, Profanity: true})
+	require.NoError(t, err)
+	p.ReserveRoute("/analytics/{code}/rules")
+	p.ReserveRoute("/{code}+")
+	p.ReserveRoute("/.well-known/assetlinks.json")
+
+	for _, ok := range []string{"Sale-2025", "abc", "team_1", "class", "grape", "analysis", "sussex", "code"} {
+		code, err := p.Normalize(ok)
+		assert.NoError(t, err, ok)
+		assert.Equal(t, ok, code, "case is preserved")
+	}
+
+	for code, want := range map[string]error{
+		"analytics": ErrReserved,
+		"ANALYTICS": ErrReserved,
+		"pricing":   ErrReserved,
+		"metrics":   ErrReserved,
+		"shit-deal": ErrBlocked,
+		"sh1t":      ErrBlocked,
+		"F-u-c-k":   ErrBlocked,
+		"kiss-a55":  ErrBlocked,
+		"Rape":      ErrBlocked,
+		"shitstorm": ErrBlocked,
+	} {
+		_, err := p.Normalize(code)
+		assert.ErrorIs(t, err, want, code)
+	}
+
+	for code, want := range map[string]string{
+		"ab":                     "must be between 3 and 64 characters long",
+		"a/b/c":                  "can only contain letters, digits, '-' and '_', not '/'",
+		"with space":             "can only contain letters, digits, '-' and '_', not ' '",
+		"café":                   "can only contain letters, digits, '-' and '_', not 'é'",
+		string(make([]byte, 65)): "must be between 3 and 64 characters long",
+	} {
+		_, err := p.Normalize(code)
+		assert.EqualError(t, err, want, code)
+	}
+
+	assert.False(t, p.Allowed("Analytics"))
+	assert.True(t, p.Allowed("aB3xYz"))
+}
+
+func TestConfig(t *testing.T) {
+	p, err := New(Config{MinLength: 1, MaxLength: 4, Extra: ".", Case: CaseLower, Blocked: []string{"Spam"}})
+	require.NoError(t, err)
+
+	code, err := p.Normalize("A.b")
+	require.NoError(t, err)
+	assert.Equal(t, "a.b", code)
+	assert.Equal(t, "a.b", p.Fold("A.B"))
+	_, err = p.Normalize("a-b")
+	assert.EqualError(t, err, "can only contain letters, digits and '.', not '-'")
+	_, err = p.Normalize("SPAM")
+	assert.ErrorIs(t, err, ErrBlocked)
+	_, err = p.Normalize("shit")
+	assert.NoError(t, err, "profanity filter is off")
+
+	for _, bad := range []Config{{MinLength: 5, MaxLength: 4}, {Extra: "/"}, {Extra: "+"}, {Extra: "é"}, {Case: "upper"}} {
+		_, err := New(bad)
+		assert.Error(t, err, "%+v", bad)
+	}
+}
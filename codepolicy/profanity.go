@@ -0,0 +1,20 @@
+package codepolicy
+
+// Common English swear words and sexual terms, folded like codes are (see foldWord). The lists
+// are deliberately short; deployments add their own words with Config.Blocked.
+var (
+	// profanity is matched anywhere in a code; these rarely occur inside harmless words.
+	profanity = []string{
+		"asshole", "bastard", "bitch", "blowjob", "bullshit", "cunt", "dildo", "faggot", "fuck",
+		"handjob", "jizz", "motherfucker", "nigga", "nigger", "pussy", "shit", "whore",
+	}
+
+	// profanityParts only match a whole part of a code, so "class", "grape" and "sussex" stay
+	// usable.
+	profanityParts = []string{
+		"anal", "anus", "arse", "ass", "boob", "boner", "bollocks", "bugger", "butthole", "clit",
+		"cock", "crap", "cum", "dick", "douche", "fag", "milf", "nazi", "orgasm", "penis", "piss",
+		"porn", "prick", "rape", "retard", "scrotum", "sex", "slut", "tit", "tits", "twat", "vagina",
+		"wank",
+	}
+)
@@ -1,13 +1,17 @@
 package factory
 
 import (
+	"context"
 	"database/sql"
+	"github.com/Kritvi0208/ShortEdge/ratelimit"
 	"github.com/Kritvi0208/ShortEdge/store"
 	"log"
 	"os"
+	"time"
 
 	//"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
 	"gofr.dev/pkg/gofr"
 )
 
@@ -21,6 +25,67 @@ func NewVisitStore(app *gofr.App) store.Visit {
 	return store.NewVisitStore(GetDB())
 }
 
+func NewTagStore(app *gofr.App) store.Tag {
+	return store.NewTagStore(GetDB())
+}
+
+func NewFolderStore(app *gofr.App) store.Folder {
+	return store.NewFolderStore(GetDB())
+}
+
+func NewMetadataStore(app *gofr.App) store.Metadata {
+	return store.NewMetadataStore(GetDB())
+}
+
+func NewUserStore(app *gofr.App) store.User {
+	return store.NewUserStore(GetDB())
+}
+
+func NewAPIKeyStore(app *gofr.App) store.APIKey {
+	return store.NewAPIKeyStore(GetDB())
+}
+
+func NewWorkspaceStore(app *gofr.App) store.Workspace {
+	return store.NewWorkspaceStore(GetDB())
+}
+
+func NewScheduleStore(app *gofr.App) store.Schedule {
+	return store.NewScheduleStore(GetDB())
+}
+
+func NewTargetingStore(app *gofr.App) store.Targeting {
+	return store.NewTargetingStore(GetDB())
+}
+
+func NewVariantStore(app *gofr.App) store.Variant {
+	return store.NewVariantStore(GetDB())
+}
+
+func NewDomainStore(app *gofr.App) store.Domain {
+	return store.NewDomainStore(GetDB())
+}
+
+func NewUsageStore(app *gofr.App) store.Usage {
+	return store.NewUsageStore(GetDB())
+}
+
+// NewRateLimitBackend shares rate limits through Redis when RATE_LIMIT_REDIS_URL is set and
+// keeps them in memory otherwise.
+func NewRateLimitBackend(app *gofr.App) ratelimit.Backend {
+	redisURL := app.Config.Get("RATE_LIMIT_REDIS_URL")
+	if redisURL == "" {
+		memory := ratelimit.NewMemory()
+		go memory.Run(context.Background(), time.Minute)
+		return memory
+	}
+
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		log.Fatalf("❌ Invalid RATE_LIMIT_REDIS_URL: %v", err)
+	}
+	return ratelimit.NewRedis(redis.NewClient(opts), "shortedge:ratelimit:")
+}
+
 func GetDB() *sql.DB {
 	if db != nil {
 		return db
@@ -42,3 +107,11 @@ func GetDB() *sql.DB {
 	}
 	return db
 }
+
+func NewHealthStore(app *gofr.App) store.Health {
+	return store.NewHealthStore(GetDB())
+}
+
+func NewIDBlockStore(app *gofr.App) store.IDBlock {
+	return store.NewIDBlockStore(GetDB())
+}
@@ -0,0 +1,109 @@
+// Package geo resolves client addresses to their country.
+package geo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Location is where an address is; fields are empty when unknown.
+type Location struct {
+	Country     string // display name, e.g. "Germany"
+	CountryCode string // ISO 3166-1 alpha-2, e.g. "DE"
+}
+
+// Resolver looks up the location of an address.
+type Resolver interface {
+	Lookup(ctx context.Context, ip netip.Addr) (Location, error)
+}
+
+// Localhost is reported for loopback and private addresses, which no geo database knows.
+var Localhost = Location{Country: "Localhost"}
+
+// IPWhoIs resolves addresses through the ipwho.is API, caching answers for ttl since every
+// redirect needs one.
+type IPWhoIs struct {
+	client  *http.Client
+	baseURL string
+	ttl     time.Duration
+
+	mu    sync.Mutex
+	cache map[netip.Addr]cached
+}
+
+type cached struct {
+	loc     Location
+	expires time.Time
+}
+
+// maxCached bounds the cache; it's simply cleared when full.
+const maxCached = 10000
+
+func NewIPWhoIs(client *http.Client, ttl time.Duration) *IPWhoIs {
+	return &IPWhoIs{client: client, baseURL: "https://ipwho.is/", ttl: ttl, cache: make(map[netip.Addr]cached)}
+}
+
+func (g *IPWhoIs) Lookup(ctx context.Context, ip netip.Addr) (Location, error) {
+	if !ip.IsValid() {
+		return Location{}, fmt.Errorf("invalid address")
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified() {
+		return Localhost, nil
+	}
+
+	now := time.Now()
+	g.mu.Lock()
+	c, ok := g.cache[ip]
+	g.mu.Unlock()
+	if ok && now.Before(c.expires) {
+		return c.loc, nil
+	}
+
+	loc, err := g.fetch(ctx, ip)
+	if err != nil {
+		return Location{}, err
+	}
+
+	g.mu.Lock()
+	if len(g.cache) >= maxCached {
+		clear(g.cache)
+	}
+	g.cache[ip] = cached{loc: loc, expires: now.Add(g.ttl)}
+	g.mu.Unlock()
+
+	return loc, nil
+}
+
+func (g *IPWhoIs) fetch(ctx context.Context, ip netip.Addr) (Location, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+ip.String(), nil)
+	if err != nil {
+		return Location{}, err
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return Location{}, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Success     bool   `json:"success"`
+		Message     string `json:"message"`
+		Country     string `json:"country"`
+		CountryCode string `json:"country_code"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Location{}, err
+	}
+	if !result.Success {
+		return Location{}, fmt.Errorf("geo lookup of %s failed: %s", ip, result.Message)
+	}
+
+	return Location{Country: result.Country, CountryCode: strings.ToUpper(result.CountryCode)}, nil
+}
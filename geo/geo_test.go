@@ -0,0 +1,45 @@
+package geo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPWhoIs(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path == "/203.0.113.9" {
+			_, _ = w.Write([]byte(`{"success": true, "country": "Germany", "country_code": "de"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success": false, "message": "Invalid IP address"}`))
+	}))
+	defer srv.Close()
+
+	g := NewIPWhoIs(srv.Client(), time.Hour)
+	g.baseURL = srv.URL + "/"
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		loc, err := g.Lookup(ctx, netip.MustParseAddr("203.0.113.9"))
+		require.NoError(t, err)
+		assert.Equal(t, Location{Country: "Germany", CountryCode: "DE"}, loc)
+	}
+	assert.Equal(t, 1, calls, "answers are cached")
+
+	_, err := g.Lookup(ctx, netip.MustParseAddr("198.51.100.1"))
+	assert.ErrorContains(t, err, "Invalid IP address")
+
+	loc, err := g.Lookup(ctx, netip.MustParseAddr("192.168.1.10"))
+	require.NoError(t, err)
+	assert.Equal(t, Localhost, loc)
+	assert.Equal(t, 2, calls)
+}
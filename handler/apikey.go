@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/service"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type APIKeyHandler struct {
+	service service.APIKeyService
+}
+
+func NewAPIKeyHandler(s service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: s}
+}
+
+// GetAll godoc
+// @Summary List your API keys
+// @Tags APIKey
+// @Produce json
+// @Success 200 {array} model.APIKey
+// @Failure 401 {object} map[string]string
+// @Router /api-keys [get]
+func (h *APIKeyHandler) GetAll(ctx *gofr.Context) (interface{}, error) {
+	return h.service.List(ctx)
+}
+
+// Create godoc
+// @Summary Create an API key
+// @Description The key is only returned once; send it as the X-Api-Key header
+// @Tags APIKey
+// @Accept json
+// @Produce json
+// @Param body body model.APIKeyRequest true "Name, scopes and optional expiry"
+// @Success 201 {object} model.CreatedAPIKey
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api-keys [post]
+func (h *APIKeyHandler) Create(ctx *gofr.Context) (interface{}, error) {
+	var req model.APIKeyRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	return h.service.Create(ctx, req)
+}
+
+// Rotate godoc
+// @Summary Rotate an API key
+// @Description Revokes the key and returns a replacement with the same scopes
+// @Tags APIKey
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 201 {object} model.CreatedAPIKey
+// @Router /api-keys/{id}/rotate [post]
+func (h *APIKeyHandler) Rotate(ctx *gofr.Context) (interface{}, error) {
+	id, err := idParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.service.Rotate(ctx, id)
+}
+
+// Revoke godoc
+// @Summary Revoke an API key
+// @Tags APIKey
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 204
+// @Router /api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(ctx *gofr.Context) (interface{}, error) {
+	id, err := idParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, h.service.Revoke(ctx, id)
+}
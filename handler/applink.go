@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"bytes"
+	"html/template"
+
+	"gofr.dev/pkg/gofr"
+	gofrHTTP "gofr.dev/pkg/gofr/http"
+	"gofr.dev/pkg/gofr/http/response"
+)
+
+// appPage tries to open the app and sends visitors who don't have it to the store listing.
+var appPage = template.Must(template.New("app").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1.0">
+  <meta name="robots" content="noindex">
+  <title>Opening the app…</title>
+</head>
+<body>
+  <p>Opening the app… <a href="{{.StoreURL}}">Get it from the store</a> if nothing happens.</p>
+  <script>
+    var fallback = setTimeout(function () { window.location.replace({{.StoreURL}}); }, 1500);
+    document.addEventListener("visibilitychange", function () {
+      if (document.hidden) { clearTimeout(fallback); }
+    });
+    window.location.href = {{.AppURL}};
+  </script>
+</body>
+</html>
+`))
+
+// appRedirect renders appPage. App URLs were validated when the link was saved, so custom
+// schemes are allowed through.
+func appRedirect(appURL, storeURL string) (interface{}, error) {
+	data := struct {
+		AppURL   string
+		StoreURL string
+	}{appURL, storeURL}
+
+	var buf bytes.Buffer
+	if err := appPage.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return response.File{Content: buf.Bytes(), ContentType: "text/html; charset=utf-8"}, nil
+}
+
+// WellKnownHandler serves the files that let iOS and Android open short links in the app.
+type WellKnownHandler struct {
+	iosAppIDs           []string
+	androidPackage      string
+	androidFingerprints []string
+}
+
+// NewWellKnownHandler takes iOS app IDs ("<team ID>.<bundle ID>") and the Android package with
+// the SHA-256 fingerprints of its signing certificates.
+func NewWellKnownHandler(iosAppIDs []string, androidPackage string, androidFingerprints []string) *WellKnownHandler {
+	return &WellKnownHandler{iosAppIDs: iosAppIDs, androidPackage: androidPackage, androidFingerprints: androidFingerprints}
+}
+
+// AppleAppSiteAssociation godoc
+// @Summary iOS universal links association
+// @Tags Apps
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Router /.well-known/apple-app-site-association [get]
+func (h *WellKnownHandler) AppleAppSiteAssociation(ctx *gofr.Context) (interface{}, error) {
+	if len(h.iosAppIDs) == 0 {
+		return nil, gofrHTTP.ErrorEntityNotFound{Name: "file", Value: "apple-app-site-association"}
+	}
+
+	// Each entry has both the iOS 13+ keys (appIDs, components) and the older ones (appID, paths)
+	details := make([]map[string]interface{}, 0, len(h.iosAppIDs))
+	for _, id := range h.iosAppIDs {
+		details = append(details, map[string]interface{}{
+			"appIDs":     []string{id},
+			"components": []map[string]string{{"/": "*"}},
+			"appID":      id,
+			"paths":      []string{"*"},
+		})
+	}
+
+	return response.Raw{Data: map[string]interface{}{
+		"applinks": map[string]interface{}{"apps": []string{}, "details": details},
+	}}, nil
+}
+
+// AssetLinks godoc
+// @Summary Android app links association
+// @Tags Apps
+// @Produce json
+// @Success 200 {array} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Router /.well-known/assetlinks.json [get]
+func (h *WellKnownHandler) AssetLinks(ctx *gofr.Context) (interface{}, error) {
+	if h.androidPackage == "" || len(h.androidFingerprints) == 0 {
+		return nil, gofrHTTP.ErrorEntityNotFound{Name: "file", Value: "assetlinks.json"}
+	}
+
+	return response.Raw{Data: []map[string]interface{}{{
+		"relation": []string{"delegate_permission/common.handle_all_urls"},
+		"target": map[string]interface{}{
+			"namespace":                "android_app",
+			"package_name":             h.androidPackage,
+			"sha256_cert_fingerprints": h.androidFingerprints,
+		},
+	}}}, nil
+}
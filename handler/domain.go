@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/reqinfo"
+	"github.com/Kritvi0208/ShortEdge/service"
+
+	"gofr.dev/pkg/gofr"
+	gofrHTTP "gofr.dev/pkg/gofr/http"
+	"gofr.dev/pkg/gofr/http/response"
+)
+
+type DomainHandler struct {
+	service service.DomainService
+}
+
+func NewDomainHandler(s service.DomainService) *DomainHandler {
+	return &DomainHandler{service: s}
+}
+
+// GetAll godoc
+// @Summary List branded domains
+// @Description Domains of the caller's workspace, or of the given one
+// @Tags Domains
+// @Produce json
+// @Param workspace query int false "Workspace ID, defaults to the caller's workspace"
+// @Success 200 {array} model.Domain
+// @Router /domains [get]
+func (h *DomainHandler) GetAll(ctx *gofr.Context) (interface{}, error) {
+	var workspaceID *int64
+	if workspace := ctx.Param("workspace"); workspace != "" {
+		id, err := strconv.ParseInt(workspace, 10, 64)
+		if err != nil {
+			return nil, gofrHTTP.ErrorInvalidParam{Params: []string{"workspace"}}
+		}
+		workspaceID = &id
+	}
+
+	return h.service.List(ctx, workspaceID)
+}
+
+// Create godoc
+// @Summary Register a branded domain
+// @Description Registers a domain for a workspace (admins only). Point the domain at ShortEdge, then verify it; it serves the verification token itself.
+// @Tags Domains
+// @Accept json
+// @Produce json
+// @Param body body model.DomainRequest true "Domain"
+// @Success 201 {object} model.Domain
+// @Failure 400 {object} map[string]string
+// @Router /domains [post]
+func (h *DomainHandler) Create(ctx *gofr.Context) (interface{}, error) {
+	var req model.DomainRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	return h.service.Create(ctx, req)
+}
+
+// Update godoc
+// @Summary Change a domain's redirects
+// @Tags Domains
+// @Accept json
+// @Produce json
+// @Param id path int true "Domain ID"
+// @Param body body model.DomainRequest true "Root and 404 redirect targets"
+// @Success 200 {object} model.Domain
+// @Router /domains/{id} [put]
+func (h *DomainHandler) Update(ctx *gofr.Context) (interface{}, error) {
+	id, err := idParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var req model.DomainRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	return h.service.Update(ctx, id, req)
+}
+
+// Delete godoc
+// @Summary Remove a branded domain
+// @Description Only domains without links can be removed
+// @Tags Domains
+// @Param id path int true "Domain ID"
+// @Success 204
+// @Router /domains/{id} [delete]
+func (h *DomainHandler) Delete(ctx *gofr.Context) (interface{}, error) {
+	id, err := idParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, h.service.Delete(ctx, id)
+}
+
+// Verify godoc
+// @Summary Verify a branded domain
+// @Description Fetches the verification token from the domain's /.well-known/shortedge-verification
+// @Tags Domains
+// @Produce json
+// @Param id path int true "Domain ID"
+// @Success 200 {object} model.Domain
+// @Failure 400 {object} map[string]string
+// @Router /domains/{id}/verify [post]
+func (h *DomainHandler) Verify(ctx *gofr.Context) (interface{}, error) {
+	id, err := idParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.service.Verify(ctx, id)
+}
+
+// VerificationToken godoc
+// @Summary Domain verification token
+// @Description Serves the verification token of the domain the request was sent to
+// @Tags Domains
+// @Produce plain
+// @Success 200 {string} string
+// @Failure 404 {object} map[string]string
+// @Router /.well-known/shortedge-verification [get]
+func (h *DomainHandler) VerificationToken(ctx *gofr.Context) (interface{}, error) {
+	host := reqinfo.FromContext(ctx).Host
+
+	token, ok := h.service.VerificationToken(ctx, host)
+	if !ok {
+		return nil, gofrHTTP.ErrorEntityNotFound{Name: "domain", Value: host}
+	}
+	return response.File{Content: []byte(token), ContentType: "text/plain; charset=utf-8"}, nil
+}
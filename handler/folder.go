@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/service"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type FolderHandler struct {
+	service service.FolderService
+}
+
+func NewFolderHandler(s service.FolderService) *FolderHandler {
+	return &FolderHandler{service: s}
+}
+
+// GetAll godoc
+// @Summary List folders
+// @Description Fetch every folder; nesting is expressed through parent_id
+// @Tags Folder
+// @Produce json
+// @Success 200 {array} model.Folder
+// @Router /folders [get]
+func (h *FolderHandler) GetAll(ctx *gofr.Context) (interface{}, error) {
+	return h.service.GetAll(ctx)
+}
+
+// Create godoc
+// @Summary Create a folder
+// @Tags Folder
+// @Accept json
+// @Produce json
+// @Param body body model.FolderRequest true "Folder details"
+// @Success 201 {object} model.Folder
+// @Failure 400 {object} map[string]string
+// @Router /folders [post]
+func (h *FolderHandler) Create(ctx *gofr.Context) (interface{}, error) {
+	var req model.FolderRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	return h.service.Create(ctx, req)
+}
+
+// Update godoc
+// @Summary Rename or move a folder
+// @Tags Folder
+// @Accept json
+// @Produce json
+// @Param id path int true "Folder ID"
+// @Param body body model.FolderRequest true "Folder details"
+// @Success 200 {object} model.Folder
+// @Failure 400 {object} map[string]string
+// @Router /folders/{id} [put]
+func (h *FolderHandler) Update(ctx *gofr.Context) (interface{}, error) {
+	id, err := idParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var req model.FolderRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	return h.service.Update(ctx, id, req)
+}
+
+// Delete godoc
+// @Summary Delete a folder
+// @Description Deletes a folder and its sub-folders; links inside move to the top level
+// @Tags Folder
+// @Param id path int true "Folder ID"
+// @Success 204
+// @Router /folders/{id} [delete]
+func (h *FolderHandler) Delete(ctx *gofr.Context) (interface{}, error) {
+	id, err := idParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.service.Delete(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"message": "deleted successfully"}, nil
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/service"
+
+	"gofr.dev/pkg/gofr"
+	gofrHTTP "gofr.dev/pkg/gofr/http"
+)
+
+// defaultCertDays is how soon a certificate has to expire to show up in health reports.
+const defaultCertDays = 14
+
+type LinkHealthHandler struct {
+	service service.HealthService
+}
+
+func NewLinkHealthHandler(service service.HealthService) *LinkHealthHandler {
+	return &LinkHealthHandler{service: service}
+}
+
+// Report godoc
+// @Summary Get the destination health report
+// @Description Counts of links by destination health and the links that are failing, broken or whose TLS certificate expires soon, worst first. Takes the same filters as /all.
+// @Tags URL
+// @Produce json
+// @Param tag query string false "Tag name"
+// @Param folder query int false "Folder ID, includes sub-folders"
+// @Param workspace query int false "Workspace ID, defaults to the caller's workspace"
+// @Param cert_days query int false "Report certificates expiring within this many days, default 14"
+// @Success 200 {object} model.HealthReport
+// @Router /links/health [get]
+func (h *LinkHealthHandler) Report(ctx *gofr.Context) (interface{}, error) {
+	filter, err := parseURLFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	days := defaultCertDays
+	if v := ctx.Param("cert_days"); v != "" {
+		days, err = strconv.Atoi(v)
+		if err != nil || days < 0 {
+			return nil, gofrHTTP.ErrorInvalidParam{Params: []string{"cert_days"}}
+		}
+	}
+
+	return h.service.Report(ctx, filter, time.Duration(days)*24*time.Hour)
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http"
+
+	"github.com/Kritvi0208/ShortEdge/service"
+
+	"gofr.dev/pkg/gofr/http/response"
+)
+
+var passwordPage = template.Must(template.New("password").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1.0">
+  <meta name="robots" content="noindex">
+  <title>Password required</title>
+  <style>
+    body { font-family: system-ui, sans-serif; background: #f4f6f8; display: flex; justify-content: center; padding-top: 15vh; }
+    form { background: #fff; padding: 2rem; border-radius: 8px; box-shadow: 0 2px 8px rgba(0,0,0,.1); width: 20rem; }
+    input, button { width: 100%; box-sizing: border-box; padding: .6rem; margin-top: .8rem; font-size: 1rem; }
+    .error { color: #c0392b; }
+  </style>
+</head>
+<body>
+  <form method="post" action="/{{.Code}}">
+    <h2>This link is password protected</h2>
+    {{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+    <input type="password" name="password" placeholder="Password" autofocus required>
+    <button type="submit">Continue</button>
+  </form>
+</body>
+</html>
+`))
+
+// isPasswordError reports whether a browser should be asked for the link's password.
+func isPasswordError(err error) bool {
+	var status interface{ StatusCode() int }
+	if errors.Is(err, service.ErrPasswordRequired) || errors.Is(err, service.ErrWrongPassword) {
+		return true
+	}
+	return errors.As(err, &status) && status.StatusCode() == http.StatusTooManyRequests
+}
+
+// passwordPrompt renders the HTML form that posts the password back to the link. The error is
+// only shown once a password was tried.
+func passwordPrompt(code, password string, err error) (interface{}, error) {
+	data := struct{ Code, Error string }{Code: code}
+	if password != "" || !errors.Is(err, service.ErrPasswordRequired) {
+		data.Error = err.Error()
+	}
+
+	var buf bytes.Buffer
+	if err := passwordPage.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return response.File{Content: buf.Bytes(), ContentType: "text/html; charset=utf-8"}, nil
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/reqinfo"
+
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/http/response"
+)
+
+var previewPage = template.Must(template.New("preview").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.Format("January 2, 2006") },
+	// The page is served on the link's own domain, so its path is the code without "@domain"
+	"path": func(code string) string {
+		code, _, _ = strings.Cut(code, "@")
+		return code
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1.0">
+  <meta name="robots" content="noindex">
+  <title>Link preview</title>
+  <style>
+    body { font-family: system-ui, sans-serif; background: #f4f6f8; display: flex; justify-content: center; padding-top: 10vh; }
+    main { background: #fff; padding: 2rem; border-radius: 8px; box-shadow: 0 2px 8px rgba(0,0,0,.1); width: 32rem; }
+    dt { font-weight: 600; margin-top: .8rem; }
+    dd { margin: .2rem 0 0; word-break: break-all; }
+    .ok { color: #1e8449; }
+    .caution { color: #b9770e; }
+    a.button { display: inline-block; margin-top: 1.5rem; padding: .6rem 1.2rem; background: #2c3e50; color: #fff; border-radius: 4px; text-decoration: none; }
+  </style>
+</head>
+<body>
+  <main>
+    <h2>Where this link goes</h2>
+    <dl>
+      <dt>Destination</dt>
+      <dd>{{if .LongURL}}{{.LongURL}}{{else}}Hidden until the password is entered{{end}}</dd>
+      {{if .Title}}<dt>Page title</dt><dd>{{.Title}}</dd>{{end}}
+      <dt>Created</dt>
+      <dd>{{date .CreatedAt}}</dd>
+      {{if .Workspace}}<dt>Shared by</dt><dd>{{.Workspace}}</dd>{{end}}
+      <dt>Safety</dt>
+      {{if eq .Safety.Status "ok"}}<dd class="ok">No known issues</dd>
+      {{else}}{{range .Safety.Warnings}}<dd class="caution">⚠️ {{.}}</dd>{{end}}{{end}}
+    </dl>
+    <a class="button" href="/{{path .Code}}" rel="noreferrer">Continue to the link</a>
+  </main>
+</body>
+</html>
+`))
+
+// Preview godoc
+// @Summary Preview a short URL
+// @Description Shows where a link goes without following it: destination, page title, creation date, workspace and safety status. Browsers get an HTML page. Views are counted apart from clicks.
+// @Tags Redirect
+// @Produce html
+// @Produce json
+// @Param code path string true "Short code"
+// @Success 200 {object} model.LinkPreview
+// @Failure 404 {object} map[string]string
+// @Router /{code}+ [get]
+func (h *URLHandler) Preview(ctx *gofr.Context) (interface{}, error) {
+	code := ctx.PathParam("code")
+
+	preview, err := h.service.Preview(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	ip, userAgent := getIPAndUserAgentFromHeaders(ctx)
+	browser, device := parseUserAgent(userAgent)
+	view := model.Visit{
+		Code:       preview.Code,
+		Timestamp:  time.Now(),
+		IP:         ip,
+		Country:    h.country(ctx),
+		Browser:    browser,
+		Device:     device,
+		VisitorKey: reqinfo.FromContext(ctx).VisitorKey,
+	}
+	go func() {
+		_ = h.visitService.LogPreview(ctx, view)
+	}()
+
+	if !reqinfo.FromContext(ctx).WantsHTML() {
+		return preview, nil
+	}
+
+	var buf bytes.Buffer
+	if err := previewPage.Execute(&buf, preview); err != nil {
+		return nil, err
+	}
+	return response.File{Content: buf.Bytes(), ContentType: "text/html; charset=utf-8"}, nil
+}
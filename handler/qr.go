@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Kritvi0208/ShortEdge/qr"
+	"github.com/Kritvi0208/ShortEdge/reqinfo"
+	"github.com/Kritvi0208/ShortEdge/service"
+
+	"gofr.dev/pkg/gofr"
+	gofrHTTP "gofr.dev/pkg/gofr/http"
+	"gofr.dev/pkg/gofr/http/response"
+)
+
+// QRSource marks clicks from QR code scans in the analytics.
+const QRSource = "qr"
+
+// Bounds of the QR code options.
+const (
+	minQRSize   = 64
+	maxQRSize   = 2048
+	maxQRMargin = 16
+)
+
+type QRHandler struct {
+	service service.URLService
+	baseURL string
+}
+
+// NewQRHandler serves QR codes of short links. baseURL is the public address of short links,
+// e.g. https://sho.rt; without it the address the request came in on is used.
+func NewQRHandler(service service.URLService, baseURL string) *QRHandler {
+	return &QRHandler{service: service, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// Get godoc
+// @Summary Get a QR code for a short URL
+// @Description Renders the full short URL as a QR code. The URL carries src=qr so scans show up as their own source in the analytics.
+// @Tags URL
+// @Produce png
+// @Produce image/svg+xml
+// @Param code path string true "Short code"
+// @Param format query string false "png (default) or svg"
+// @Param size query int false "Width and height in pixels, 64 to 2048, default 256; PNGs round down to whole pixels per module"
+// @Param level query string false "Error correction level: L, M (default), Q or H"
+// @Param margin query int false "Quiet zone in modules, 0 to 16, default 4"
+// @Param fg query string false "Foreground color as hex, default 000000"
+// @Param bg query string false "Background color as hex, default ffffff"
+// @Param track query bool false "Set to false to leave src=qr out of the URL"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /qr/{code} [get]
+func (h *QRHandler) Get(ctx *gofr.Context) (interface{}, error) {
+	style, level, err := parseQRStyle(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Private and internal links only get QR codes for callers who may open them
+	link, err := h.service.GetByCode(ctx, ctx.PathParam("code"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Links on branded domains have codes qualified as code@domain
+	short := h.origin(ctx) + "/" + url.PathEscape(link.Code)
+	if link.Domain != "" {
+		short = "https://" + link.Domain + "/" + url.PathEscape(strings.TrimSuffix(link.Code, "@"+link.Domain))
+	}
+	if ctx.Param("track") != "false" {
+		short += "?src=" + QRSource
+	}
+
+	code, err := qr.Encode([]byte(short), level)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.Param("format") == "svg" {
+		return response.File{Content: code.SVG(style), ContentType: "image/svg+xml"}, nil
+	}
+	img, err := code.PNG(style)
+	if err != nil {
+		return nil, err
+	}
+	return response.File{Content: img, ContentType: "image/png"}, nil
+}
+
+// origin is the scheme and host short links are served on.
+func (h *QRHandler) origin(ctx *gofr.Context) string {
+	if h.baseURL != "" {
+		return h.baseURL
+	}
+
+	host := reqinfo.FromContext(ctx).Host
+	if strings.HasPrefix(host, "localhost") || strings.HasPrefix(host, "127.0.0.1") {
+		return "http://" + host
+	}
+	return "https://" + host
+}
+
+func parseQRStyle(ctx *gofr.Context) (qr.Style, qr.Level, error) {
+	style := qr.DefaultStyle
+	level := qr.M
+
+	if f := ctx.Param("format"); f != "" && f != "png" && f != "svg" {
+		return style, level, gofrHTTP.ErrorInvalidParam{Params: []string{"format"}}
+	}
+
+	var err error
+	if v := ctx.Param("size"); v != "" {
+		style.Size, err = strconv.Atoi(v)
+		if err != nil || style.Size < minQRSize || style.Size > maxQRSize {
+			return style, level, gofrHTTP.ErrorInvalidParam{Params: []string{"size"}}
+		}
+	}
+	if v := ctx.Param("margin"); v != "" {
+		style.Margin, err = strconv.Atoi(v)
+		if err != nil || style.Margin < 0 || style.Margin > maxQRMargin {
+			return style, level, gofrHTTP.ErrorInvalidParam{Params: []string{"margin"}}
+		}
+	}
+	if v := ctx.Param("level"); v != "" {
+		if level, err = qr.ParseLevel(v); err != nil {
+			return style, level, gofrHTTP.ErrorInvalidParam{Params: []string{"level"}}
+		}
+	}
+	if v := ctx.Param("fg"); v != "" {
+		if style.Foreground, err = qr.ParseColor(v); err != nil {
+			return style, level, gofrHTTP.ErrorInvalidParam{Params: []string{"fg"}}
+		}
+	}
+	if v := ctx.Param("bg"); v != "" {
+		if style.Background, err = qr.ParseColor(v); err != nil {
+			return style, level, gofrHTTP.ErrorInvalidParam{Params: []string{"bg"}}
+		}
+	}
+	return style, level, nil
+}
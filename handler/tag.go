@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/service"
+
+	"gofr.dev/pkg/gofr"
+	gofrHTTP "gofr.dev/pkg/gofr/http"
+)
+
+type TagHandler struct {
+	service service.TagService
+}
+
+func NewTagHandler(s service.TagService) *TagHandler {
+	return &TagHandler{service: s}
+}
+
+// GetAll godoc
+// @Summary List tags
+// @Description Fetch every tag
+// @Tags Tag
+// @Produce json
+// @Success 200 {array} model.Tag
+// @Router /tags [get]
+func (h *TagHandler) GetAll(ctx *gofr.Context) (interface{}, error) {
+	return h.service.GetAll(ctx)
+}
+
+// Create godoc
+// @Summary Create a tag
+// @Tags Tag
+// @Accept json
+// @Produce json
+// @Param body body model.TagRequest true "Tag name"
+// @Success 201 {object} model.Tag
+// @Failure 400 {object} map[string]string
+// @Router /tags [post]
+func (h *TagHandler) Create(ctx *gofr.Context) (interface{}, error) {
+	var req model.TagRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	return h.service.Create(ctx, req)
+}
+
+// Rename godoc
+// @Summary Rename a tag
+// @Description Renames a tag; every link carrying it picks up the new name
+// @Tags Tag
+// @Accept json
+// @Produce json
+// @Param id path int true "Tag ID"
+// @Param body body model.TagRequest true "New name"
+// @Success 200 {object} model.Tag
+// @Failure 400 {object} map[string]string
+// @Router /tags/{id} [put]
+func (h *TagHandler) Rename(ctx *gofr.Context) (interface{}, error) {
+	id, err := idParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var req model.TagRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	return h.service.Rename(ctx, id, req)
+}
+
+// Merge godoc
+// @Summary Merge tags
+// @Description Moves links from the source tags onto the target tag and deletes the source tags
+// @Tags Tag
+// @Accept json
+// @Produce json
+// @Param id path int true "Target tag ID"
+// @Param body body model.MergeTagsRequest true "Source tag IDs"
+// @Success 201 {object} model.Tag
+// @Failure 400 {object} map[string]string
+// @Router /tags/{id}/merge [post]
+func (h *TagHandler) Merge(ctx *gofr.Context) (interface{}, error) {
+	id, err := idParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var req model.MergeTagsRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	return h.service.Merge(ctx, id, req)
+}
+
+// Delete godoc
+// @Summary Delete a tag
+// @Description Deletes a tag and removes it from every link
+// @Tags Tag
+// @Param id path int true "Tag ID"
+// @Success 204
+// @Router /tags/{id} [delete]
+func (h *TagHandler) Delete(ctx *gofr.Context) (interface{}, error) {
+	id, err := idParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.service.Delete(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"message": "deleted successfully"}, nil
+}
+
+func idParam(ctx *gofr.Context) (int64, error) {
+	id, err := strconv.ParseInt(ctx.PathParam("id"), 10, 64)
+	if err != nil {
+		return 0, gofrHTTP.ErrorInvalidParam{Params: []string{"id"}}
+	}
+	return id, nil
+}
@@ -1,40 +1,54 @@
 package handler
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Kritvi0208/ShortEdge/geo"
 	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/reqinfo"
 	"github.com/Kritvi0208/ShortEdge/service"
 
 	"gofr.dev/pkg/gofr"
+	gofrHTTP "gofr.dev/pkg/gofr/http"
+	"gofr.dev/pkg/gofr/http/response"
 )
 
 type URLHandler struct {
 	service      service.URLService
 	visitService service.VisitService
+	geo          geo.Resolver
 }
 
-func NewURLHandler(service service.URLService, visitService service.VisitService) *URLHandler {
+func NewURLHandler(service service.URLService, visitService service.VisitService, geo geo.Resolver) *URLHandler {
 	return &URLHandler{
 		service:      service,
 		visitService: visitService,
+		geo:          geo,
 	}
 }
 
 // GetAll godoc
 // @Summary Get all URLs
-// @Description Fetch all shortened URLs
+// @Description Fetch all shortened URLs, optionally filtered by tag, folder or a search term
 // @Tags URL
 // @Produce json
+// @Param q query string false "Search code, destination, tags and page title/description"
+// @Param tag query string false "Tag name"
+// @Param folder query int false "Folder ID, includes sub-folders"
+// @Param workspace query int false "Workspace ID, defaults to the caller's workspace"
 // @Success 200 {array} model.URL
 // @Router /all [get]
 func (h *URLHandler) GetAll(ctx *gofr.Context) (interface{}, error) {
-	urls, err := h.service.GetAll(ctx)
+	filter, err := parseURLFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	urls, err := h.service.GetAll(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -43,7 +57,8 @@ func (h *URLHandler) GetAll(ctx *gofr.Context) (interface{}, error) {
 
 // Shorten godoc
 // @Summary Shorten a URL
-// @Description Create a shortened URL with optional custom code, visibility, and expiry
+// @Description Create a shortened URL with optional custom code, visibility, and expiry. With dedupe on, the caller's
+// @Description existing link to the same destination with the same settings is returned unless force_new is set.
 // @Tags URL
 // @Accept json
 // @Produce json
@@ -68,40 +83,99 @@ func (h *URLHandler) Shorten(ctx *gofr.Context) (interface{}, error) {
 
 // Redirect godoc
 // @Summary Redirect to the original long URL
-// @Description Redirects using the short code and logs analytics
+// @Description Redirects using the short code and logs analytics. Password protected links take the password from the X-Link-Password header; browsers get a password prompt instead.
 // @Tags Redirect
 // @Produce json
 // @Param code path string true "Short code"
+// @Param X-Link-Password header string false "Password of a protected link"
+// @Param src query string false "Channel the click came from, e.g. qr; counted in the analytics"
 // @Success 302 {string} string "Redirects to long URL"
+// @Failure 401 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 404 {object} map[string]string "Not active yet"
+// @Failure 410 {object} map[string]string "Click limit reached or link disabled as unsafe"
 // @Router /{code} [get]
 func (h *URLHandler) Redirect(ctx *gofr.Context) (interface{}, error) {
-	code := ctx.PathParam("code")
+	return h.redirect(ctx, ctx.PathParam("code"), reqinfo.FromContext(ctx).LinkPassword, false)
+}
 
-	link, err := h.service.GetByCode(ctx, code)
-	if err != nil {
+// Unlock godoc
+// @Summary Open a password protected link
+// @Description Takes the password as JSON or from the HTML prompt's form; browsers are redirected, API clients get the redirect instruction
+// @Tags Redirect
+// @Accept json
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param code path string true "Short code"
+// @Param body body model.UnlockRequest true "Link password"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Router /{code} [post]
+func (h *URLHandler) Unlock(ctx *gofr.Context) (interface{}, error) {
+	var req model.UnlockRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	// Browsers submitting the password prompt are sent on to the destination
+	return h.redirect(ctx, ctx.PathParam("code"), req.Password, reqinfo.FromContext(ctx).WantsHTML())
+}
+
+// redirect resolves a link and logs the visit. With follow set it answers with an HTTP redirect
+// rather than the JSON redirect instruction.
+func (h *URLHandler) redirect(ctx *gofr.Context, code, password string, follow bool) (interface{}, error) {
+	info := reqinfo.FromContext(ctx)
+
+	link, err := h.service.Resolve(ctx, code, password)
+
+	var unavailable service.LinkUnavailableError
+	if errors.As(err, &unavailable) && unavailable.Fallback != "" {
+		if follow {
+			return response.Redirect{URL: unavailable.Fallback}, nil
+		}
+		return map[string]interface{}{
+			"redirect": unavailable.Fallback,
+		}, nil
+	}
+
+	if errors.Is(err, service.ErrLinkNotFound) {
 		// Return structured JSON 404
 		return map[string]interface{}{
 			"error": "URL not found",
 		}, nil
 	}
-
-	if link.ExpiresAt != nil && time.Now().After(*link.ExpiresAt) {
+	if errors.Is(err, service.ErrLinkExpired) {
 		return map[string]string{"error": "This link has expired."}, nil
 	}
+	if err != nil && info.WantsHTML() && isPasswordError(err) {
+		return passwordPrompt(code, password, err)
+	}
+	if err != nil {
+		// Private or internal link the caller may not open, or a missing or wrong password
+		return nil, err
+	}
 
 	// Extract IP/User-Agent and log visit
 	ip, userAgent := getIPAndUserAgentFromHeaders(ctx)
 	browser, device := parseUserAgent(userAgent)
-	country := getCountryFromIP(ip)
+	country := h.country(ctx)
 
+	// Logged under the stored code, which is code@domain on branded domains
 	visit := model.Visit{
-		Code:      code,
+		Code:      link.Code,
 		Timestamp: time.Now(),
 		IP:        ip,
 		Country:   country,
 		Browser:   browser,
 		Device:    device,
+		RuleID:    link.RuleID,
+		VariantID: link.VariantID,
+		Source:    visitSource(ctx.Param("src")),
+
+		VisitorKey: reqinfo.FromContext(ctx).VisitorKey,
+
+		WorkspaceID: link.WorkspaceID,
 	}
 
 	// Asynchronously log
@@ -111,6 +185,21 @@ func (h *URLHandler) Redirect(ctx *gofr.Context) (interface{}, error) {
 
 	fmt.Printf("%#v\n", ctx)
 
+	// Deep links fall back to the store listing when the app isn't installed
+	if link.AppFallbackURL != "" {
+		if info.WantsHTML() {
+			return appRedirect(link.LongURL, link.AppFallbackURL)
+		}
+		return map[string]interface{}{
+			"redirect": link.LongURL,
+			"fallback": link.AppFallbackURL,
+		}, nil
+	}
+
+	if follow {
+		return response.Redirect{URL: link.LongURL}, nil
+	}
+
 	// Return redirect instruction (not auto-redirect)
 	return map[string]interface{}{
 		"redirect": link.LongURL,
@@ -137,11 +226,22 @@ func (h *URLHandler) Redirect(ctx *gofr.Context) (interface{}, error) {
 // 	return ip, userAgent
 // }
 
+// getIPAndUserAgentFromHeaders reads the client details recorded by middleware.RequestInfo, as
+// gofr doesn't expose request headers to handlers.
 func getIPAndUserAgentFromHeaders(ctx *gofr.Context) (string, string) {
-	// You said: don't use ctx.Header, ctx.Request, ctx.Request().Header, etc.
-	// So here's the only valid fallback if everything is blocked:
+	info := reqinfo.FromContext(ctx)
+
+	ip := "Unknown"
+	if info.IP.IsValid() {
+		ip = info.IP.String()
+	}
+
+	userAgent := info.UserAgent
+	if userAgent == "" {
+		userAgent = "Unknown"
+	}
 
-	return "Unknown", "Unknown"
+	return ip, userAgent
 }
 
 func parseUserAgent(ua string) (browser string, device string) {
@@ -165,36 +265,33 @@ func parseUserAgent(ua string) (browser string, device string) {
 	return
 }
 
-func getCountryFromIP(ip string) string {
-	if ip == "" || ip == "::1" || ip == "127.0.0.1" || ip == "localhost" {
-		return "Localhost"
-	}
-
-	url := fmt.Sprintf("https://ipwho.is/%s", ip)
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return "Unknown"
+// visitSource cleans up the channel in a short link's src parameter, such as "qr" in the links of
+// QR codes. Anything but a short lowercase name is dropped so it can't flood the breakdowns.
+func visitSource(src string) string {
+	src = strings.ToLower(src)
+	if len(src) > 32 {
+		return ""
 	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
-
-	var result struct {
-		Country string `json:"country"`
+	for _, r := range src {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return ""
+		}
 	}
+	return src
+}
 
-	err = json.Unmarshal(body, &result)
-	if err != nil || result.Country == "" {
+// country names the visitor's country for analytics.
+func (h *URLHandler) country(ctx *gofr.Context) string {
+	loc, err := h.geo.Lookup(ctx, reqinfo.FromContext(ctx).IP)
+	if err != nil || loc.Country == "" {
 		return "Unknown"
 	}
-
-	return result.Country
+	return loc.Country
 }
 
 // Update godoc
 // @Summary Update a short URL
-// @Description Modify long URL or visibility of a short URL
+// @Description Modify long URL, visibility or folder of a short URL. Omitted fields keep their value; folder_id 0 takes the link out of its folder
 // @Tags URL
 // @Accept json
 // @Produce json
@@ -238,6 +335,206 @@ func (h *URLHandler) Delete(ctx *gofr.Context) (interface{}, error) {
 	return map[string]string{"message": "deleted successfully"}, nil
 }
 
+// AttachTags godoc
+// @Summary Tag a short URL
+// @Description Attach tags to a short link, creating missing tags
+// @Tags URL
+// @Accept json
+// @Produce json
+// @Param code path string true "Short code"
+// @Param body body model.LinkTagsRequest true "Tags to attach"
+// @Success 201 {object} model.URL
+// @Failure 400 {object} map[string]string
+// @Router /links/{code}/tags [post]
+func (h *URLHandler) AttachTags(ctx *gofr.Context) (interface{}, error) {
+	code := ctx.PathParam("code")
+
+	var req model.LinkTagsRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	return h.service.AttachTags(ctx, code, req.Tags)
+}
+
+// DetachTags godoc
+// @Summary Untag a short URL
+// @Description Detach the given tags (comma separated) from a short link
+// @Tags URL
+// @Produce json
+// @Param code path string true "Short code"
+// @Param tags query string true "Tags to detach"
+// @Success 200 {object} model.URL
+// @Failure 400 {object} map[string]string
+// @Router /links/{code}/tags [delete]
+func (h *URLHandler) DetachTags(ctx *gofr.Context) (interface{}, error) {
+	code := ctx.PathParam("code")
+
+	tags := ctx.Params("tags")
+	if len(tags) == 0 {
+		return nil, gofrHTTP.ErrorMissingParam{Params: []string{"tags"}}
+	}
+
+	return h.service.DetachTags(ctx, code, tags)
+}
+
+// Move godoc
+// @Summary Move a short URL to another workspace
+// @Description The short code and its analytics are kept
+// @Tags URL
+// @Accept json
+// @Produce json
+// @Param code path string true "Short code"
+// @Param body body model.MoveLinkRequest true "Target workspace"
+// @Success 201 {object} model.URL
+// @Failure 403 {object} map[string]string
+// @Router /links/{code}/move [post]
+func (h *URLHandler) Move(ctx *gofr.Context) (interface{}, error) {
+	code := ctx.PathParam("code")
+
+	var req model.MoveLinkRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	return h.service.Move(ctx, code, req.WorkspaceID)
+}
+
+// ScheduleChange godoc
+// @Summary Schedule a destination change
+// @Description The link switches to the new long URL at the given time
+// @Tags URL
+// @Accept json
+// @Produce json
+// @Param code path string true "Short code"
+// @Param body body model.ScheduledChangeRequest true "New destination and cut-over time"
+// @Success 201 {object} model.ScheduledChange
+// @Failure 400 {object} map[string]string
+// @Router /links/{code}/schedule [post]
+func (h *URLHandler) ScheduleChange(ctx *gofr.Context) (interface{}, error) {
+	code := ctx.PathParam("code")
+
+	var req model.ScheduledChangeRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	return h.service.ScheduleChange(ctx, code, req)
+}
+
+// ScheduledChanges godoc
+// @Summary List pending destination changes
+// @Tags URL
+// @Produce json
+// @Param code path string true "Short code"
+// @Success 200 {array} model.ScheduledChange
+// @Router /links/{code}/schedule [get]
+func (h *URLHandler) ScheduledChanges(ctx *gofr.Context) (interface{}, error) {
+	return h.service.ScheduledChanges(ctx, ctx.PathParam("code"))
+}
+
+// CancelChange godoc
+// @Summary Cancel a pending destination change
+// @Tags URL
+// @Param code path string true "Short code"
+// @Param id path int true "Scheduled change ID"
+// @Success 204
+// @Router /links/{code}/schedule/{id} [delete]
+func (h *URLHandler) CancelChange(ctx *gofr.Context) (interface{}, error) {
+	id, err := idParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, h.service.CancelChange(ctx, ctx.PathParam("code"), id)
+}
+
+// TargetingRules godoc
+// @Summary List targeting rules
+// @Description Rules choosing the destination by visitor country and language, in priority order
+// @Tags URL
+// @Produce json
+// @Param code path string true "Short code"
+// @Success 200 {array} model.TargetingRule
+// @Router /links/{code}/rules [get]
+func (h *URLHandler) TargetingRules(ctx *gofr.Context) (interface{}, error) {
+	return h.service.TargetingRules(ctx, ctx.PathParam("code"))
+}
+
+// SetTargetingRules godoc
+// @Summary Replace targeting rules
+// @Description The first matching rule picks the destination; visitors matching none get the link's own URL
+// @Tags URL
+// @Accept json
+// @Produce json
+// @Param code path string true "Short code"
+// @Param body body model.TargetingRulesRequest true "Rules in priority order"
+// @Success 200 {array} model.TargetingRule
+// @Failure 400 {object} map[string]string
+// @Router /links/{code}/rules [put]
+func (h *URLHandler) SetTargetingRules(ctx *gofr.Context) (interface{}, error) {
+	var req model.TargetingRulesRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	return h.service.SetTargetingRules(ctx, ctx.PathParam("code"), req)
+}
+
+// Variants godoc
+// @Summary List A/B variants
+// @Description Destinations the link splits its traffic across by weight
+// @Tags URL
+// @Produce json
+// @Param code path string true "Short code"
+// @Success 200 {array} model.Variant
+// @Router /links/{code}/variants [get]
+func (h *URLHandler) Variants(ctx *gofr.Context) (interface{}, error) {
+	return h.service.Variants(ctx, ctx.PathParam("code"))
+}
+
+// SetVariants godoc
+// @Summary Replace A/B variants
+// @Description Variants listed with their id keep their analytics, so weights can change mid-test; ones left out are archived
+// @Tags URL
+// @Accept json
+// @Produce json
+// @Param code path string true "Short code"
+// @Param body body model.VariantsRequest true "Variants with their weights"
+// @Success 200 {array} model.Variant
+// @Failure 400 {object} map[string]string
+// @Router /links/{code}/variants [put]
+func (h *URLHandler) SetVariants(ctx *gofr.Context) (interface{}, error) {
+	var req model.VariantsRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	return h.service.SetVariants(ctx, ctx.PathParam("code"), req)
+}
+
+func parseURLFilter(ctx *gofr.Context) (model.URLFilter, error) {
+	filter := model.URLFilter{Tag: ctx.Param("tag"), Query: ctx.Param("q")}
+
+	if folder := ctx.Param("folder"); folder != "" {
+		id, err := strconv.ParseInt(folder, 10, 64)
+		if err != nil {
+			return filter, gofrHTTP.ErrorInvalidParam{Params: []string{"folder"}}
+		}
+		filter.FolderID = &id
+	}
+
+	if workspace := ctx.Param("workspace"); workspace != "" {
+		id, err := strconv.ParseInt(workspace, 10, 64)
+		if err != nil {
+			return filter, gofrHTTP.ErrorInvalidParam{Params: []string{"workspace"}}
+		}
+		filter.WorkspaceID = &id
+	}
+
+	return filter, nil
+}
+
 func HealthHandler(ctx *gofr.Context) (interface{}, error) {
 	return map[string]string{"status": "ok"}, nil
 }
@@ -0,0 +1,127 @@
+package handler_test
+
+import (
+	"context"
+	"database/sql"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/geo"
+	"github.com/Kritvi0208/ShortEdge/handler"
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/reqinfo"
+	"github.com/Kritvi0208/ShortEdge/service"
+	"github.com/Kritvi0208/ShortEdge/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gofr.dev/pkg/gofr"
+)
+
+// urlStore and domainStore only implement what resolving a link needs.
+type urlStore struct {
+	store.URL
+	urls map[string]model.URL
+}
+
+func (s urlStore) GetByCode(ctx context.Context, code string) (model.URL, error) {
+	link, ok := s.urls[code]
+	if !ok {
+		return model.URL{}, sql.ErrNoRows
+	}
+	return link, nil
+}
+
+type domainStore struct {
+	store.Domain
+	domains map[string]model.Domain
+}
+
+func (s domainStore) GetByName(ctx context.Context, name string) (model.Domain, error) {
+	d, ok := s.domains[name]
+	if !ok {
+		return model.Domain{}, sql.ErrNoRows
+	}
+	return d, nil
+}
+
+// visitRecorder passes logged visits on to a channel, as the handler logs them in the background.
+type visitRecorder struct {
+	service.VisitService
+	visits chan model.Visit
+}
+
+func (v visitRecorder) LogVisit(ctx context.Context, visit model.Visit) error {
+	v.visits <- visit
+	return nil
+}
+
+func (v visitRecorder) LogPreview(ctx context.Context, visit model.Visit) error {
+	v.visits <- visit
+	return nil
+}
+
+type noGeo struct{}
+
+func (noGeo) Lookup(ctx context.Context, ip netip.Addr) (geo.Location, error) {
+	return geo.Location{}, nil
+}
+
+// request is a gofr request for a path with a code parameter.
+type request struct {
+	ctx  context.Context
+	code string
+}
+
+func (r request) Context() context.Context    { return r.ctx }
+func (r request) Param(string) string         { return "" }
+func (r request) PathParam(key string) string { return map[string]string{"code": r.code}[key] }
+func (r request) Bind(any) error              { return nil }
+func (r request) HostName() string            { return reqinfo.FromContext(r.ctx).Host }
+func (r request) Params(string) []string      { return nil }
+
+func onHost(host, code string) *gofr.Context {
+	ctx := reqinfo.WithInfo(context.Background(), reqinfo.Info{Host: host})
+	return &gofr.Context{Context: ctx, Request: request{ctx: ctx, code: code}}
+}
+
+func TestVisitsOfBrandedLinks(t *testing.T) {
+	verified := time.Now()
+	urls := urlStore{urls: map[string]model.URL{
+		"sale":             {Code: "sale", LongURL: "https://example.com/default", Visibility: model.VisibilityPublic},
+		"sale@go.acme.com": {Code: "sale@go.acme.com", LongURL: "https://acme.com/sale", Visibility: model.VisibilityPublic},
+	}}
+	domains := domainStore{domains: map[string]model.Domain{
+		"go.acme.com": {ID: 1, Name: "go.acme.com", VerifiedAt: &verified},
+	}}
+	svc := service.New(urls, service.WithDomainStore(domains))
+	visits := visitRecorder{visits: make(chan model.Visit, 1)}
+	h := handler.NewURLHandler(svc, visits, noGeo{})
+
+	res, err := h.Redirect(onHost("go.acme.com", "sale"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"redirect": "https://acme.com/sale"}, res)
+	assert.Equal(t, "sale@go.acme.com", (<-visits.visits).Code, "not the default domain's sale")
+
+	_, err = h.Redirect(onHost("sho.rt", "sale"))
+	require.NoError(t, err)
+	assert.Equal(t, "sale", (<-visits.visits).Code)
+}
+
+func TestPreviewsOfBrandedLinks(t *testing.T) {
+	verified := time.Now()
+	urls := urlStore{urls: map[string]model.URL{
+		"sale@go.acme.com": {Code: "sale@go.acme.com", LongURL: "https://acme.com/sale", Visibility: model.VisibilityPublic},
+	}}
+	domains := domainStore{domains: map[string]model.Domain{
+		"go.acme.com": {ID: 1, Name: "go.acme.com", VerifiedAt: &verified},
+	}}
+	svc := service.New(urls, service.WithDomainStore(domains))
+	views := visitRecorder{visits: make(chan model.Visit, 1)}
+	h := handler.NewURLHandler(svc, views, noGeo{})
+
+	res, err := h.Preview(onHost("go.acme.com", "sale"))
+	require.NoError(t, err)
+	assert.Equal(t, "sale@go.acme.com", res.(model.LinkPreview).Code)
+	assert.Equal(t, "sale@go.acme.com", (<-views.visits).Code)
+}
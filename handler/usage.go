@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/service"
+
+	"gofr.dev/pkg/gofr"
+	gofrHTTP "gofr.dev/pkg/gofr/http"
+)
+
+type UsageHandler struct {
+	service service.QuotaService
+}
+
+func NewUsageHandler(s service.QuotaService) *UsageHandler {
+	return &UsageHandler{service: s}
+}
+
+// Get godoc
+// @Summary Get workspace usage
+// @Description Current consumption of each quota, the plan's limits (0 means unlimited) and when monthly quotas reset
+// @Tags Usage
+// @Produce json
+// @Param workspace query int false "Workspace ID, defaults to the caller's workspace"
+// @Success 200 {object} model.Usage
+// @Failure 403 {object} map[string]string
+// @Router /usage [get]
+func (h *UsageHandler) Get(ctx *gofr.Context) (interface{}, error) {
+	var workspaceID *int64
+	if workspace := ctx.Param("workspace"); workspace != "" {
+		id, err := strconv.ParseInt(workspace, 10, 64)
+		if err != nil {
+			return nil, gofrHTTP.ErrorInvalidParam{Params: []string{"workspace"}}
+		}
+		workspaceID = &id
+	}
+
+	return h.service.Usage(ctx, workspaceID)
+}
+
+// SetPlan godoc
+// @Summary Change a workspace's plan
+// @Description Admins only
+// @Tags Usage
+// @Accept json
+// @Produce json
+// @Param id path int true "Workspace ID"
+// @Param body body model.PlanRequest true "Plan name"
+// @Success 200 {object} model.Workspace
+// @Failure 403 {object} map[string]string
+// @Router /workspaces/{id}/plan [put]
+func (h *UsageHandler) SetPlan(ctx *gofr.Context) (interface{}, error) {
+	id, err := idParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var req model.PlanRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	return h.service.SetPlan(ctx, id, req)
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/service"
+
+	"gofr.dev/pkg/gofr"
+)
+
+type UserHandler struct {
+	service service.UserService
+}
+
+func NewUserHandler(s service.UserService) *UserHandler {
+	return &UserHandler{service: s}
+}
+
+// Register godoc
+// @Summary Register a user account
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param body body model.RegisterRequest true "Email and password"
+// @Success 201 {object} model.User
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /register [post]
+func (h *UserHandler) Register(ctx *gofr.Context) (interface{}, error) {
+	var req model.RegisterRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	return h.service.Register(ctx, req)
+}
+
+// Login godoc
+// @Summary Log in
+// @Description Returns a session token to send as "Authorization: Bearer <token>"
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param body body model.LoginRequest true "Email and password"
+// @Success 201 {object} model.Session
+// @Failure 401 {object} map[string]string
+// @Router /login [post]
+func (h *UserHandler) Login(ctx *gofr.Context) (interface{}, error) {
+	var req model.LoginRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	return h.service.Login(ctx, req)
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description Invalidates the session token of the request
+// @Tags User
+// @Produce json
+// @Success 201 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /logout [post]
+func (h *UserHandler) Logout(ctx *gofr.Context) (interface{}, error) {
+	if err := h.service.Logout(ctx); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"message": "logged out"}, nil
+}
+
+// Me godoc
+// @Summary Current user
+// @Tags User
+// @Produce json
+// @Success 200 {object} model.User
+// @Failure 401 {object} map[string]string
+// @Router /me [get]
+func (h *UserHandler) Me(ctx *gofr.Context) (interface{}, error) {
+	return h.service.Me(ctx)
+}
@@ -25,9 +25,82 @@ func NewVisitHandler(s service.VisitService) *VisitHandler {
 // @Router /analytics/{code} [get]
 func (h *VisitHandler) GetAnalytics(ctx *gofr.Context) (interface{}, error) {
 	code := ctx.PathParam("code")
-	visits, err := h.service.GetAnalytics(ctx.Context, code)
+
+	visits, err := h.service.GetAnalytics(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	return visits, nil
+}
+
+// GetFilteredAnalytics godoc
+// @Summary Get analytics for a group of short URLs
+// @Description Fetch visit logs of every active link of a workspace matching a tag and/or folder
+// @Tags Analytics
+// @Produce json
+// @Param workspace query int false "Workspace ID, defaults to the caller's workspace"
+// @Param tag query string false "Tag name"
+// @Param folder query int false "Folder ID, includes sub-folders"
+// @Success 200 {array} model.Visit
+// @Failure 400 {object} map[string]string
+// @Router /analytics [get]
+func (h *VisitHandler) GetFilteredAnalytics(ctx *gofr.Context) (interface{}, error) {
+	filter, err := parseURLFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	visits, err := h.service.GetFilteredAnalytics(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 	return visits, nil
 }
+
+// RuleBreakdown godoc
+// @Summary Get clicks by targeting rule
+// @Description Visits of a short link grouped by the targeting rule that chose their destination; a null rule_id is the default destination
+// @Tags Analytics
+// @Produce json
+// @Param code path string true "Short code"
+// @Success 200 {array} model.RuleClicks
+// @Router /analytics/{code}/rules [get]
+func (h *VisitHandler) RuleBreakdown(ctx *gofr.Context) (interface{}, error) {
+	return h.service.RuleBreakdown(ctx, ctx.PathParam("code"))
+}
+
+// VariantBreakdown godoc
+// @Summary Get clicks by A/B variant
+// @Description Clicks and unique visitors of a short link per variant; a null variant_id counts visits that weren't split
+// @Tags Analytics
+// @Produce json
+// @Param code path string true "Short code"
+// @Success 200 {array} model.VariantClicks
+// @Router /analytics/{code}/variants [get]
+func (h *VisitHandler) VariantBreakdown(ctx *gofr.Context) (interface{}, error) {
+	return h.service.VariantBreakdown(ctx, ctx.PathParam("code"))
+}
+
+// SourceBreakdown godoc
+// @Summary Get clicks by source
+// @Description Clicks of a short link grouped by the channel in its src parameter, e.g. "qr" for scans of its QR code; an empty source counts plain clicks
+// @Tags Analytics
+// @Produce json
+// @Param code path string true "Short code"
+// @Success 200 {array} model.SourceClicks
+// @Router /analytics/{code}/sources [get]
+func (h *VisitHandler) SourceBreakdown(ctx *gofr.Context) (interface{}, error) {
+	return h.service.SourceBreakdown(ctx, ctx.PathParam("code"))
+}
+
+// PreviewStats godoc
+// @Summary Get preview views
+// @Description Views of the link's preview page (/{code}+), counted apart from its clicks
+// @Tags Analytics
+// @Produce json
+// @Param code path string true "Short code"
+// @Success 200 {object} model.PreviewStats
+// @Router /analytics/{code}/previews [get]
+func (h *VisitHandler) PreviewStats(ctx *gofr.Context) (interface{}, error) {
+	return h.service.PreviewStats(ctx, ctx.PathParam("code"))
+}
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/service"
+
+	"gofr.dev/pkg/gofr"
+	gofrHTTP "gofr.dev/pkg/gofr/http"
+)
+
+type WorkspaceHandler struct {
+	service service.WorkspaceService
+}
+
+func NewWorkspaceHandler(s service.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{service: s}
+}
+
+// GetAll godoc
+// @Summary List your workspaces
+// @Description Fetch every workspace the caller belongs to, with their role in it
+// @Tags Workspace
+// @Produce json
+// @Success 200 {array} model.Workspace
+// @Failure 401 {object} map[string]string
+// @Router /workspaces [get]
+func (h *WorkspaceHandler) GetAll(ctx *gofr.Context) (interface{}, error) {
+	return h.service.List(ctx)
+}
+
+// Create godoc
+// @Summary Create a workspace
+// @Description The caller becomes its owner
+// @Tags Workspace
+// @Accept json
+// @Produce json
+// @Param body body model.WorkspaceRequest true "Workspace name"
+// @Success 201 {object} model.Workspace
+// @Router /workspaces [post]
+func (h *WorkspaceHandler) Create(ctx *gofr.Context) (interface{}, error) {
+	var req model.WorkspaceRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	return h.service.Create(ctx, req)
+}
+
+// Rename godoc
+// @Summary Rename a workspace and set its code style
+// @Tags Workspace
+// @Accept json
+// @Produce json
+// @Param id path int true "Workspace ID"
+// @Param body body model.WorkspaceRequest true "Workspace name and, optionally, code style"
+// @Success 200 {object} model.Workspace
+// @Failure 403 {object} map[string]string
+// @Router /workspaces/{id} [put]
+func (h *WorkspaceHandler) Rename(ctx *gofr.Context) (interface{}, error) {
+	id, err := idParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var req model.WorkspaceRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	return h.service.Rename(ctx, id, req)
+}
+
+// Members godoc
+// @Summary List workspace members
+// @Tags Workspace
+// @Produce json
+// @Param id path int true "Workspace ID"
+// @Success 200 {array} model.Member
+// @Failure 403 {object} map[string]string
+// @Router /workspaces/{id}/members [get]
+func (h *WorkspaceHandler) Members(ctx *gofr.Context) (interface{}, error) {
+	id, err := idParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.service.Members(ctx, id)
+}
+
+// SetMemberRole godoc
+// @Summary Change a member's role
+// @Description Only owners can grant or take away ownership
+// @Tags Workspace
+// @Accept json
+// @Produce json
+// @Param id path int true "Workspace ID"
+// @Param user_id path int true "User ID"
+// @Param body body model.MemberRoleRequest true "owner, admin, editor or viewer"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /workspaces/{id}/members/{user_id} [put]
+func (h *WorkspaceHandler) SetMemberRole(ctx *gofr.Context) (interface{}, error) {
+	id, userID, err := memberParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var req model.MemberRoleRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	if err := h.service.SetMemberRole(ctx, id, userID, req); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"message": "role updated"}, nil
+}
+
+// RemoveMember godoc
+// @Summary Remove a member
+// @Description Admins can remove members; anyone can remove themselves to leave
+// @Tags Workspace
+// @Param id path int true "Workspace ID"
+// @Param user_id path int true "User ID"
+// @Success 204
+// @Router /workspaces/{id}/members/{user_id} [delete]
+func (h *WorkspaceHandler) RemoveMember(ctx *gofr.Context) (interface{}, error) {
+	id, userID, err := memberParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, h.service.RemoveMember(ctx, id, userID)
+}
+
+// Invite godoc
+// @Summary Invite someone to a workspace
+// @Description The invitation token is only returned once; the invitee accepts it while logged in
+// @Tags Workspace
+// @Accept json
+// @Produce json
+// @Param id path int true "Workspace ID"
+// @Param body body model.InvitationRequest true "Email and role"
+// @Success 201 {object} model.Invitation
+// @Failure 403 {object} map[string]string
+// @Router /workspaces/{id}/invitations [post]
+func (h *WorkspaceHandler) Invite(ctx *gofr.Context) (interface{}, error) {
+	id, err := idParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var req model.InvitationRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	return h.service.Invite(ctx, id, req)
+}
+
+// Invitations godoc
+// @Summary List a workspace's invitations
+// @Tags Workspace
+// @Produce json
+// @Param id path int true "Workspace ID"
+// @Success 200 {array} model.Invitation
+// @Router /workspaces/{id}/invitations [get]
+func (h *WorkspaceHandler) Invitations(ctx *gofr.Context) (interface{}, error) {
+	id, err := idParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.service.Invitations(ctx, id)
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke an invitation
+// @Tags Workspace
+// @Param id path int true "Workspace ID"
+// @Param invitation_id path int true "Invitation ID"
+// @Success 204
+// @Router /workspaces/{id}/invitations/{invitation_id} [delete]
+func (h *WorkspaceHandler) RevokeInvitation(ctx *gofr.Context) (interface{}, error) {
+	id, err := idParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	invitationID, err := strconv.ParseInt(ctx.PathParam("invitation_id"), 10, 64)
+	if err != nil {
+		return nil, gofrHTTP.ErrorInvalidParam{Params: []string{"invitation_id"}}
+	}
+
+	return nil, h.service.RevokeInvitation(ctx, id, invitationID)
+}
+
+// AcceptInvitation godoc
+// @Summary Accept an invitation
+// @Tags Workspace
+// @Accept json
+// @Produce json
+// @Param body body model.AcceptInvitationRequest true "Invitation token"
+// @Success 201 {object} model.Workspace
+// @Failure 401 {object} map[string]string
+// @Router /invitations/accept [post]
+func (h *WorkspaceHandler) AcceptInvitation(ctx *gofr.Context) (interface{}, error) {
+	var req model.AcceptInvitationRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	return h.service.AcceptInvitation(ctx, req)
+}
+
+func memberParams(ctx *gofr.Context) (int64, int64, error) {
+	id, err := idParam(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	userID, err := strconv.ParseInt(ctx.PathParam("user_id"), 10, 64)
+	if err != nil {
+		return 0, 0, gofrHTTP.ErrorInvalidParam{Params: []string{"user_id"}}
+	}
+
+	return id, userID, nil
+}
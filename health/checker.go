@@ -0,0 +1,126 @@
+// Package health checks whether link destinations still work: it requests them with HEAD,
+// falling back to GET, and records the status, latency, redirects and TLS certificate expiry.
+package health
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/safehttp"
+)
+
+const (
+	defaultMaxRedirects = 10
+	defaultUserAgent    = "ShortEdgeBot/1.0 (+link health check)"
+)
+
+type Config struct {
+	Timeout      time.Duration // per request, 0 means 10s
+	MaxRedirects int           // 0 means 10
+	UserAgent    string
+	AllowPrivate bool // only for tests, see safehttp.Config
+}
+
+type Checker struct {
+	client       *http.Client
+	maxRedirects int
+	userAgent    string
+}
+
+func NewChecker(cfg Config) *Checker {
+	if cfg.MaxRedirects <= 0 {
+		cfg.MaxRedirects = defaultMaxRedirects
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = defaultUserAgent
+	}
+
+	return &Checker{
+		// Redirects are followed by hand to record the chain
+		client: safehttp.NewClient(safehttp.Config{
+			Timeout:      cfg.Timeout,
+			MaxRedirects: -1,
+			AllowPrivate: cfg.AllowPrivate,
+		}),
+		maxRedirects: cfg.MaxRedirects,
+		userAgent:    cfg.UserAgent,
+	}
+}
+
+// Check requests rawURL with HEAD and, when that fails or is refused, with GET. Failures are
+// reported in the result's Error rather than returned.
+func (c *Checker) Check(ctx context.Context, rawURL string) model.HealthCheck {
+	start := time.Now()
+
+	check := c.request(ctx, http.MethodHead, rawURL)
+	if !check.OK() && ctx.Err() == nil {
+		// Plenty of servers answer HEAD with 405, 403 or 404, or not at all
+		check = c.request(ctx, http.MethodGet, rawURL)
+	}
+
+	check.LatencyMS = time.Since(start).Milliseconds()
+	check.CheckedAt = time.Now()
+	return check
+}
+
+func (c *Checker) request(ctx context.Context, method, rawURL string) model.HealthCheck {
+	check := model.HealthCheck{Method: method}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		check.Error = safehttp.ErrUnsupportedURL.Error()
+		return check
+	}
+
+	for hops := 0; ; hops++ {
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			check.Error = safehttp.ErrUnsupportedURL.Error()
+			return check
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+		if err != nil {
+			check.Error = err.Error()
+			return check
+		}
+		req.Header.Set("User-Agent", c.userAgent)
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			check.Error = err.Error()
+			return check
+		}
+		// Only the status matters; a little of the body lets the connection be reused
+		_, _ = io.CopyN(io.Discard, resp.Body, 4<<10)
+		resp.Body.Close()
+
+		check.StatusCode = resp.StatusCode
+		check.TLSExpiresAt = nil
+		if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+			expires := resp.TLS.PeerCertificates[0].NotAfter
+			check.TLSExpiresAt = &expires
+		}
+
+		location := resp.Header.Get("Location")
+		if resp.StatusCode < 300 || resp.StatusCode >= 400 || location == "" {
+			return check
+		}
+
+		check.RedirectChain = append(check.RedirectChain, model.RedirectHop{URL: u.String(), StatusCode: resp.StatusCode})
+		if hops == c.maxRedirects {
+			check.Error = fmt.Sprintf("%s after %d redirects", safehttp.ErrTooManyRedirects, hops)
+			return check
+		}
+		next, err := u.Parse(location)
+		if err != nil {
+			check.Error = fmt.Sprintf("invalid redirect to %q", location)
+			return check
+		}
+		u = next
+	}
+}
@@ -0,0 +1,86 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/moved", http.StatusMovedPermanently)
+		case "/moved":
+			http.Redirect(w, r, "/page", http.StatusFound)
+		case "/page":
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewChecker(Config{AllowPrivate: true, MaxRedirects: 3})
+	ctx := context.Background()
+
+	check := c.Check(ctx, srv.URL+"/old")
+	assert.True(t, check.OK())
+	assert.Equal(t, http.MethodHead, check.Method)
+	assert.Equal(t, http.StatusOK, check.StatusCode)
+	assert.Equal(t, []model.RedirectHop{
+		{URL: srv.URL + "/old", StatusCode: http.StatusMovedPermanently},
+		{URL: srv.URL + "/moved", StatusCode: http.StatusFound},
+	}, check.RedirectChain)
+	assert.Nil(t, check.TLSExpiresAt)
+	assert.False(t, check.CheckedAt.IsZero())
+
+	check = c.Check(ctx, srv.URL+"/no-head")
+	assert.True(t, check.OK())
+	assert.Equal(t, http.MethodGet, check.Method, "falls back to GET")
+
+	check = c.Check(ctx, srv.URL+"/gone")
+	assert.False(t, check.OK())
+	assert.Equal(t, http.StatusNotFound, check.StatusCode)
+
+	check = c.Check(ctx, srv.URL+"/loop")
+	assert.False(t, check.OK())
+	assert.Contains(t, check.Error, "too many redirects")
+	assert.Len(t, check.RedirectChain, 4)
+
+	check = c.Check(ctx, "ftp://example.com/file")
+	assert.False(t, check.OK())
+
+	srv.Close()
+	check = c.Check(ctx, srv.URL+"/page")
+	assert.False(t, check.OK())
+	assert.Zero(t, check.StatusCode)
+	assert.NotEmpty(t, check.Error)
+}
+
+func TestCheck_TLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	c := NewChecker(Config{AllowPrivate: true})
+
+	check := c.Check(context.Background(), srv.URL)
+	assert.False(t, check.OK(), "the test certificate isn't trusted")
+	assert.Contains(t, check.Error, "certificate")
+
+	c.client.Transport.(*http.Transport).TLSClientConfig = srv.Client().Transport.(*http.Transport).TLSClientConfig
+	check = c.Check(context.Background(), srv.URL)
+	assert.True(t, check.OK())
+	require.NotNil(t, check.TLSExpiresAt)
+	assert.Equal(t, srv.Certificate().NotAfter, *check.TLSExpiresAt)
+}
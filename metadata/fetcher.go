@@ -0,0 +1,97 @@
+// Package metadata fetches a destination page and extracts its title, description, OpenGraph
+// and Twitter card fields and favicon.
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/safehttp"
+)
+
+const (
+	defaultMaxBytes  = 1 << 20 // 1 MiB is plenty for a <head>
+	defaultUserAgent = "ShortEdgeBot/1.0 (+link preview)"
+)
+
+type Config struct {
+	Timeout      time.Duration
+	MaxBytes     int64
+	MaxRedirects int
+	UserAgent    string
+	AllowPrivate bool // only for tests, see safehttp.Config
+}
+
+type Fetcher struct {
+	client    *http.Client
+	maxBytes  int64
+	userAgent string
+}
+
+func NewFetcher(cfg Config) *Fetcher {
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = defaultMaxBytes
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = defaultUserAgent
+	}
+
+	return &Fetcher{
+		client: safehttp.NewClient(safehttp.Config{
+			Timeout:      cfg.Timeout,
+			MaxRedirects: cfg.MaxRedirects,
+			AllowPrivate: cfg.AllowPrivate,
+		}),
+		maxBytes:  cfg.MaxBytes,
+		userAgent: cfg.UserAgent,
+	}
+}
+
+// Fetch downloads at most MaxBytes of rawURL and extracts its metadata. Relative favicon and
+// image URLs are resolved against the final URL after redirects.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (model.LinkMetadata, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return model.LinkMetadata{}, safehttp.ErrUnsupportedURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return model.LinkMetadata{}, err
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return model.LinkMetadata{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return model.LinkMetadata{}, fmt.Errorf("destination answered %d", resp.StatusCode)
+	}
+
+	final := resp.Request.URL
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "" && mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		// Not a page (PDF, image, ...): nothing to extract, but the fetch itself succeeded.
+		return model.LinkMetadata{FaviconURL: resolve(final, "/favicon.ico"), FetchedAt: time.Now()}, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBytes))
+	if err != nil {
+		return model.LinkMetadata{}, err
+	}
+
+	meta := Parse(body, final)
+	meta.FetchedAt = time.Now()
+	return meta, nil
+}
@@ -0,0 +1,94 @@
+package metadata_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Kritvi0208/ShortEdge/metadata"
+	"github.com/Kritvi0208/ShortEdge/safehttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const page = `<!doctype html>
+<html><head>
+<title>  Launch &amp; Learn
+</title>
+<meta name="description" content="All about the launch">
+<meta property="og:title" content="OG Launch">
+<meta property='og:image' content='/img/cover.png'>
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:title" content="Tweet Launch">
+<link rel="shortcut icon" href="/static/fav.ico">
+</head><body>hello</body></html>`
+
+func TestFetch_ExtractsMetadata(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/page", http.StatusMovedPermanently)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(page))
+	}))
+	defer srv.Close()
+
+	f := metadata.NewFetcher(metadata.Config{AllowPrivate: true})
+
+	meta, err := f.Fetch(context.Background(), srv.URL+"/old")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Launch & Learn", meta.Title)
+	assert.Equal(t, "All about the launch", meta.Description)
+	assert.Equal(t, "OG Launch", meta.OGTitle)
+	assert.Equal(t, srv.URL+"/img/cover.png", meta.OGImage)
+	assert.Equal(t, "summary_large_image", meta.TwitterCard)
+	assert.Equal(t, "Tweet Launch", meta.TwitterTitle)
+	assert.Equal(t, srv.URL+"/static/fav.ico", meta.FaviconURL)
+	assert.False(t, meta.FetchedAt.IsZero())
+}
+
+func TestFetch_BlocksPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(page))
+	}))
+	defer srv.Close()
+
+	f := metadata.NewFetcher(metadata.Config{})
+
+	_, err := f.Fetch(context.Background(), srv.URL)
+	assert.True(t, errors.Is(err, safehttp.ErrPrivateAddress), "got %v", err)
+
+	_, err = f.Fetch(context.Background(), "file:///etc/passwd")
+	assert.ErrorIs(t, err, safehttp.ErrUnsupportedURL)
+}
+
+func TestFetch_RedirectLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Path+"x", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	f := metadata.NewFetcher(metadata.Config{AllowPrivate: true, MaxRedirects: 3})
+
+	_, err := f.Fetch(context.Background(), srv.URL+"/")
+	assert.True(t, errors.Is(err, safehttp.ErrTooManyRedirects), "got %v", err)
+}
+
+func TestFetch_SizeLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html><head>" + strings.Repeat(" ", 4096) + "<title>Too late</title>"))
+	}))
+	defer srv.Close()
+
+	f := metadata.NewFetcher(metadata.Config{AllowPrivate: true, MaxBytes: 1024})
+
+	meta, err := f.Fetch(context.Background(), srv.URL)
+	require.NoError(t, err)
+	assert.Empty(t, meta.Title)
+}
@@ -0,0 +1,131 @@
+package metadata
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+)
+
+var (
+	titleRe = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	metaRe  = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	linkRe  = regexp.MustCompile(`(?is)<link\s[^>]*>`)
+	attrRe  = regexp.MustCompile(`(?is)([a-z_:.-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	spaceRe = regexp.MustCompile(`\s+`)
+)
+
+// Parse extracts metadata from an HTML document. base is used to resolve relative URLs.
+func Parse(doc []byte, base *url.URL) model.LinkMetadata {
+	var meta model.LinkMetadata
+
+	if m := titleRe.FindSubmatch(doc); m != nil {
+		meta.Title = clean(string(m[1]))
+	}
+
+	for _, tag := range metaRe.FindAll(doc, -1) {
+		attrs := attributes(tag)
+
+		key := strings.ToLower(attrs["property"])
+		if key == "" {
+			key = strings.ToLower(attrs["name"])
+		}
+		content := clean(attrs["content"])
+		if content == "" {
+			continue
+		}
+
+		switch key {
+		case "description":
+			meta.Description = first(meta.Description, content)
+		case "og:title":
+			meta.OGTitle = first(meta.OGTitle, content)
+		case "og:description":
+			meta.OGDescription = first(meta.OGDescription, content)
+		case "og:image", "og:image:url", "og:image:secure_url":
+			meta.OGImage = first(meta.OGImage, resolve(base, content))
+		case "og:site_name":
+			meta.OGSiteName = first(meta.OGSiteName, content)
+		case "og:type":
+			meta.OGType = first(meta.OGType, content)
+		case "twitter:card":
+			meta.TwitterCard = first(meta.TwitterCard, content)
+		case "twitter:title":
+			meta.TwitterTitle = first(meta.TwitterTitle, content)
+		case "twitter:description":
+			meta.TwitterDescription = first(meta.TwitterDescription, content)
+		case "twitter:image", "twitter:image:src":
+			meta.TwitterImage = first(meta.TwitterImage, resolve(base, content))
+		}
+	}
+
+	for _, tag := range linkRe.FindAll(doc, -1) {
+		attrs := attributes(tag)
+		if attrs["href"] == "" {
+			continue
+		}
+
+		for _, rel := range strings.Fields(strings.ToLower(attrs["rel"])) {
+			if rel == "icon" || rel == "apple-touch-icon" {
+				// Prefer a plain "icon" over the touch icon.
+				if meta.FaviconURL == "" || rel == "icon" {
+					meta.FaviconURL = resolve(base, strings.TrimSpace(attrs["href"]))
+				}
+			}
+		}
+	}
+
+	if meta.FaviconURL == "" {
+		meta.FaviconURL = resolve(base, "/favicon.ico")
+	}
+
+	return meta
+}
+
+func attributes(tag []byte) map[string]string {
+	attrs := make(map[string]string)
+	for _, m := range attrRe.FindAllSubmatch(tag, -1) {
+		name := strings.ToLower(string(m[1]))
+		if _, seen := attrs[name]; seen {
+			continue
+		}
+		attrs[name] = html.UnescapeString(string(m[2]) + string(m[3]) + string(m[4]))
+	}
+	return attrs
+}
+
+func clean(s string) string {
+	s = html.UnescapeString(s)
+	s = spaceRe.ReplaceAllString(s, " ")
+	s = strings.TrimSpace(s)
+
+	const maxLen = 1024
+	if len(s) > maxLen {
+		s = strings.ToValidUTF8(s[:maxLen], "")
+	}
+	return s
+}
+
+func first(current, candidate string) string {
+	if current != "" {
+		return current
+	}
+	return candidate
+}
+
+func resolve(base *url.URL, ref string) string {
+	if base == nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	resolved := base.ResolveReference(r)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return ""
+	}
+	return resolved.String()
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Kritvi0208/ShortEdge/auth"
+	"github.com/Kritvi0208/ShortEdge/service"
+	"github.com/golang-jwt/jwt/v5"
+	gofrMiddleware "gofr.dev/pkg/gofr/http/middleware"
+)
+
+// Authenticate resolves an "X-Api-Key" header, the claims of a JWT validated by OAuth or an
+// "Authorization: Bearer <session token>" header into the caller's principal, which the service
+// layer reads from the request context. Requests without credentials continue anonymously; a bad
+// or expired credential is rejected. sso may be nil when single sign-on isn't configured.
+func Authenticate(users service.UserService, keys service.APIKeyService, sso service.SSOService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var (
+				p   auth.Principal
+				err error
+			)
+
+			if key := r.Header.Get("X-Api-Key"); key != "" {
+				if p, err = keys.Authenticate(r.Context(), key); err != nil {
+					writeError(w, http.StatusUnauthorized, "invalid, revoked or expired api key")
+					return
+				}
+			} else if claims, ok := r.Context().Value(gofrMiddleware.JWTClaim).(jwt.MapClaims); ok && sso != nil {
+				if p, err = sso.Authenticate(r.Context(), claims); err != nil {
+					writeError(w, http.StatusUnauthorized, "token doesn't map to a user")
+					return
+				}
+			} else if token, ok := bearerToken(r); ok {
+				if p, err = users.Authenticate(r.Context(), token); err != nil {
+					writeError(w, http.StatusUnauthorized, "invalid or expired session token")
+					return
+				}
+			} else {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), p)))
+		})
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if len(header) < len("Bearer ") || !strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		return "", false
+	}
+
+	token := strings.TrimSpace(header[len("Bearer "):])
+	return token, token != ""
+}
+
+// writeError answers in the same shape gofr uses for handler errors.
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]string{"message": message}})
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Kritvi0208/ShortEdge/service"
+)
+
+// DomainRoot sends visitors of a branded domain's bare root to the domain's root URL, rather
+// than showing ShortEdge's own frontend. Domains without one fall through to the frontend.
+func DomainRoot(domains service.DomainService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/" && (r.Method == http.MethodGet || r.Method == http.MethodHead) {
+				if d, ok := domains.Lookup(r.Context(), r.Host); ok && d.RootURL != "" {
+					http.Redirect(w, r, d.RootURL, http.StatusFound)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
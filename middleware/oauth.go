@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	gofrMiddleware "gofr.dev/pkg/gofr/http/middleware"
+)
+
+// OAuth validates bearer JWTs with gofr's OAuth middleware against keys. With required set every
+// request needs a valid token, as with App.EnableOAuth; otherwise only bearer tokens that look
+// like JWTs are checked, so session tokens, API keys and anonymous visitors keep working.
+func OAuth(keys gofrMiddleware.PublicKeyProvider, required bool, opts ...jwt.ParserOption) func(http.Handler) http.Handler {
+	validate := gofrMiddleware.OAuth(keys, opts...)
+
+	return func(next http.Handler) http.Handler {
+		validated := validate(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token, ok := bearerToken(r); required || (ok && looksLikeJWT(token)) {
+				validated.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func looksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
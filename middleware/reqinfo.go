@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/reqinfo"
+)
+
+// RequestInfo records the client address and headers of a request in its context. Proxy
+// headers (X-Forwarded-For, X-Real-IP) are only honoured with trustProxy set, as clients can
+// send them freely.
+func RequestInfo(trustProxy bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := clientIP(r, trustProxy)
+			info := reqinfo.Info{
+				IP:             ip,
+				UserAgent:      r.UserAgent(),
+				AcceptLanguage: r.Header.Get("Accept-Language"),
+				Accept:         r.Header.Get("Accept"),
+				Host:           r.Host,
+				LinkPassword:   r.Header.Get("X-Link-Password"),
+				VisitorKey:     visitorKey(w, r, ip),
+			}
+
+			next.ServeHTTP(w, r.WithContext(reqinfo.WithInfo(r.Context(), info)))
+		})
+	}
+}
+
+// VisitorCookie holds the visitor key, keeping A/B assignments sticky for browsers.
+const VisitorCookie = "shortedge_visitor"
+
+// visitorKey returns the key of the client's visitor cookie. Clients without one get a hash of
+// their address and User-Agent, so those not keeping cookies still get a stable key, and
+// browsers get it as their cookie.
+func visitorKey(w http.ResponseWriter, r *http.Request, ip netip.Addr) string {
+	if c, err := r.Cookie(VisitorCookie); err == nil && validVisitorKey(c.Value) {
+		return c.Value
+	}
+
+	sum := sha256.Sum256([]byte(ip.String() + "|" + r.UserAgent()))
+	key := hex.EncodeToString(sum[:16])
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     VisitorCookie,
+		Value:    key,
+		Path:     "/",
+		MaxAge:   int((365 * 24 * time.Hour).Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return key
+}
+
+// validVisitorKey accepts keys as visitorKey makes them, so clients can't store arbitrary data.
+func validVisitorKey(key string) bool {
+	if len(key) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(key)
+	return err == nil
+}
+
+func clientIP(r *http.Request, trustProxy bool) netip.Addr {
+	if trustProxy {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			first, _, _ := strings.Cut(fwd, ",")
+			if ip, err := netip.ParseAddr(strings.TrimSpace(first)); err == nil {
+				return ip.Unmap()
+			}
+		}
+		if ip, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+			return ip.Unmap()
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return ip.Unmap()
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/Kritvi0208/ShortEdge/auth"
+	"github.com/Kritvi0208/ShortEdge/service"
+
+	"gofr.dev/pkg/gofr"
+)
+
+// RequireScope wraps handlers that need scope. Anonymous requests pass through and are left
+// to the service's own rules; authenticated callers must hold the scope.
+func RequireScope(scope string) func(gofr.Handler) gofr.Handler {
+	return func(h gofr.Handler) gofr.Handler {
+		return func(ctx *gofr.Context) (interface{}, error) {
+			if p, ok := auth.FromContext(ctx); ok && !p.HasScope(scope) {
+				return nil, service.ErrMissingScope(scope)
+			}
+
+			return h(ctx)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS url_metadata (
+    code TEXT PRIMARY KEY,
+    title TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    og_title TEXT NOT NULL DEFAULT '',
+    og_description TEXT NOT NULL DEFAULT '',
+    og_image TEXT NOT NULL DEFAULT '',
+    og_site_name TEXT NOT NULL DEFAULT '',
+    og_type TEXT NOT NULL DEFAULT '',
+    twitter_card TEXT NOT NULL DEFAULT '',
+    twitter_title TEXT NOT NULL DEFAULT '',
+    twitter_description TEXT NOT NULL DEFAULT '',
+    twitter_image TEXT NOT NULL DEFAULT '',
+    favicon_url TEXT NOT NULL DEFAULT '',
+    fetched_at TIMESTAMP NOT NULL,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_url_metadata_fetched_at ON url_metadata (fetched_at);
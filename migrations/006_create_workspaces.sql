@@ -0,0 +1,49 @@
+CREATE TABLE IF NOT EXISTS workspaces (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('owner', 'admin', 'editor', 'viewer')),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user_id ON workspace_members (user_id);
+
+-- Only a SHA-256 of the invitation token is stored.
+CREATE TABLE IF NOT EXISTS workspace_invitations (
+    id SERIAL PRIMARY KEY,
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('owner', 'admin', 'editor', 'viewer')),
+    token_hash TEXT UNIQUE NOT NULL,
+    invited_by INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_invitations_workspace_id ON workspace_invitations (workspace_id);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS default_workspace_id INTEGER REFERENCES workspaces(id) ON DELETE SET NULL;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS workspace_id INTEGER REFERENCES workspaces(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_urls_workspace_id ON urls (workspace_id);
+
+-- Give existing users a personal workspace holding the links they own.
+DO $$
+DECLARE
+    u RECORD;
+    ws INTEGER;
+BEGIN
+    FOR u IN SELECT id, email FROM users WHERE default_workspace_id IS NULL LOOP
+        INSERT INTO workspaces (name) VALUES (u.email) RETURNING id INTO ws;
+        INSERT INTO workspace_members (workspace_id, user_id, role) VALUES (ws, u.id, 'owner');
+        UPDATE users SET default_workspace_id = ws WHERE id = u.id;
+        UPDATE urls SET workspace_id = ws WHERE owner_id = u.id AND workspace_id IS NULL;
+    END LOOP;
+END $$;
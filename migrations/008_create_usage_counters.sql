@@ -0,0 +1,23 @@
+ALTER TABLE workspaces ADD COLUMN IF NOT EXISTS plan TEXT;
+
+-- One row per workspace, metric and period. Monthly metrics use the first day of the month as
+-- period, running totals such as active links use 1970-01-01.
+CREATE TABLE IF NOT EXISTS usage_counters (
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    metric TEXT NOT NULL,
+    period DATE NOT NULL,
+    count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (workspace_id, metric, period)
+);
+
+-- Start the counters from the links that already exist.
+INSERT INTO usage_counters (workspace_id, metric, period, count)
+SELECT workspace_id, 'active_links', DATE '1970-01-01', COUNT(*)
+FROM urls WHERE workspace_id IS NOT NULL GROUP BY workspace_id
+ON CONFLICT (workspace_id, metric, period) DO UPDATE SET count = EXCLUDED.count;
+
+INSERT INTO usage_counters (workspace_id, metric, period, count)
+SELECT workspace_id, 'links_created', date_trunc('month', NOW())::date, COUNT(*)
+FROM urls WHERE workspace_id IS NOT NULL AND created_at >= date_trunc('month', NOW())
+GROUP BY workspace_id
+ON CONFLICT (workspace_id, metric, period) DO UPDATE SET count = EXCLUDED.count;
@@ -0,0 +1,2 @@
+-- bcrypt hash of the link password, NULL for unprotected links.
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS password_hash TEXT;
@@ -0,0 +1,4 @@
+-- remaining_clicks is decremented on every redirect while it's above zero; both stay NULL for
+-- links without a click limit.
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS max_clicks BIGINT CHECK (max_clicks > 0);
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS remaining_clicks BIGINT CHECK (remaining_clicks >= 0);
@@ -0,0 +1,13 @@
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS activate_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS scheduled_changes (
+    id SERIAL PRIMARY KEY,
+    code TEXT NOT NULL,
+    long_url TEXT NOT NULL,
+    change_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    applied_at TIMESTAMP
+);
+
+-- Redirects look for due changes of their link on every request.
+CREATE INDEX IF NOT EXISTS idx_scheduled_changes_pending ON scheduled_changes (code, change_at) WHERE applied_at IS NULL;
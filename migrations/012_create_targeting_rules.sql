@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS targeting_rules (
+    id SERIAL PRIMARY KEY,
+    code TEXT NOT NULL,
+    priority INTEGER NOT NULL,
+    countries TEXT[] NOT NULL DEFAULT '{}',
+    languages TEXT[] NOT NULL DEFAULT '{}',
+    long_url TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_targeting_rules_code ON targeting_rules (code, priority);
+
+-- Which rule chose the destination of a visit, NULL for the link's default.
+ALTER TABLE visits ADD COLUMN IF NOT EXISTS rule_id INTEGER;
@@ -0,0 +1,5 @@
+-- Per-platform destinations of mobile deep links; the long URL stays the web fallback.
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS ios_app_url TEXT;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS ios_store_url TEXT;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS android_app_url TEXT;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS android_store_url TEXT;
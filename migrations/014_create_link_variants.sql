@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS link_variants (
+    id SERIAL PRIMARY KEY,
+    code TEXT NOT NULL,
+    name TEXT NOT NULL,
+    long_url TEXT NOT NULL,
+    weight INTEGER NOT NULL CHECK (weight >= 0),
+    archived_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_link_variants_code ON link_variants (code) WHERE archived_at IS NULL;
+
+-- Which variant a visit was sent to, and a pseudonymous key telling visitors apart.
+ALTER TABLE visits ADD COLUMN IF NOT EXISTS variant_id INTEGER;
+ALTER TABLE visits ADD COLUMN IF NOT EXISTS visitor_key TEXT;
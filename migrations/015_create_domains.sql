@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS domains (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    root_url TEXT,
+    not_found_url TEXT,
+    token TEXT NOT NULL,
+    verified_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_domains_workspace ON domains (workspace_id);
+
+-- Links on a branded domain are stored with the code "<code>@<domain>", which keeps codes
+-- unique per domain while other tables go on referring to links by code alone.
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS domain TEXT;
@@ -0,0 +1,13 @@
+-- Views of the preview page (/{code}+), kept apart from the clicks in visits.
+CREATE TABLE IF NOT EXISTS preview_views (
+    id SERIAL PRIMARY KEY,
+    code TEXT NOT NULL,
+    timestamp TIMESTAMP NOT NULL,
+    ip TEXT,
+    country TEXT,
+    browser TEXT,
+    device TEXT,
+    visitor_key TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_preview_views_code ON preview_views (code);
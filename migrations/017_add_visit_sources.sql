@@ -0,0 +1,2 @@
+-- Channel a click came from, taken from the short link's src parameter ("qr" for QR code scans).
+ALTER TABLE visits ADD COLUMN IF NOT EXISTS source TEXT;
@@ -0,0 +1,16 @@
+-- Last destination check of each link, refreshed by the health check job.
+CREATE TABLE IF NOT EXISTS link_health (
+    code TEXT PRIMARY KEY,
+    status TEXT NOT NULL,
+    method TEXT NOT NULL DEFAULT '',
+    status_code INT NOT NULL DEFAULT 0,
+    latency_ms BIGINT NOT NULL DEFAULT 0,
+    tls_expires_at TIMESTAMP,
+    redirect_chain TEXT NOT NULL DEFAULT '[]', -- JSON array of {url, status_code}
+    error TEXT NOT NULL DEFAULT '',
+    consecutive_failures INT NOT NULL DEFAULT 0,
+    last_ok_at TIMESTAMP,
+    checked_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_link_health_checked_at ON link_health (checked_at);
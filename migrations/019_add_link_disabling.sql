@@ -0,0 +1,3 @@
+-- Links whose destination was flagged by screening after they were created stop redirecting.
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMP;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS disabled_reason TEXT;
@@ -0,0 +1,6 @@
+-- SHA-256 of the normalized destination, indexed so shortening the same URL again can find the
+-- existing link; long_url itself can be too long for a btree index entry.
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS long_url_hash TEXT;
+UPDATE urls SET long_url_hash = encode(sha256(convert_to(long_url, 'UTF8')), 'hex') WHERE long_url_hash IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_urls_long_url_hash ON urls (long_url_hash, owner_id);
@@ -0,0 +1,4 @@
+-- How codes of new links are generated (see codegen.Parse); a domain's style overrides its
+-- workspace's, and NULL falls back to the configured default.
+ALTER TABLE workspaces ADD COLUMN IF NOT EXISTS code_style TEXT;
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS code_style TEXT;
@@ -0,0 +1,6 @@
+-- Counters that replicas lease blocks of IDs from, e.g. for sequential link codes. next_id is
+-- the first ID no lease has covered yet.
+CREATE TABLE IF NOT EXISTS id_blocks (
+    name TEXT PRIMARY KEY,
+    next_id BIGINT NOT NULL CHECK (next_id >= 0)
+);
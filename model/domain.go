@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// DomainVerificationPath is where a domain must serve its verification token; ShortEdge serves
+// it itself once the domain points at it.
+const DomainVerificationPath = "/.well-known/shortedge-verification"
+
+// Domain is a branded domain links can be served from, with its own namespace of codes.
+type Domain struct {
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"` // host name such as "go.acme.com"
+	WorkspaceID int64      `json:"workspace_id"`
+	RootURL     string     `json:"root_url,omitempty"`      // where the bare domain redirects to
+	NotFoundURL string     `json:"not_found_url,omitempty"` // where unknown codes redirect to
+	CodeStyle   string     `json:"code_style,omitempty"`    // how codes of new links are generated, overriding the workspace's
+	Token       string     `json:"verification_token,omitempty"`
+	VerifiedAt  *time.Time `json:"verified_at"` // links can only use verified domains
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type DomainRequest struct {
+	Name        string `json:"name"` // only read on creation
+	WorkspaceID *int64 `json:"workspace_id"`
+	RootURL     string `json:"root_url"`
+	NotFoundURL string `json:"not_found_url"`
+	CodeStyle   string `json:"code_style"` // e.g. "random:8", "nolookalike:7", "words:3" or "adaptive:6-10"; empty uses the workspace's
+}
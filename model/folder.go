@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+type Folder struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	ParentID  *int64    `json:"parent_id"` // nil for top-level folders
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type FolderRequest struct {
+	Name     string `json:"name"`
+	ParentID *int64 `json:"parent_id"` // Optional
+}
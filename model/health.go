@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+// Health states of a link's destination.
+const (
+	HealthOK      = "ok"      // the last check succeeded
+	HealthFailing = "failing" // recent checks failed, but fewer than the broken threshold
+	HealthBroken  = "broken"  // the threshold of consecutive checks failed
+)
+
+// HealthCheck is the outcome of one check of a destination.
+type HealthCheck struct {
+	Method        string        `json:"method"`                // HEAD, or GET when the destination doesn't support HEAD
+	StatusCode    int           `json:"status_code,omitempty"` // of the final response, 0 when there was none
+	LatencyMS     int64         `json:"latency_ms"`
+	TLSExpiresAt  *time.Time    `json:"tls_expires_at,omitempty"` // certificate of the final destination
+	RedirectChain []RedirectHop `json:"redirect_chain,omitempty"`
+	Error         string        `json:"error,omitempty"`
+	CheckedAt     time.Time     `json:"checked_at"`
+}
+
+// OK reports whether the destination answered with a non-error status.
+func (c HealthCheck) OK() bool {
+	return c.Error == "" && c.StatusCode > 0 && c.StatusCode < 400
+}
+
+// RedirectHop is a redirect on the way to the destination.
+type RedirectHop struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+}
+
+// LinkHealth is the last check of a link's destination and how it has been doing.
+type LinkHealth struct {
+	HealthCheck
+	Status              string     `json:"status"` // one of the Health* states
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastOKAt            *time.Time `json:"last_ok_at,omitempty"`
+}
+
+// HealthReport sums up the destination health of the links in a listing.
+type HealthReport struct {
+	Checked   int                `json:"checked"`
+	Unchecked int                `json:"unchecked"`
+	OK        int                `json:"ok"`
+	Failing   int                `json:"failing"`
+	Broken    int                `json:"broken"`
+	Links     []LinkHealthReport `json:"links"` // links that aren't OK or whose certificate expires soon
+}
+
+// LinkHealthReport is a link in a HealthReport.
+type LinkHealthReport struct {
+	Code    string     `json:"code"`
+	LongURL string     `json:"long_url"`
+	Health  LinkHealth `json:"health"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// LinkMetadata is what ShortEdge learned about a link's destination page.
+type LinkMetadata struct {
+	Title              string    `json:"title,omitempty"`
+	Description        string    `json:"description,omitempty"`
+	OGTitle            string    `json:"og_title,omitempty"`
+	OGDescription      string    `json:"og_description,omitempty"`
+	OGImage            string    `json:"og_image,omitempty"`
+	OGSiteName         string    `json:"og_site_name,omitempty"`
+	OGType             string    `json:"og_type,omitempty"`
+	TwitterCard        string    `json:"twitter_card,omitempty"`
+	TwitterTitle       string    `json:"twitter_title,omitempty"`
+	TwitterDescription string    `json:"twitter_description,omitempty"`
+	TwitterImage       string    `json:"twitter_image,omitempty"`
+	FaviconURL         string    `json:"favicon_url,omitempty"`
+	FetchedAt          time.Time `json:"fetched_at"`
+	Error              string    `json:"error,omitempty"` // last fetch failure, empty on success
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// Safety statuses of a link's destination.
+const (
+	SafetyOK      = "ok"      // no known issues
+	SafetyCaution = "caution" // see the warnings before following the link
+)
+
+// LinkPreview is what the preview page shows about a link before following it.
+type LinkPreview struct {
+	Code              string    `json:"code"`               // code@domain on branded domains
+	LongURL           string    `json:"long_url,omitempty"` // withheld for password protected links
+	Title             string    `json:"title,omitempty"`    // title of the destination page, once fetched
+	Description       string    `json:"description,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	Workspace         string    `json:"workspace,omitempty"` // name of the owner's workspace
+	PasswordProtected bool      `json:"password_protected"`
+	Safety            Safety    `json:"safety"`
+}
+
+type Safety struct {
+	Status   string   `json:"status"` // one of the Safety* statuses
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// PreviewStats compares how often a link was previewed with how often it was followed.
+type PreviewStats struct {
+	Code           string `json:"code"`
+	Previews       int    `json:"previews"`
+	UniqueVisitors int    `json:"unique_visitors"` // distinct visitors among the previews
+	Clicks         int    `json:"clicks"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// ScheduledChange switches a link to a new destination at a given time.
+type ScheduledChange struct {
+	ID        int64      `json:"id"`
+	Code      string     `json:"code"`
+	LongURL   string     `json:"long_url"`
+	At        time.Time  `json:"at"`
+	CreatedAt time.Time  `json:"created_at"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+type ScheduledChangeRequest struct {
+	LongURL string    `json:"long_url"`
+	At      time.Time `json:"at"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+type Tag struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TagRequest struct {
+	Name string `json:"name"`
+}
+
+type MergeTagsRequest struct {
+	SourceIDs []int64 `json:"source_ids"` // tags folded into the target and then removed
+}
+
+type LinkTagsRequest struct {
+	Tags []string `json:"tags"`
+}
@@ -0,0 +1,30 @@
+package model
+
+// TargetingRule sends visitors matching its countries and languages to LongURL instead of the
+// link's own destination. Empty lists match everyone.
+type TargetingRule struct {
+	ID        int64    `json:"id"`
+	Code      string   `json:"code"`
+	Priority  int      `json:"priority"`            // rules are tried in ascending priority
+	Countries []string `json:"countries,omitempty"` // ISO 3166-1 alpha-2 codes such as "DE"
+	Languages []string `json:"languages,omitempty"` // BCP 47 tags; "de" also matches "de-AT"
+	LongURL   string   `json:"long_url"`
+}
+
+type TargetingRuleRequest struct {
+	Countries []string `json:"countries"`
+	Languages []string `json:"languages"`
+	LongURL   string   `json:"long_url"`
+}
+
+// TargetingRulesRequest replaces all rules of a link, in priority order.
+type TargetingRulesRequest struct {
+	Rules []TargetingRuleRequest `json:"rules"`
+}
+
+// RuleClicks counts the visits a rule sent on; a nil RuleID stands for the link's default
+// destination.
+type RuleClicks struct {
+	RuleID *int64 `json:"rule_id"`
+	Clicks int    `json:"clicks"`
+}
@@ -2,17 +2,84 @@ package model
 
 import "time"
 
+// Visibility modes of a link.
+const (
+	VisibilityPublic   = "public"   // listed and redirects for everyone
+	VisibilityUnlisted = "unlisted" // redirects for everyone, only listed to its owner
+	VisibilityPrivate  = "private"  // redirects only for the owner's account
+	VisibilityInternal = "internal" // redirects only from the configured internal networks
+)
+
 type URL struct {
-	Code       string     `json:"code"`
-	LongURL    string     `json:"long_url"`
-	CreatedAt  time.Time  `json:"created_at"`
-	Visibility string     `json:"visibility"` // "public" or "private"
-	ExpiresAt  *time.Time `json:"expires_at"` // 
+	Code        string        `json:"code"`             // "<code>@<domain>" for links on a branded domain
+	Domain      string        `json:"domain,omitempty"` // branded domain the link is served from
+	LongURL     string        `json:"long_url"`
+	CreatedAt   time.Time     `json:"created_at"`
+	Visibility  string        `json:"visibility"` // one of the Visibility* modes
+	ExpiresAt   *time.Time    `json:"expires_at"` //
+	FolderID    *int64        `json:"folder_id"`
+	Tags        []string      `json:"tags,omitempty"`
+	Metadata    *LinkMetadata `json:"metadata,omitempty"`     // destination page details, fetched in the background
+	Health      *LinkHealth   `json:"health,omitempty"`       // last destination check, nil until the first one
+	OwnerID     *int64        `json:"owner_id,omitempty"`     // nil for links created anonymously
+	CreatedBy   string        `json:"created_by,omitempty"`   // browser token of the frontend that created the link
+	WorkspaceID *int64        `json:"workspace_id,omitempty"` // nil for links created anonymously
+
+	PasswordHash      string `json:"-"`
+	PasswordProtected bool   `json:"password_protected"`
+
+	MaxClicks       *int64 `json:"max_clicks,omitempty"`       // nil means unlimited
+	RemainingClicks *int64 `json:"remaining_clicks,omitempty"` // set along with MaxClicks
+
+	ActivateAt *time.Time `json:"activate_at,omitempty"` // the link doesn't redirect before this time
+
+	DisabledAt     *time.Time `json:"disabled_at,omitempty"`     // set when screening flagged the destination later
+	DisabledReason string     `json:"disabled_reason,omitempty"` // why screening flagged it
+
+	IOS     *AppLink `json:"ios,omitempty"`     // destination for iPhone and iPad visitors
+	Android *AppLink `json:"android,omitempty"` // destination for Android visitors
+
+	RuleID         *int64 `json:"-"` // targeting rule that chose LongURL, set when resolving a redirect
+	VariantID      *int64 `json:"-"` // A/B variant that chose LongURL, set when resolving a redirect
+	AppFallbackURL string `json:"-"` // store listing to open if the app LongURL points to isn't installed
+
+	Existing bool `json:"existing,omitempty"` // Shorten returned an earlier link to the same destination
+}
+
+// AppLink sends visitors on one mobile platform into an app, or to its store listing. Visitors
+// on other platforms get the link's long URL.
+type AppLink struct {
+	AppURL   string `json:"app_url,omitempty"`   // app scheme URL, or a universal/app link
+	StoreURL string `json:"store_url,omitempty"` // App Store or Google Play listing
 }
 
 type ShortenRequest struct {
-	LongURL    string     `json:"long_url"`
-	CustomCode string     `json:"custom_code"` // Optional
-	Visibility string     `json:"visibility"`  // public / private
-	ExpiresAt  *time.Time `json:"expires_at"`  // Optional 
+	LongURL     string     `json:"long_url"`
+	CustomCode  string     `json:"custom_code"`  // Optional; on update it must be the link's code
+	Visibility  string     `json:"visibility"`   // public / unlisted / private / internal
+	ExpiresAt   *time.Time `json:"expires_at"`   // Optional
+	FolderID    *int64     `json:"folder_id"`    // Optional; on update nil keeps the folder and 0 removes it
+	Tags        []string   `json:"tags"`         // Optional
+	CreatedBy   string     `json:"created_by"`   // Optional, set by the frontend
+	WorkspaceID *int64     `json:"workspace_id"` // Optional, defaults to the caller's workspace
+	Password    *string    `json:"password"`     // Optional; on update nil keeps it and "" removes it
+	MaxClicks   *int64     `json:"max_clicks"`   // Optional, 1 for single-use links; on update nil keeps it and 0 removes it
+	ActivateAt  *time.Time `json:"activate_at"`  // Optional go-live time; on update nil keeps it
+	IOS         *AppLink   `json:"ios"`          // Optional; on update nil keeps it and an empty one removes it
+	Android     *AppLink   `json:"android"`      // Optional; on update nil keeps it and an empty one removes it
+	Domain      string     `json:"domain"`       // Optional verified branded domain of the link's workspace; only read on creation
+	ForceNew    bool       `json:"force_new"`    // Optional; creates a new link even if an identical one exists
+}
+
+// UnlockRequest carries the password of a protected link, as JSON or from the HTML prompt.
+type UnlockRequest struct {
+	Password string `json:"password" form:"password"`
+}
+
+// URLFilter narrows down listings and aggregated analytics.
+type URLFilter struct {
+	Tag         string // tag name, empty means any
+	FolderID    *int64 // folder including its sub-folders, nil means any
+	Query       string // case-insensitive search over code, destination, tags and page metadata
+	WorkspaceID *int64 // workspace to list, nil means the caller's default workspace
 }
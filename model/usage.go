@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// Usage metrics metered per workspace.
+const (
+	MetricLinksCreated = "links_created" // links created this month
+	MetricActiveLinks  = "active_links"  // links that currently exist
+	MetricCustomCodes  = "custom_codes"  // links created with a custom code this month
+	MetricClicks       = "clicks"        // clicks tracked this month
+)
+
+// Plan holds the quotas of a workspace; a zero limit means unlimited.
+type Plan struct {
+	Name                string `json:"name"`
+	LinksPerMonth       int64  `json:"links_per_month"`
+	ActiveLinks         int64  `json:"active_links"`
+	CustomCodesPerMonth int64  `json:"custom_codes_per_month"`
+	ClicksPerMonth      int64  `json:"clicks_per_month"`
+}
+
+type UsageMetric struct {
+	Name     string     `json:"name"`
+	Used     int64      `json:"used"`
+	Limit    int64      `json:"limit"` // 0 means unlimited
+	ResetsAt *time.Time `json:"resets_at,omitempty"`
+}
+
+type Usage struct {
+	WorkspaceID int64         `json:"workspace_id"`
+	Plan        string        `json:"plan"`
+	PeriodStart time.Time     `json:"period_start"`
+	ResetsAt    time.Time     `json:"resets_at"`
+	Metrics     []UsageMetric `json:"metrics"`
+}
+
+type PlanRequest struct {
+	Plan string `json:"plan"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+type User struct {
+	ID           int64     `json:"id"`
+	Email        string    `json:"email"`
+	Role         string    `json:"role"` // "user" or "admin"
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	// DefaultWorkspaceID is the personal workspace created on registration, used when a
+	// request doesn't name a workspace.
+	DefaultWorkspaceID *int64 `json:"default_workspace_id"`
+}
+
+type RegisterRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Session is returned on login; Token goes into the Authorization: Bearer header.
+type Session struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	User      User      `json:"user"`
+}
@@ -0,0 +1,34 @@
+package model
+
+// Variant is one of several destinations a link splits its traffic across, in proportion to
+// its weight. Removed variants are archived rather than deleted so their visits keep a name.
+type Variant struct {
+	ID      int64  `json:"id"`
+	Code    string `json:"code"`
+	Name    string `json:"name"`
+	LongURL string `json:"long_url"`
+	Weight  int    `json:"weight"` // share of traffic relative to the other variants; 0 pauses it
+}
+
+// VariantRequest changes the variant with ID, or adds a new one when ID is nil.
+type VariantRequest struct {
+	ID      *int64 `json:"id"`
+	Name    string `json:"name"`
+	LongURL string `json:"long_url"`
+	Weight  int    `json:"weight"`
+}
+
+// VariantsRequest replaces all variants of a link. Variants keep their visits when listed with
+// their ID; ones left out are archived.
+type VariantsRequest struct {
+	Variants []VariantRequest `json:"variants"`
+}
+
+// VariantClicks counts the visits a variant received; a nil VariantID stands for visits that
+// weren't split, such as those before the test started.
+type VariantClicks struct {
+	VariantID      *int64 `json:"variant_id"`
+	Name           string `json:"name,omitempty"`
+	Clicks         int    `json:"clicks"`
+	UniqueVisitors int    `json:"unique_visitors"`
+}
@@ -9,4 +9,17 @@ type Visit struct {
 	Country   string    `json:"country"`
 	Browser   string    `json:"browser"`
 	Device    string    `json:"device"`
+	RuleID    *int64    `json:"rule_id,omitempty"`    // targeting rule that chose the destination
+	VariantID *int64    `json:"variant_id,omitempty"` // A/B variant the visitor was sent to
+	Source    string    `json:"source,omitempty"`     // channel from the link's src parameter, e.g. "qr" for scans
+
+	VisitorKey string `json:"-"` // pseudonymous visitor, for counting unique visitors
+
+	WorkspaceID *int64 `json:"-"` // workspace of the link, for click quotas; not stored
+}
+
+// SourceClicks counts a link's clicks from one channel; Source is empty for plain clicks.
+type SourceClicks struct {
+	Source string `json:"source"`
+	Clicks int    `json:"clicks"`
 }
@@ -0,0 +1,62 @@
+package model
+
+import "time"
+
+// Membership roles within a workspace, from most to least privileged.
+const (
+	WorkspaceOwner  = "owner"  // everything, including managing owners
+	WorkspaceAdmin  = "admin"  // manage members and invitations
+	WorkspaceEditor = "editor" // create, change and delete links
+	WorkspaceViewer = "viewer" // read links and analytics
+)
+
+type Workspace struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role,omitempty"`       // the caller's role, set in listings
+	Plan      string    `json:"plan,omitempty"`       // empty means the default plan
+	CodeStyle string    `json:"code_style,omitempty"` // how codes of new links are generated, empty means the default
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WorkspaceRequest struct {
+	Name      string  `json:"name"`
+	CodeStyle *string `json:"code_style"` // Optional; nil keeps it and "" resets it to the default
+}
+
+type Member struct {
+	WorkspaceID int64     `json:"workspace_id"`
+	UserID      int64     `json:"user_id"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type MemberRoleRequest struct {
+	Role string `json:"role"`
+}
+
+type Invitation struct {
+	ID          int64      `json:"id"`
+	WorkspaceID int64      `json:"workspace_id"`
+	Email       string     `json:"email"`
+	Role        string     `json:"role"`
+	InvitedBy   int64      `json:"invited_by"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	AcceptedAt  *time.Time `json:"accepted_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Token       string     `json:"token,omitempty"` // only returned when the invitation is created
+}
+
+type InvitationRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+type MoveLinkRequest struct {
+	WorkspaceID int64 `json:"workspace_id"`
+}
@@ -0,0 +1,146 @@
+// Package oidc loads the signing keys of an OpenID Connect provider for gofr's JWT validation.
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const maxJWKSBytes = 1 << 20
+
+var ErrNoKeys = errors.New("jwks contains no usable RSA keys")
+
+// KeySet holds the RSA keys of a JWKS endpoint. It implements gofr's
+// middleware.PublicKeyProvider. Unlike the provider behind App.EnableOAuth, which only loads keys
+// after its first refresh interval, it is usable as soon as NewKeySet returns.
+type KeySet struct {
+	url    string
+	client *http.Client
+
+	mu   sync.RWMutex
+	keys map[string]*rsa.PublicKey
+}
+
+// NewKeySet fetches the keys at url once; a failure is returned but leaves a usable, empty set
+// that a later Refresh can fill.
+func NewKeySet(ctx context.Context, url string, client *http.Client) (*KeySet, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	k := &KeySet{url: url, client: client}
+	return k, k.Refresh(ctx)
+}
+
+// Get returns the key with the given key ID, nil if unknown.
+func (k *KeySet) Get(kid string) *rsa.PublicKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.keys[kid]
+}
+
+// Refresh reloads the keys. The previous keys stay in use if it fails.
+func (k *KeySet) Refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching jwks: unexpected status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSBytes))
+	if err != nil {
+		return err
+	}
+
+	keys, err := ParseJWKS(body)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.mu.Unlock()
+
+	return nil
+}
+
+// Run refreshes the keys every interval until ctx is done, so rotated provider keys are picked up.
+func (k *KeySet) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := k.Refresh(ctx); err != nil {
+				log.Printf("refreshing jwks from %s failed: %v", k.url, err)
+			}
+		}
+	}
+}
+
+type jwks struct {
+	Keys []struct {
+		ID      string `json:"kid"`
+		Type    string `json:"kty"`
+		Use     string `json:"use"`
+		Modulus string `json:"n"`
+		Exp     string `json:"e"`
+	} `json:"keys"`
+}
+
+// ParseJWKS extracts the RSA signing keys of a JSON Web Key Set by key ID.
+func ParseJWKS(data []byte) (map[string]*rsa.PublicKey, error) {
+	var set jwks
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Type != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(jwk.Modulus)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.Exp)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			continue
+		}
+
+		keys[jwk.ID] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+	return keys, nil
+}
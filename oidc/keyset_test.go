@@ -0,0 +1,107 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/oidc"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gofrMiddleware "gofr.dev/pkg/gofr/http/middleware"
+)
+
+func jwk(kid string, key *rsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kid": kid,
+		"kty": "RSA",
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// jwksServer is a local stand-in for the provider's JWKS endpoint serving whatever keys holds.
+func jwksServer(t *testing.T, keys *atomic.Value) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": keys.Load()})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func sign(t *testing.T, kid string, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestKeySetWithGofrOAuth(t *testing.T) {
+	first, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	second, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var served atomic.Value
+	served.Store([]map[string]string{jwk("k1", first)})
+	srv := jwksServer(t, &served)
+
+	keys, err := oidc.NewKeySet(context.Background(), srv.URL, srv.Client())
+	require.NoError(t, err)
+
+	var gotSubject string
+	handler := gofrMiddleware.OAuth(keys, jwt.WithIssuer("https://idp.test"))(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			claims := r.Context().Value(gofrMiddleware.JWTClaim).(jwt.MapClaims)
+			gotSubject, _ = claims["sub"].(string)
+		}))
+
+	call := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/all", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	now := time.Now()
+	valid := jwt.MapClaims{"iss": "https://idp.test", "sub": "user-1", "iat": now.Unix(), "exp": now.Add(time.Hour).Unix()}
+
+	assert.Equal(t, http.StatusOK, call(sign(t, "k1", first, valid)))
+	assert.Equal(t, "user-1", gotSubject)
+
+	wrongIssuer := jwt.MapClaims{"iss": "https://evil.test", "sub": "user-1", "iat": now.Unix(), "exp": now.Add(time.Hour).Unix()}
+	assert.Equal(t, http.StatusUnauthorized, call(sign(t, "k1", first, wrongIssuer)))
+
+	expired := jwt.MapClaims{"iss": "https://idp.test", "sub": "user-1", "iat": now.Add(-2 * time.Hour).Unix(), "exp": now.Add(-time.Hour).Unix()}
+	assert.Equal(t, http.StatusUnauthorized, call(sign(t, "k1", first, expired)))
+
+	// A token signed with the right kid but another key fails.
+	assert.Equal(t, http.StatusUnauthorized, call(sign(t, "k1", second, valid)))
+
+	// Keys rotated at the provider are picked up on refresh.
+	assert.Equal(t, http.StatusUnauthorized, call(sign(t, "k2", second, valid)))
+	served.Store([]map[string]string{jwk("k2", second)})
+	require.NoError(t, keys.Refresh(context.Background()))
+	assert.Equal(t, http.StatusOK, call(sign(t, "k2", second, valid)))
+	assert.Nil(t, keys.Get("k1"))
+}
+
+func TestParseJWKS(t *testing.T) {
+	_, err := oidc.ParseJWKS([]byte(`{"keys":[{"kid":"x","kty":"EC"}]}`))
+	assert.ErrorIs(t, err, oidc.ErrNoKeys)
+
+	_, err = oidc.ParseJWKS([]byte(`not json`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,237 @@
+package qr
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// "HELLO WORLD" as 1-M in alphanumeric mode, the worked example from the standard's tutorials
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	ecc := rsRemainder(data, rsDivisor(10))
+	assert.Equal(t, []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}, ecc)
+}
+
+func TestTables(t *testing.T) {
+	assert.Equal(t, 208, rawModules(1))
+	assert.Equal(t, 29648, rawModules(40))
+	assert.Equal(t, 16, dataCodewords(1, M))
+	assert.Equal(t, 2956, dataCodewords(40, L))
+	assert.Equal(t, 1276, dataCodewords(40, H))
+
+	assert.Nil(t, alignmentPositions(1))
+	assert.Equal(t, []int{6, 22, 38}, alignmentPositions(7))
+	assert.Equal(t, []int{6, 34, 60, 86, 112, 138}, alignmentPositions(32))
+	assert.Equal(t, []int{6, 24, 50, 76, 102, 128, 154}, alignmentPositions(36))
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	c := newCode(7, M)
+	c.drawFunctionPatterns()
+	c.drawFormatBits(0)
+
+	// M with mask 0 is 101010000010010, most significant bit at column 0 of row 8
+	var format string
+	for x := 0; x <= 8; x++ {
+		if x != 6 {
+			format += bit(c.modules[8][x])
+		}
+	}
+	for y := 7; y >= 0; y-- {
+		if y != 6 {
+			format += bit(c.modules[y][8])
+		}
+	}
+	assert.Equal(t, "101010000010010", format)
+
+	// Version 7 is 000111110010010100, least significant bit in the top left of the block
+	var version string
+	for i := 17; i >= 0; i-- {
+		version += bit(c.modules[i/3][c.Size-11+i%3])
+	}
+	assert.Equal(t, "000111110010010100", version)
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		data    string
+		level   Level
+		version int
+	}{
+		{"https://sho.rt/abc?src=qr", M, 2},
+		{"https://sho.rt/abc?src=qr", H, 4}, // 3-H holds 24 bytes
+		{strings.Repeat("x", 17), L, 1},
+		{strings.Repeat("x", 18), L, 2},
+		{strings.Repeat("x", 600), Q, 23},
+		{strings.Repeat("x", 2953), L, 40},
+	}
+
+	for _, tt := range tests {
+		c, err := Encode([]byte(tt.data), tt.level)
+		require.NoError(t, err)
+		assert.Equal(t, tt.version, c.Version, "%d bytes at %s", len(tt.data), tt.level)
+		assert.Equal(t, 17+4*tt.version, c.Size)
+		assert.Equal(t, tt.data, decode(t, c), "%d bytes at %s", len(tt.data), tt.level)
+	}
+
+	_, err := Encode(bytes.Repeat([]byte("x"), 2954), L)
+	assert.ErrorIs(t, err, ErrTooLong)
+}
+
+func TestRender(t *testing.T) {
+	c, err := Encode([]byte("https://sho.rt/abc"), M)
+	require.NoError(t, err)
+
+	style := DefaultStyle
+	style.Foreground, err = ParseColor("#1a2B3c")
+	require.NoError(t, err)
+
+	out, err := c.PNG(style)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(out))
+	require.NoError(t, err)
+	scale := 256 / (c.Size + 8)
+	assert.Equal(t, (c.Size+8)*scale, img.Bounds().Dx())
+	r, g, b, _ := img.At(4*scale, 4*scale).RGBA() // top left of the finder pattern
+	assert.Equal(t, []uint32{0x1a, 0x2b, 0x3c}, []uint32{r >> 8, g >> 8, b >> 8})
+	r, _, _, _ = img.At(0, 0).RGBA()
+	assert.Equal(t, uint32(0xff), r>>8)
+
+	svg := string(c.SVG(style))
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg"`))
+	assert.Contains(t, svg, `viewBox="0 0 33 33"`)
+	assert.Contains(t, svg, `fill="#1a2b3c"`)
+	assert.Contains(t, svg, "M4,4h7v1h-7z")
+
+	_, err = ParseColor("blue")
+	assert.Error(t, err)
+	white, err := ParseColor("fff")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultStyle.Background, white)
+}
+
+func bit(dark bool) string {
+	if dark {
+		return "1"
+	}
+	return "0"
+}
+
+// decode reads a code back the way a scanner would, checking the error correction of every block.
+func decode(t *testing.T, c *Code) string {
+	t.Helper()
+
+	// The level and mask from the format bits, by comparing with each valid pattern
+	level, mask := Level(-1), -1
+	for l := L; l <= H; l++ {
+		for m := 0; m < 8; m++ {
+			read := newCode(c.Version, l)
+			read.drawFormatBits(m)
+			same := true
+			for i := 0; i <= 8; i++ {
+				if i != 6 {
+					same = same && read.modules[8][i] == c.modules[8][i] && read.modules[i][8] == c.modules[i][8]
+				}
+			}
+			if same {
+				level, mask = l, m
+			}
+		}
+	}
+	require.Equal(t, c.Level, level)
+	require.GreaterOrEqual(t, mask, 0)
+
+	read := newCode(c.Version, level)
+	read.drawFunctionPatterns()
+	read.drawFormatBits(mask)
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if read.function[y][x] {
+				require.Equal(t, read.modules[y][x], c.modules[y][x], "function module %d,%d", x, y)
+			}
+		}
+	}
+
+	// Codewords in zigzag order, unmasked
+	var bits []bool
+	for col := c.Size - 1; col > 0; col -= 2 {
+		if col == 6 {
+			col--
+		}
+		up := ((c.Size-1-col)/2)%2 == 0
+		if col < 6 {
+			up = ((c.Size-1-(col+1))/2)%2 == 0
+		}
+		for i := 0; i < c.Size; i++ {
+			y := i
+			if up {
+				y = c.Size - 1 - i
+			}
+			for _, x := range []int{col, col - 1} {
+				if !read.function[y][x] {
+					bits = append(bits, c.modules[y][x] != maskBit(mask, x, y))
+				}
+			}
+		}
+	}
+	codewords := make([]byte, len(bits)/8)
+	for i := range codewords {
+		for _, b := range bits[i*8 : i*8+8] {
+			codewords[i] <<= 1
+			if b {
+				codewords[i] |= 1
+			}
+		}
+	}
+
+	// Undo the interleaving: data codewords of all blocks first, long blocks having one more
+	numBlocks := eccBlocks[level][c.Version]
+	eccLen := eccPerBlock[level][c.Version]
+	total := len(codewords)
+	numLong := total % numBlocks
+	shortData := total/numBlocks - eccLen
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for i := 0; i < shortData+1; i++ {
+		for j := range blocks {
+			if i < shortData || j >= numBlocks-numLong {
+				blocks[j] = append(blocks[j], codewords[k])
+				k++
+			}
+		}
+	}
+	var data []byte
+	for i := 0; i < eccLen; i++ {
+		for j := range blocks {
+			blocks[j] = append(blocks[j], codewords[k])
+			k++
+		}
+	}
+	for _, block := range blocks {
+		n := len(block) - eccLen
+		require.Equal(t, block[n:], rsRemainder(block[:n], rsDivisor(eccLen)), "error correction")
+		data = append(data, block[:n]...)
+	}
+
+	require.Equal(t, byte(0b0100), data[0]>>4, "byte mode")
+	pos := 4
+	readBits := func(n int) int {
+		v := 0
+		for i := 0; i < n; i++ {
+			v = v<<1 | int(data[pos/8]>>(7-pos%8)&1)
+			pos++
+		}
+		return v
+	}
+	n := readBits(countBits(c.Version))
+	out := make([]byte, n)
+	for i := range out {
+		out[i] = byte(readBits(8))
+	}
+	return string(out)
+}
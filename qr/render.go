@@ -0,0 +1,108 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+)
+
+// Style controls how a code is rendered.
+type Style struct {
+	Size       int // requested width and height in pixels
+	Margin     int // quiet zone in modules; scanners want at least 4
+	Foreground color.RGBA
+	Background color.RGBA
+}
+
+// DefaultStyle is black on white with the standard quiet zone.
+var DefaultStyle = Style{
+	Size:       256,
+	Margin:     4,
+	Foreground: color.RGBA{A: 0xff},
+	Background: color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+}
+
+// ParseColor parses a color written as RGB or RRGGBB hex digits, with or without a leading '#'.
+func ParseColor(s string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+
+	var r, g, b uint8
+	if len(hex) != 6 {
+		return color.RGBA{}, fmt.Errorf("qr: invalid color %q, use hex digits like 1a2b3c", s)
+	}
+	if _, err := fmt.Sscanf(hex, "%02x%02x%02x", &r, &g, &b); err != nil {
+		return color.RGBA{}, fmt.Errorf("qr: invalid color %q, use hex digits like 1a2b3c", s)
+	}
+	return color.RGBA{R: r, G: g, B: b, A: 0xff}, nil
+}
+
+// scale is the pixels per module that fit the code and its margin in size, at least 1.
+func (c *Code) scale(s Style) int {
+	return max(1, s.Size/(c.Size+2*s.Margin))
+}
+
+// PNG renders the code as a PNG image. Modules are whole pixels, so the image is the largest
+// multiple of the code's width that fits in s.Size, or one pixel per module if none does.
+func (c *Code) PNG(s Style) ([]byte, error) {
+	scale := c.scale(s)
+	width := (c.Size + 2*s.Margin) * scale
+
+	img := image.NewPaletted(image.Rect(0, 0, width, width), color.Palette{s.Background, s.Foreground})
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.modules[y][x] {
+				continue
+			}
+			px, py := (x+s.Margin)*scale, (y+s.Margin)*scale
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex(px+dx, py+dy, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SVG renders the code as an SVG image of s.Size pixels, drawing the dark modules as one path.
+func (c *Code) SVG(s Style) []byte {
+	width := c.Size + 2*s.Margin
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" version="1.1" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		s.Size, s.Size, width, width)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="%s"/>`, hexColor(s.Background))
+	buf.WriteString(`<path d="`)
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.modules[y][x] {
+				continue
+			}
+			// Runs of dark modules become one rectangle each
+			run := 1
+			for x+run < c.Size && c.modules[y][x+run] {
+				run++
+			}
+			fmt.Fprintf(&buf, "M%d,%dh%dv1h-%dz", x+s.Margin, y+s.Margin, run, run)
+			x += run - 1
+		}
+	}
+	fmt.Fprintf(&buf, `" fill="%s"/></svg>`, hexColor(s.Foreground))
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
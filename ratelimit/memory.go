@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory keeps buckets in process memory; limits only hold per replica.
+type Memory struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   Limit
+}
+
+func NewMemory() *Memory {
+	return &Memory{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (m *Memory) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	return m.take(key, limit, 1), nil
+}
+
+func (m *Memory) Refund(_ context.Context, key string, limit Limit) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if b, ok := m.buckets[key]; ok && b.limit == limit {
+		b.tokens = min(float64(limit.Burst), b.tokens+1)
+	}
+	return nil
+}
+
+// take refills the bucket of key and takes cost tokens from it if at least one is left.
+func (m *Memory) take(key string, limit Limit, cost float64) Result {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	b, ok := m.buckets[key]
+	if !ok || b.limit != limit {
+		b = &bucket{tokens: float64(limit.Burst), updated: now, limit: limit}
+		m.buckets[key] = b
+	}
+
+	b.tokens = min(float64(limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*limit.rate())
+	b.updated = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens -= cost
+	}
+
+	return result(allowed, b.tokens, limit)
+}
+
+// Prune drops buckets that have refilled completely, as they behave like new ones.
+func (m *Memory) Prune() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	for key, b := range m.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*b.limit.rate() >= float64(b.limit.Burst) {
+			delete(m.buckets, key)
+		}
+	}
+}
+
+// Run prunes full buckets every interval until ctx is done, bounding memory use.
+func (m *Memory) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Prune()
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	l, err := ParseLimit("30/m")
+	require.NoError(t, err)
+	assert.Equal(t, Limit{Burst: 30, Period: time.Minute}, l)
+
+	l, err = ParseLimit(" 5 / 10s ")
+	require.NoError(t, err)
+	assert.Equal(t, Limit{Burst: 5, Period: 10 * time.Second}, l)
+
+	l, err = ParseLimit("")
+	require.NoError(t, err)
+	assert.False(t, l.Enabled())
+
+	for _, bad := range []string{"30", "0/m", "x/m", "5/fortnight", "5/-1s"} {
+		_, err := ParseLimit(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestMemoryTokenBucket(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+
+	limit := Limit{Burst: 3, Period: 3 * time.Second} // one token per second
+	ctx := context.Background()
+
+	for i := 2; i >= 0; i-- {
+		res, err := m.Allow(ctx, "ip:1", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, i, res.Remaining)
+	}
+
+	res, _ := m.Allow(ctx, "ip:1", limit)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Second, res.RetryAfter)
+	assert.Equal(t, 3*time.Second, res.Reset)
+
+	// Other clients have their own bucket.
+	res, _ = m.Allow(ctx, "ip:2", limit)
+	assert.True(t, res.Allowed)
+
+	now = now.Add(1500 * time.Millisecond)
+	res, _ = m.Allow(ctx, "ip:1", limit)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+
+	res, _ = m.Allow(ctx, "ip:1", limit)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+
+	// Refills never exceed the burst, and full buckets are pruned.
+	now = now.Add(time.Hour)
+	m.Prune()
+	assert.Empty(t, m.buckets)
+	res, _ = m.Allow(ctx, "ip:1", limit)
+	assert.Equal(t, 2, res.Remaining)
+
+	// Refunds give tokens back, but never beyond the burst.
+	require.NoError(t, m.Refund(ctx, "ip:1", limit))
+	res, _ = m.Allow(ctx, "ip:1", limit)
+	assert.Equal(t, 2, res.Remaining)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, m.Refund(ctx, "ip:1", limit))
+	}
+	res, _ = m.Allow(ctx, "ip:1", limit)
+	assert.Equal(t, 2, res.Remaining)
+}
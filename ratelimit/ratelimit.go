@@ -0,0 +1,95 @@
+// Package ratelimit implements token bucket rate limiting with in-memory and Redis backends.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit allows Burst requests at once, refilled at Burst per Period.
+type Limit struct {
+	Burst  int
+	Period time.Duration
+}
+
+// Enabled reports whether the limit restricts anything; the zero Limit doesn't.
+func (l Limit) Enabled() bool {
+	return l.Burst > 0 && l.Period > 0
+}
+
+// rate is the refill speed in tokens per second.
+func (l Limit) rate() float64 {
+	return float64(l.Burst) / l.Period.Seconds()
+}
+
+// ParseLimit parses "<count>/<period>" such as "30/m", "5/s" or "1000/1h". An empty string
+// yields the zero, disabled Limit.
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Limit{}, nil
+	}
+
+	count, period, ok := strings.Cut(s, "/")
+	n, err := strconv.Atoi(strings.TrimSpace(count))
+	if !ok || err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, want <count>/<period>", s)
+	}
+
+	period = strings.TrimSpace(period)
+	switch period {
+	case "s", "m", "h":
+		period = "1" + period
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit period in %q", s)
+	}
+
+	return Limit{Burst: n, Period: d}, nil
+}
+
+// Result is the outcome of taking a token.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next token, zero when allowed
+}
+
+// Backend stores token buckets.
+type Backend interface {
+	// Allow takes a token from the bucket of key, if one is left.
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+	// Refund gives back a token taken by Allow, e.g. to only count failed attempts at something
+	// while still taking the token before the attempt.
+	Refund(ctx context.Context, key string, limit Limit) error
+}
+
+// result derives the caller-facing numbers from the tokens left after a request.
+func result(allowed bool, tokens float64, limit Limit) Result {
+	rate := limit.rate()
+
+	res := Result{
+		Allowed:   allowed,
+		Limit:     limit.Burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(limit.Burst) - tokens) / rate),
+	}
+	if !allowed {
+		res.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return res
+}
+
+func seconds(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucket takes ARGV[3] tokens (-1 to refund one) atomically. It uses the Redis clock so replicas with skewed clocks
+// share buckets correctly. Returns {allowed, tokens left}; tokens as a string to keep fractions.
+var tokenBucket = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2]) / 1000
+local cost = tonumber(ARGV[3])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+if cost < 0 then
+  tokens = math.min(burst, tokens - cost)
+  allowed = 1
+elseif tokens >= 1 then
+  tokens = tokens - cost
+  allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate) + 1000)
+
+return {allowed, tostring(tokens)}
+`)
+
+// Redis shares buckets between replicas.
+type Redis struct {
+	client redis.Scripter
+	prefix string
+}
+
+// NewRedis stores buckets under keys starting with prefix.
+func NewRedis(client redis.Scripter, prefix string) *Redis {
+	return &Redis{client: client, prefix: prefix}
+}
+
+func (r *Redis) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	return r.take(ctx, key, limit, 1)
+}
+
+func (r *Redis) Refund(ctx context.Context, key string, limit Limit) error {
+	_, err := r.take(ctx, key, limit, -1)
+	return err
+}
+
+func (r *Redis) take(ctx context.Context, key string, limit Limit, cost int) (Result, error) {
+	reply, err := tokenBucket.Run(ctx, r.client, []string{r.prefix + key}, limit.Burst, limit.rate(), cost).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(reply) != 2 {
+		return Result{}, fmt.Errorf("unexpected rate limit reply %v", reply)
+	}
+
+	allowed, _ := reply[0].(int64)
+	left, _ := reply[1].(string)
+	tokens, err := strconv.ParseFloat(left, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("unexpected rate limit reply %v", reply)
+	}
+
+	return result(allowed == 1, tokens, limit), nil
+}
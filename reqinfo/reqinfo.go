@@ -0,0 +1,73 @@
+// Package reqinfo carries details of the incoming HTTP request that gofr handlers can't read
+// directly, so services can make decisions based on them.
+package reqinfo
+
+import (
+	"context"
+	"net/netip"
+	"strings"
+)
+
+// Info describes the client of a request.
+type Info struct {
+	IP             netip.Addr // invalid when it couldn't be determined
+	UserAgent      string
+	AcceptLanguage string
+	Accept         string
+	Host           string
+	LinkPassword   string // X-Link-Password, for API clients opening protected links
+	VisitorKey     string // pseudonymous key that stays the same across the client's visits
+}
+
+// WantsHTML reports whether the client is a browser expecting a page rather than JSON.
+func (i Info) WantsHTML() bool {
+	return strings.Contains(i.Accept, "text/html")
+}
+
+type infoKey struct{}
+
+func WithInfo(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, infoKey{}, info)
+}
+
+// FromContext returns the request info, or the zero Info outside of a request.
+func FromContext(ctx context.Context) Info {
+	info, _ := ctx.Value(infoKey{}).(Info)
+	return info
+}
+
+// InPrefixes reports whether ip lies within one of prefixes.
+func InPrefixes(ip netip.Addr, prefixes []netip.Prefix) bool {
+	if !ip.IsValid() {
+		return false
+	}
+
+	ip = ip.Unmap()
+	for _, p := range prefixes {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePrefixes parses CIDRs or single addresses, skipping empty entries.
+func ParsePrefixes(list []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, s := range list {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(s)
+			if addrErr != nil {
+				return nil, err
+			}
+			p = netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen())
+		}
+		prefixes = append(prefixes, p.Masked())
+	}
+	return prefixes, nil
+}
@@ -0,0 +1,177 @@
+package screening
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Blocklist holds blocked domains and URLs read from a file, one pattern per line:
+//
+//	evil.example           the domain and all its subdomains
+//	*.evil.example         only subdomains
+//	login-*.example        '*' matches any run of characters
+//	evil.example/phish     a path and everything below it on the domain
+//	evil.example/*/login   a path with wildcards, matched in full
+//
+// Blank lines and lines starting with '#' are skipped. The file is re-read when it changes, so
+// entries can be added without a restart.
+type Blocklist struct {
+	path  string
+	every time.Duration
+
+	mu       sync.RWMutex
+	patterns []pattern
+	modTime  time.Time
+	checked  time.Time
+}
+
+type pattern struct {
+	raw  string
+	host string
+	path string // empty matches every path
+}
+
+// LoadBlocklist reads the file at path, checking it for changes at most once per every.
+func LoadBlocklist(path string, every time.Duration) (*Blocklist, error) {
+	if every <= 0 {
+		every = 10 * time.Second
+	}
+
+	b := &Blocklist{path: path, every: every}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.load(info.ModTime()); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *Blocklist) load(modTime time.Time) error {
+	f, err := os.Open(b.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var patterns []pattern
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		// Whole URLs are accepted too; only the host and path count
+		text = strings.TrimPrefix(strings.TrimPrefix(text, "http://"), "https://")
+		p := pattern{raw: text, host: text}
+		if i := strings.IndexByte(text, '/'); i >= 0 {
+			p.host, p.path = text[:i], strings.TrimSuffix(text[i:], "/")
+		}
+		if p.host == "" {
+			return fmt.Errorf("%s:%d: pattern without a host", b.path, line)
+		}
+		patterns = append(patterns, p)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	b.patterns, b.modTime = patterns, modTime
+	b.mu.Unlock()
+	return nil
+}
+
+// reload re-reads the file when it changed since it was last loaded. A file that became
+// unreadable or invalid keeps the previous patterns in effect.
+func (b *Blocklist) reload() {
+	b.mu.Lock()
+	due := time.Since(b.checked) >= b.every
+	if due {
+		b.checked = time.Now()
+	}
+	modTime := b.modTime
+	b.mu.Unlock()
+	if !due {
+		return
+	}
+
+	info, err := os.Stat(b.path)
+	if err != nil {
+		log.Printf("blocklist %s: %v", b.path, err)
+		return
+	}
+	if info.ModTime().Equal(modTime) {
+		return
+	}
+	if err := b.load(info.ModTime()); err != nil {
+		log.Printf("blocklist %s: keeping the previous list: %v", b.path, err)
+		return
+	}
+	log.Printf("blocklist %s reloaded", b.path)
+}
+
+// Match reports the first pattern matching host and path, both lowercase.
+func (b *Blocklist) Match(host, path string) (string, bool) {
+	b.reload()
+
+	path = strings.ToLower(path)
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, p := range b.patterns {
+		if matchHost(p.host, host) && matchPath(p.path, path) {
+			return p.raw, true
+		}
+	}
+	return "", false
+}
+
+func matchHost(pattern, host string) bool {
+	if strings.Contains(pattern, "*") {
+		return wildcard(pattern, host)
+	}
+	return host == pattern || strings.HasSuffix(host, "."+pattern)
+}
+
+func matchPath(pattern, path string) bool {
+	if pattern == "" {
+		return true
+	}
+	if strings.Contains(pattern, "*") {
+		return wildcard(pattern, path)
+	}
+	return path == pattern || strings.HasPrefix(path, pattern+"/")
+}
+
+// wildcard matches s against pattern, in which '*' stands for any run of characters.
+func wildcard(pattern, s string) bool {
+	star, resume := -1, 0
+	p, i := 0, 0
+	for i < len(s) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, resume = p, i
+			p++
+		case p < len(pattern) && pattern[p] == s[i]:
+			p++
+			i++
+		case star >= 0:
+			// Let the last '*' swallow one more character
+			resume++
+			p, i = star+1, resume
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
@@ -0,0 +1,74 @@
+package screening
+
+import (
+	"net"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+// isIPHost reports whether browsers would take host as an IP address. Besides the usual forms
+// that includes hosts ending in a decimal or hex number, e.g. 2130706433 or 0x7f.1.
+func isIPHost(host string) bool {
+	if net.ParseIP(host) != nil {
+		return true
+	}
+
+	last := host[strings.LastIndexByte(host, '.')+1:]
+	if last == "" {
+		return false
+	}
+	if hex, ok := strings.CutPrefix(last, "0x"); ok {
+		return strings.Trim(hex, "0123456789abcdef") == ""
+	}
+	return strings.Trim(last, "0123456789") == ""
+}
+
+// confusableScripts have letters that look like Latin ones.
+var confusableScripts = []*unicode.RangeTable{unicode.Cyrillic, unicode.Greek, unicode.Armenian, unicode.Cherokee}
+
+// latinLookalikes are Cyrillic and Greek letters that can pass for Latin letters on their own,
+// so that e.g. "аpple" in Cyrillic needs no Latin letter to fool a reader.
+const latinLookalikes = "аеорсухіјѕһԁӏԛԝ" + "αικνορτυχ"
+
+// lookalike returns a label of host that mixes Latin with a confusable alphabet or is written
+// only in letters that imitate Latin ones. Punycode labels are checked in their decoded form.
+func lookalike(host string) (string, bool) {
+	for _, label := range strings.Split(host, ".") {
+		decoded := label
+		if strings.HasPrefix(label, "xn--") {
+			var err error
+			if decoded, err = idna.Punycode.ToUnicode(label); err != nil {
+				return label, true
+			}
+		}
+		if isLookalike(decoded) {
+			return label, true
+		}
+	}
+	return "", false
+}
+
+func isLookalike(label string) bool {
+	var latin, confusable, letters, imitating int
+	for _, r := range label {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.IsOneOf(confusableScripts, r):
+			confusable++
+			if strings.ContainsRune(latinLookalikes, r) {
+				imitating++
+			}
+		}
+	}
+
+	mixed := latin > 0 && confusable > 0
+	wholeScript := letters > 0 && imitating == letters
+	return mixed || wholeScript
+}
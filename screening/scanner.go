@@ -0,0 +1,65 @@
+package screening
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// HTTPScanner asks a web service about each URL. It POSTs {"url": "..."} to the endpoint and
+// expects {"blocked": true, "reason": "..."} or {"blocked": false} back, which makes it easy to
+// put in front of a safe browsing API or an in-house threat feed.
+type HTTPScanner struct {
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+// NewHTTPScanner sends token, if set, as a bearer token.
+func NewHTTPScanner(endpoint, token string, client *http.Client) *HTTPScanner {
+	return &HTTPScanner{endpoint: endpoint, token: token, client: client}
+}
+
+func (s *HTTPScanner) Scan(ctx context.Context, u *url.URL) error {
+	body, err := json.Marshal(map[string]string{"url": u.String()})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("scanner answered %d", resp.StatusCode)
+	}
+
+	var verdict struct {
+		Blocked bool   `json:"blocked"`
+		Reason  string `json:"reason"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&verdict); err != nil {
+		return fmt.Errorf("invalid scanner response: %w", err)
+	}
+	if verdict.Blocked {
+		if verdict.Reason == "" {
+			verdict.Reason = "flagged by the URL scanner"
+		}
+		return &BlockedError{Reason: verdict.Reason}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Package screening decides whether a link destination may be used. It checks the URL scheme
+// against an allowlist, matches the URL against a local blocklist, applies heuristics for hosts
+// common in phishing and finally asks external scanners.
+package screening
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultSchemes are the schemes destinations may use unless configured otherwise.
+var DefaultSchemes = []string{"http", "https"}
+
+// BlockedError explains why a destination was rejected.
+type BlockedError struct {
+	Reason string
+}
+
+func (e *BlockedError) Error() string {
+	return "destination blocked: " + e.Reason
+}
+
+func blocked(format string, args ...any) error {
+	return &BlockedError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// Scanner is an external source of URL reputation, such as a safe browsing service. Scanners
+// return a *BlockedError for unsafe URLs and other errors when they couldn't decide.
+type Scanner interface {
+	Scan(ctx context.Context, u *url.URL) error
+}
+
+type Config struct {
+	Schemes       []string      // allowed URL schemes, nil means DefaultSchemes
+	BlocklistFile string        // optional, see Blocklist
+	ReloadEvery   time.Duration // how often the blocklist file is checked for changes, 0 means 10s
+	AllowIPHosts  bool          // accept hosts given as IP addresses, e.g. for intranet links
+	Scanners      []Scanner
+}
+
+type Screener struct {
+	schemes      map[string]bool
+	blocklist    *Blocklist
+	allowIPHosts bool
+	scanners     []Scanner
+}
+
+func New(cfg Config) (*Screener, error) {
+	if cfg.Schemes == nil {
+		cfg.Schemes = DefaultSchemes
+	}
+
+	s := &Screener{
+		schemes:      make(map[string]bool),
+		allowIPHosts: cfg.AllowIPHosts,
+		scanners:     cfg.Scanners,
+	}
+	for _, scheme := range cfg.Schemes {
+		if scheme = strings.ToLower(strings.TrimSpace(scheme)); scheme != "" {
+			s.schemes[scheme] = true
+		}
+	}
+
+	if cfg.BlocklistFile != "" {
+		b, err := LoadBlocklist(cfg.BlocklistFile, cfg.ReloadEvery)
+		if err != nil {
+			return nil, err
+		}
+		s.blocklist = b
+	}
+	return s, nil
+}
+
+// Screen returns a *BlockedError when rawURL must not be used as a destination. Scanners that
+// fail don't block the URL, so an outage of one doesn't stop links from being created.
+func (s *Screener) Screen(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return blocked("not a valid URL")
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if !s.schemes[scheme] {
+		if scheme == "" {
+			return blocked("the URL has no scheme, such as https://")
+		}
+		return blocked("%s: URLs are not allowed", scheme)
+	}
+
+	// Hierarchical schemes need a host; mailto: and the like don't have one
+	if u.Opaque == "" && u.Host == "" {
+		return blocked("the URL has no host")
+	}
+
+	if u.User != nil {
+		return blocked("user names before the host can disguise the real site")
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if s.blocklist != nil {
+		if pattern, ok := s.blocklist.Match(host, u.EscapedPath()); ok {
+			return blocked("%s is on the blocklist (%s)", host, pattern)
+		}
+	}
+
+	if !s.allowIPHosts && isIPHost(host) {
+		return blocked("IP addresses are not allowed as hosts")
+	}
+	if label, ok := lookalike(host); ok {
+		return blocked("%s mixes alphabets or imitates Latin letters", label)
+	}
+
+	for _, scanner := range s.scanners {
+		err := scanner.Scan(ctx, u)
+		if _, ok := err.(*BlockedError); ok {
+			return err
+		}
+		if err != nil {
+			log.Printf("screening %s: scanner failed: %v", host, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package screening
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScreen(t *testing.T) {
+	s, err := New(Config{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	for _, ok := range []string{
+		"https://example.com/path?q=1",
+		"http://xn--mnchen-3ya.de/",      // münchen
+		"https://xn--hxajbheg2az3al.gr/", // παράδειγμα
+		"https://shop.example.co.uk",
+	} {
+		assert.NoError(t, s.Screen(ctx, ok), ok)
+	}
+
+	for _, bad := range []string{
+		"javascript:alert(1)",
+		"data:text/html;base64,PHNjcmlwdD4=",
+		"file:///etc/passwd",
+		"example.com/no-scheme",
+		"https://",
+		"https://paypal.com@evil.example/",
+		"http://203.0.113.7/login",
+		"http://[2001:db8::1]/",
+		"http://2130706433/",
+		"http://0x7f.0x0.0x0.0x1/",
+		"https://xn--pple-43d.com/",   // аpple with a Cyrillic а
+		"https://xn--80ak6aa92e.com/", // аррӏе, all Cyrillic
+	} {
+		err := s.Screen(ctx, bad)
+		var blockedErr *BlockedError
+		assert.True(t, errors.As(err, &blockedErr), bad)
+	}
+
+	s, err = New(Config{Schemes: []string{"https", "mailto"}, AllowIPHosts: true})
+	require.NoError(t, err)
+	assert.NoError(t, s.Screen(ctx, "mailto:team@example.com"))
+	assert.NoError(t, s.Screen(ctx, "https://10.0.0.8/wiki"))
+	assert.Error(t, s.Screen(ctx, "http://example.com"))
+}
+
+func TestBlocklist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	require.NoError(t, os.WriteFile(path, []byte(`
+# Phishing
+evil.example
+*.bad.example
+login-*.example
+https://shared.example/~mallory
+files.example/*/payload.exe
+`), 0o600))
+
+	s, err := New(Config{BlocklistFile: path, ReloadEvery: time.Millisecond})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	for _, u := range []string{
+		"https://evil.example/",
+		"https://www.evil.example/x",
+		"https://cdn.bad.example/",
+		"https://login-secure.example/",
+		"https://shared.example/~mallory/page",
+		"https://files.example/2024/payload.exe",
+	} {
+		assert.Error(t, s.Screen(ctx, u), u)
+	}
+	for _, u := range []string{
+		"https://notevil.example/",
+		"https://bad.example/",
+		"https://shared.example/~alice",
+		"https://shared.example/~mallory2",
+		"https://files.example/2024/readme.txt",
+	} {
+		assert.NoError(t, s.Screen(ctx, u), u)
+	}
+
+	// Changes to the file apply without a restart
+	require.NoError(t, os.WriteFile(path, []byte("example.org\n"), 0o600))
+	later := time.Now().Add(time.Second)
+	require.NoError(t, os.Chtimes(path, later, later))
+	time.Sleep(2 * time.Millisecond)
+	assert.Error(t, s.Screen(ctx, "https://example.org/"))
+	assert.NoError(t, s.Screen(ctx, "https://evil.example/"))
+
+	_, err = New(Config{BlocklistFile: filepath.Join(t.TempDir(), "missing.txt")})
+	assert.Error(t, err)
+}
+
+func TestHTTPScanner(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ URL string }
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch {
+		case r.Header.Get("Authorization") != "Bearer secret":
+			w.WriteHeader(http.StatusUnauthorized)
+		case req.URL == "https://phish.example/":
+			_, _ = w.Write([]byte(`{"blocked": true, "reason": "known phishing page"}`))
+		default:
+			_, _ = w.Write([]byte(`{"blocked": false}`))
+		}
+	}))
+	defer srv.Close()
+
+	s, err := New(Config{Scanners: []Scanner{NewHTTPScanner(srv.URL, "secret", srv.Client())}})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	assert.NoError(t, s.Screen(ctx, "https://example.com/"))
+	err = s.Screen(ctx, "https://phish.example/")
+	assert.EqualError(t, err, "destination blocked: known phishing page")
+
+	// A failing scanner doesn't block links
+	s, err = New(Config{Scanners: []Scanner{NewHTTPScanner(srv.URL, "wrong", srv.Client())}})
+	require.NoError(t, err)
+	assert.NoError(t, s.Screen(ctx, "https://phish.example/"))
+}
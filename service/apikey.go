@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/auth"
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/store"
+)
+
+const (
+	apiKeyPrefix       = "sek_"
+	apiKeyDisplayChars = 12
+	// lastUsedResolution limits last-used tracking to one write per key and interval.
+	lastUsedResolution = time.Minute
+)
+
+type APIKeyService interface {
+	Create(ctx context.Context, req model.APIKeyRequest) (model.CreatedAPIKey, error)
+	List(ctx context.Context) ([]model.APIKey, error)
+	Revoke(ctx context.Context, id int64) error
+	Rotate(ctx context.Context, id int64) (model.CreatedAPIKey, error)
+	Authenticate(ctx context.Context, key string) (auth.Principal, error)
+}
+
+type apiKeyService struct {
+	store store.APIKey
+	users store.User
+}
+
+func NewAPIKeyService(s store.APIKey, users store.User) APIKeyService {
+	return &apiKeyService{store: s, users: users}
+}
+
+// keyManager returns the caller if they may manage API keys. Keys can only manage keys
+// when they carry the admin scope.
+func keyManager(ctx context.Context) (auth.Principal, error) {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return auth.Principal{}, ErrUnauthenticated
+	}
+	if p.APIKeyID != 0 && !p.HasScope(auth.ScopeAdmin) {
+		return auth.Principal{}, ErrKeyManagement
+	}
+	return p, nil
+}
+
+func (a *apiKeyService) Create(ctx context.Context, req model.APIKeyRequest) (model.CreatedAPIKey, error) {
+	p, err := keyManager(ctx)
+	if err != nil {
+		return model.CreatedAPIKey{}, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return model.CreatedAPIKey{}, fmt.Errorf("name is required")
+	}
+
+	scopes, err := validateScopes(p, req.Scopes)
+	if err != nil {
+		return model.CreatedAPIKey{}, err
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return model.CreatedAPIKey{}, fmt.Errorf("expires_at must be in the future")
+	}
+
+	key, plain, err := newAPIKey(model.APIKey{UserID: p.UserID, Name: name, Scopes: scopes, ExpiresAt: req.ExpiresAt})
+	if err != nil {
+		return model.CreatedAPIKey{}, err
+	}
+
+	key, err = a.store.Create(ctx, key, auth.HashToken(plain))
+	if err != nil {
+		return model.CreatedAPIKey{}, err
+	}
+
+	return model.CreatedAPIKey{APIKey: key, Key: plain}, nil
+}
+
+func (a *apiKeyService) List(ctx context.Context) ([]model.APIKey, error) {
+	p, err := keyManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.store.ListByUser(ctx, p.UserID)
+}
+
+func (a *apiKeyService) Revoke(ctx context.Context, id int64) error {
+	if _, err := a.owned(ctx, id); err != nil {
+		return err
+	}
+
+	return a.store.Revoke(ctx, id, time.Now())
+}
+
+// Rotate replaces a key with a new one that has the same name, scopes and expiry.
+func (a *apiKeyService) Rotate(ctx context.Context, id int64) (model.CreatedAPIKey, error) {
+	old, err := a.owned(ctx, id)
+	if err != nil {
+		return model.CreatedAPIKey{}, err
+	}
+	if old.RevokedAt != nil {
+		return model.CreatedAPIKey{}, fmt.Errorf("api key is revoked")
+	}
+
+	key, plain, err := newAPIKey(model.APIKey{UserID: old.UserID, Name: old.Name, Scopes: old.Scopes,
+		ExpiresAt: old.ExpiresAt})
+	if err != nil {
+		return model.CreatedAPIKey{}, err
+	}
+
+	key, err = a.store.Rotate(ctx, old.ID, key, auth.HashToken(plain))
+	if err != nil {
+		return model.CreatedAPIKey{}, err
+	}
+
+	return model.CreatedAPIKey{APIKey: key, Key: plain}, nil
+}
+
+// Authenticate resolves an API key to its owner, limited to the key's scopes.
+func (a *apiKeyService) Authenticate(ctx context.Context, plain string) (auth.Principal, error) {
+	key, err := a.store.GetByHash(ctx, auth.HashToken(plain))
+	if err != nil {
+		return auth.Principal{}, ErrUnauthenticated
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && !key.ExpiresAt.After(now)) {
+		return auth.Principal{}, ErrUnauthenticated
+	}
+
+	user, err := a.users.GetByID(ctx, key.UserID)
+	if err != nil {
+		return auth.Principal{}, ErrUnauthenticated
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		// Failing to record usage must not fail the request.
+		_ = a.store.TouchLastUsed(ctx, key.ID, now)
+	}
+
+	scopes := key.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	p := userPrincipal(user)
+	p.APIKeyID = key.ID
+	p.Scopes = scopes
+	return p, nil
+}
+
+func (a *apiKeyService) owned(ctx context.Context, id int64) (model.APIKey, error) {
+	p, err := keyManager(ctx)
+	if err != nil {
+		return model.APIKey{}, err
+	}
+
+	key, err := a.store.GetByID(ctx, id)
+	if err != nil {
+		return model.APIKey{}, fmt.Errorf("api key not found")
+	}
+	if key.UserID != p.UserID {
+		return model.APIKey{}, fmt.Errorf("api key not found")
+	}
+
+	return key, nil
+}
+
+func newAPIKey(key model.APIKey) (model.APIKey, string, error) {
+	plain, err := auth.NewToken(apiKeyPrefix)
+	if err != nil {
+		return model.APIKey{}, "", err
+	}
+
+	key.Prefix = plain[:apiKeyDisplayChars]
+	key.CreatedAt = time.Now()
+
+	return key, plain, nil
+}
+
+// validateScopes dedupes scopes and rejects unknown ones; only admins may hand out admin.
+func validateScopes(p auth.Principal, scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+
+	known := make(map[string]bool, len(auth.AllScopes))
+	for _, s := range auth.AllScopes {
+		known[s] = true
+	}
+
+	seen := make(map[string]bool, len(scopes))
+	var out []string
+	for _, s := range scopes {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if !known[s] {
+			return nil, fmt.Errorf("unknown scope %q", s)
+		}
+		if s == auth.ScopeAdmin && !p.IsAdmin() {
+			return nil, ErrAdminScope
+		}
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+
+	return out, nil
+}
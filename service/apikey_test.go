@@ -0,0 +1,151 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/auth"
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAPIKeyStore struct {
+	keys   map[int64]model.APIKey
+	hashes map[string]int64
+}
+
+func newMockAPIKeyStore() *mockAPIKeyStore {
+	return &mockAPIKeyStore{keys: make(map[int64]model.APIKey), hashes: make(map[string]int64)}
+}
+
+func (m *mockAPIKeyStore) Create(ctx context.Context, key model.APIKey, hash string) (model.APIKey, error) {
+	key.ID = int64(len(m.keys) + 1)
+	m.keys[key.ID] = key
+	m.hashes[hash] = key.ID
+	return key, nil
+}
+
+func (m *mockAPIKeyStore) GetByID(ctx context.Context, id int64) (model.APIKey, error) {
+	k, ok := m.keys[id]
+	if !ok {
+		return model.APIKey{}, sql.ErrNoRows
+	}
+	return k, nil
+}
+
+func (m *mockAPIKeyStore) GetByHash(ctx context.Context, hash string) (model.APIKey, error) {
+	id, ok := m.hashes[hash]
+	if !ok {
+		return model.APIKey{}, sql.ErrNoRows
+	}
+	return m.keys[id], nil
+}
+
+func (m *mockAPIKeyStore) ListByUser(ctx context.Context, userID int64) ([]model.APIKey, error) {
+	var out []model.APIKey
+	for _, k := range m.keys {
+		if k.UserID == userID {
+			out = append(out, k)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockAPIKeyStore) Revoke(ctx context.Context, id int64, at time.Time) error {
+	k := m.keys[id]
+	k.RevokedAt = &at
+	m.keys[id] = k
+	return nil
+}
+
+func (m *mockAPIKeyStore) Rotate(ctx context.Context, oldID int64, key model.APIKey, hash string) (model.APIKey, error) {
+	if err := m.Revoke(ctx, oldID, key.CreatedAt); err != nil {
+		return model.APIKey{}, err
+	}
+	return m.Create(ctx, key, hash)
+}
+
+func (m *mockAPIKeyStore) TouchLastUsed(ctx context.Context, id int64, at time.Time) error {
+	k := m.keys[id]
+	k.LastUsedAt = &at
+	m.keys[id] = k
+	return nil
+}
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	users := newMockUserStore()
+	dev, _ := users.Create(context.Background(), model.User{Email: "dev@example.com", Role: auth.RoleUser})
+	keys := newMockAPIKeyStore()
+	svc := service.NewAPIKeyService(keys, users)
+
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: dev.ID, Role: auth.RoleUser})
+
+	_, err := svc.Create(context.Background(), model.APIKeyRequest{Name: "ci", Scopes: []string{auth.ScopeLinksRead}})
+	assert.ErrorIs(t, err, service.ErrUnauthenticated)
+
+	_, err = svc.Create(ctx, model.APIKeyRequest{Name: "ci", Scopes: []string{"links:everything"}})
+	assert.Error(t, err)
+
+	_, err = svc.Create(ctx, model.APIKeyRequest{Name: "ci", Scopes: []string{auth.ScopeAdmin}})
+	assert.ErrorIs(t, err, service.ErrAdminScope)
+
+	created, err := svc.Create(ctx, model.APIKeyRequest{Name: "ci", Scopes: []string{auth.ScopeLinksRead, "LINKS:READ"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{auth.ScopeLinksRead}, created.Scopes)
+	assert.Equal(t, created.Key[:len(created.Prefix)], created.Prefix)
+
+	p, err := svc.Authenticate(context.Background(), created.Key)
+	require.NoError(t, err)
+	assert.Equal(t, dev.ID, p.UserID)
+	assert.True(t, p.HasScope(auth.ScopeLinksRead))
+	assert.False(t, p.HasScope(auth.ScopeLinksWrite))
+	assert.NotNil(t, keys.keys[created.ID].LastUsedAt)
+
+	// A key without the admin scope can't manage keys.
+	_, err = svc.List(auth.WithPrincipal(context.Background(), p))
+	assert.ErrorIs(t, err, service.ErrKeyManagement)
+
+	rotated, err := svc.Rotate(ctx, created.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, created.Key, rotated.Key)
+
+	_, err = svc.Authenticate(context.Background(), created.Key)
+	assert.ErrorIs(t, err, service.ErrUnauthenticated)
+
+	_, err = svc.Authenticate(context.Background(), rotated.Key)
+	require.NoError(t, err)
+
+	other := auth.WithPrincipal(context.Background(), auth.Principal{UserID: dev.ID + 1, Role: auth.RoleUser})
+	assert.Error(t, svc.Revoke(other, rotated.ID))
+
+	require.NoError(t, svc.Revoke(ctx, rotated.ID))
+	_, err = svc.Authenticate(context.Background(), rotated.Key)
+	assert.True(t, errors.Is(err, service.ErrUnauthenticated))
+}
+
+func TestAPIKeyExpiry(t *testing.T) {
+	users := newMockUserStore()
+	dev, _ := users.Create(context.Background(), model.User{Email: "dev@example.com", Role: auth.RoleUser})
+	keys := newMockAPIKeyStore()
+	svc := service.NewAPIKeyService(keys, users)
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: dev.ID, Role: auth.RoleUser})
+
+	past := time.Now().Add(-time.Hour)
+	_, err := svc.Create(ctx, model.APIKeyRequest{Name: "old", Scopes: []string{auth.ScopeLinksRead}, ExpiresAt: &past})
+	assert.Error(t, err)
+
+	created, err := svc.Create(ctx, model.APIKeyRequest{Name: "ci", Scopes: []string{auth.ScopeLinksRead}})
+	require.NoError(t, err)
+
+	k := keys.keys[created.ID]
+	k.ExpiresAt = &past
+	keys.keys[created.ID] = k
+
+	_, err = svc.Authenticate(context.Background(), created.Key)
+	assert.ErrorIs(t, err, service.ErrUnauthenticated)
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/reqinfo"
+)
+
+// Mobile platforms, as told apart by platformOf.
+const (
+	platformIOS     = "ios"
+	platformAndroid = "android"
+)
+
+// platformOf detects the visitor's mobile platform from their User-Agent, "" for anything else.
+func platformOf(userAgent string) string {
+	switch {
+	case strings.Contains(userAgent, "Android"):
+		return platformAndroid
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"), strings.Contains(userAgent, "iPod"):
+		return platformIOS
+	}
+	return ""
+}
+
+// normalizeAppLink validates an app link; an empty one yields nil, removing it.
+func normalizeAppLink(a *model.AppLink) (*model.AppLink, error) {
+	if a == nil {
+		return nil, nil
+	}
+
+	link := model.AppLink{AppURL: strings.TrimSpace(a.AppURL), StoreURL: strings.TrimSpace(a.StoreURL)}
+	if link == (model.AppLink{}) {
+		return nil, nil
+	}
+
+	if link.AppURL != "" {
+		u, err := url.Parse(link.AppURL)
+		if err != nil || u.Scheme == "" {
+			return nil, fmt.Errorf("app_url must be an absolute URL such as myapp://path")
+		}
+		switch strings.ToLower(u.Scheme) {
+		case "javascript", "data", "vbscript", "file":
+			return nil, fmt.Errorf("app_url can't use the %s scheme", u.Scheme)
+		}
+	}
+
+	if link.StoreURL != "" {
+		u, err := url.Parse(link.StoreURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return nil, fmt.Errorf("store_url must be an http(s) URL")
+		}
+	}
+
+	return &link, nil
+}
+
+// openInApp points mobile visitors at the app link of their platform: the app itself when set,
+// falling back to the store listing or, without one, the long URL, or else the store listing.
+// Others keep the long URL.
+func openInApp(ctx context.Context, link model.URL) model.URL {
+	var app *model.AppLink
+	switch platformOf(reqinfo.FromContext(ctx).UserAgent) {
+	case platformIOS:
+		app = link.IOS
+	case platformAndroid:
+		app = link.Android
+	}
+	if app == nil {
+		return link
+	}
+
+	if app.AppURL != "" {
+		// Visitors without the app would be left at a dead custom scheme link
+		link.AppFallbackURL = app.StoreURL
+		if link.AppFallbackURL == "" {
+			link.AppFallbackURL = link.LongURL
+		}
+		link.LongURL = app.AppURL
+	} else {
+		link.LongURL = app.StoreURL
+	}
+	return link
+}
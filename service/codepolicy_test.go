@@ -0,0 +1,70 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Kritvi0208/ShortEdge/codepolicy"
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShorten_CodePolicy(t *testing.T) {
+	policy, err := codepolicy.New(codepolicy.Config{Case: codepolicy.CaseLower, Profanity: true, Reserved: []string{"pricing"}})
+	require.NoError(t, err)
+	policy.ReserveRoute("/analytics/{code}")
+	urls := newMockStore()
+	svc := service.New(urls, service.WithCodePolicy(policy))
+
+	for code, msg := range map[string]string{
+		"Analytics": "custom_code is reserved",
+		"pricing":   "custom_code is reserved",
+		"sh1t":      "custom_code contains a word that isn't allowed",
+		"a/b/c":     "custom_code can only contain letters, digits, '-' and '_', not '/'",
+		"ab":        "custom_code must be between 3 and 64 characters long",
+		"sale+":     "custom_code can't contain '@' or '+'",
+	} {
+		_, err := svc.Shorten(userCtx(1), model.ShortenRequest{LongURL: "https://example.com", CustomCode: code})
+		var verr service.ValidationError
+		require.ErrorAs(t, err, &verr, code)
+		assert.Equal(t, "custom_code", verr.Fields[0].Field, code)
+		assert.EqualError(t, err, msg, code)
+	}
+
+	// Custom codes are folded to lowercase, so the same word in another case is taken
+	link, err := svc.Shorten(userCtx(1), model.ShortenRequest{LongURL: "https://example.com", CustomCode: "Spring-Sale"})
+	require.NoError(t, err)
+	assert.Equal(t, "spring-sale", link.Code)
+	_, err = svc.Shorten(userCtx(1), model.ShortenRequest{LongURL: "https://example.com", CustomCode: "SPRING-SALE"})
+	assert.ErrorContains(t, err, "already exists")
+
+	// Visitors find it in any case, while generated codes still only match exactly
+	resolved, err := svc.Resolve(context.Background(), "Spring-SALE", "")
+	require.NoError(t, err)
+	assert.Equal(t, "spring-sale", resolved.Code)
+	urls.urls["aB3xYz"] = model.URL{Code: "aB3xYz", LongURL: "https://example.com", Visibility: model.VisibilityPublic}
+	_, err = svc.Resolve(context.Background(), "aB3xYz", "")
+	assert.NoError(t, err)
+
+	// Updates may send the code back but not change it
+	_, err = svc.Update(userCtx(1), link.Code, model.ShortenRequest{LongURL: "https://example.org", CustomCode: "Spring-Sale"})
+	require.NoError(t, err)
+	_, err = svc.Update(userCtx(1), link.Code, model.ShortenRequest{LongURL: "https://example.org", CustomCode: "summer-sale"})
+	assert.EqualError(t, err, "custom_code can't be changed, create a new link instead")
+	_, err = svc.Update(userCtx(1), link.Code, model.ShortenRequest{LongURL: "https://example.org", CustomCode: "admin"})
+	assert.EqualError(t, err, "custom_code is reserved")
+
+	// Owners can change the link through the code in any case too
+	updated, err := svc.Update(userCtx(1), "Spring-SALE", model.ShortenRequest{LongURL: "https://example.net"})
+	require.NoError(t, err)
+	assert.Equal(t, "spring-sale", updated.Code)
+	assert.Equal(t, "https://example.net", urls.urls["spring-sale"].LongURL)
+	assert.NotContains(t, urls.urls, "Spring-SALE")
+
+	// Codes from before the policy can be sent back even though they break it now
+	urls.urls["AB"] = model.URL{Code: "AB", LongURL: "https://example.com", OwnerID: ptrInt64(1)}
+	_, err = svc.Update(userCtx(1), "AB", model.ShortenRequest{LongURL: "https://example.org", CustomCode: "AB"})
+	assert.NoError(t, err)
+}
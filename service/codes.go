@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Kritvi0208/ShortEdge/codegen"
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/store"
+)
+
+// maxCodeAttempts bounds the candidates tried for one new link before giving up.
+const maxCodeAttempts = 5
+
+// codeStyles keeps one generator per spec, so adaptive generators remember their collisions
+// across links.
+type codeStyles struct {
+	def    string
+	parser codegen.Parser
+
+	mu         sync.Mutex
+	generators map[string]codegen.Generator
+}
+
+// WithCodeStyle sets how codes of new links are generated when neither their branded domain
+// nor their workspace chose a style; spec is parsed by codegen.Parse.
+func WithCodeStyle(spec string) Option {
+	return func(u *urlService) { u.codes.def = spec }
+}
+
+// CodePolicy rules which codes links can have; codepolicy.Policy implements it.
+type CodePolicy interface {
+	// Normalize checks a custom code and returns the form to store, or an error that reads as
+	// a predicate of the code, such as "is reserved".
+	Normalize(code string) (string, error)
+	// Allowed reports whether a generated code can be used.
+	Allowed(code string) bool
+	// Fold applies the policy's case rule, so links can be found in the case they're stored in.
+	Fold(code string) string
+}
+
+// WithCodePolicy enforces a policy on custom codes and skips generated codes it doesn't allow.
+func WithCodePolicy(p CodePolicy) Option {
+	return func(u *urlService) { u.codePolicy = p }
+}
+
+// customCode checks a custom code against the policy, if there is one.
+func (u *urlService) customCode(code string) (string, error) {
+	// '@' qualifies codes with their domain and a trailing '+' opens the preview page
+	if strings.ContainsAny(code, "@+") {
+		return "", invalidField("custom_code", "can't contain '@' or '+'")
+	}
+	if u.codePolicy == nil {
+		return code, nil
+	}
+	normalized, err := u.codePolicy.Normalize(code)
+	if err != nil {
+		return "", invalidField("custom_code", err.Error())
+	}
+	return normalized, nil
+}
+
+// linkIDCounter is the id_blocks counter sequential codes are leased from. Codes of all
+// lengths and domains share it, so an ID is only ever used once.
+const linkIDCounter = "links"
+
+// WithIDBlocks enables the sequential code style: IDs are leased from blocks in size blocks
+// and shuffled into codes with key, which must stay the same across replicas and restarts.
+// Without a key sequential codes stay disabled.
+func WithIDBlocks(blocks store.IDBlock, key []byte, size int64) Option {
+	return func(u *urlService) {
+		if len(key) == 0 {
+			return
+		}
+		u.codes.parser = codegen.Parser{
+			Leaser: codegen.LeaserFunc(func(ctx context.Context, n int64) (int64, error) {
+				return blocks.Lease(ctx, linkIDCounter, n)
+			}),
+			Key:       key,
+			BlockSize: size,
+		}
+	}
+}
+
+func (c *codeStyles) generator(spec string) (codegen.Generator, error) {
+	if spec == "" {
+		spec = c.def
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if g, ok := c.generators[spec]; ok {
+		return g, nil
+	}
+	g, err := c.parser.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	if c.generators == nil {
+		c.generators = make(map[string]codegen.Generator)
+	}
+	c.generators[spec] = g
+	return g, nil
+}
+
+// codeStyle picks the spec for a new link: its domain's, else its workspace's, else the default.
+func (u *urlService) codeStyle(ctx context.Context, domain string, workspaceID *int64) string {
+	if domain != "" && u.domains != nil {
+		if d, err := u.domains.GetByName(ctx, domain); err == nil && d.CodeStyle != "" {
+			return d.CodeStyle
+		}
+	}
+	if workspaceID != nil && u.workspaces != nil {
+		if ws, err := u.workspaces.GetByID(ctx, *workspaceID); err == nil {
+			return ws.CodeStyle
+		}
+	}
+	return ""
+}
+
+// insert stores a new link under code, or under a generated code in the style of its domain or
+// workspace when code is empty. Generated codes that turn out to be taken, found by looking
+// them up or, for collision-free styles, only by the insert failing, are replaced by new ones.
+func (u *urlService) insert(ctx context.Context, link *model.URL, code string) error {
+	if code != "" {
+		link.Code = domainCode(code, link.Domain)
+		err := u.store.Create(ctx, *link)
+		if errors.Is(err, store.ErrCodeTaken) {
+			return fmt.Errorf("custom code '%s' already exists", code)
+		}
+		return err
+	}
+
+	g, err := u.codes.generator(u.codeStyle(ctx, link.Domain, link.WorkspaceID))
+	if err != nil {
+		return err
+	}
+	observer, _ := g.(codegen.Observer)
+	_, unique := g.(codegen.CollisionFree)
+
+	for i := 0; i < maxCodeAttempts; i++ {
+		code, err := g.Generate(ctx)
+		if err != nil {
+			return err
+		}
+		if u.codePolicy != nil && !u.codePolicy.Allowed(code) {
+			continue
+		}
+		link.Code = domainCode(code, link.Domain)
+
+		taken := false
+		if !unique {
+			_, err := u.store.GetByCode(ctx, link.Code)
+			taken = err == nil
+		}
+		if !taken {
+			err := u.store.Create(ctx, *link)
+			if !errors.Is(err, store.ErrCodeTaken) {
+				if err == nil && observer != nil {
+					observer.Accepted()
+				}
+				return err
+			}
+		}
+		if observer != nil {
+			observer.Collided()
+		}
+	}
+	return fmt.Errorf("failed to generate unique short code after %d attempts", maxCodeAttempts)
+}
+
+// validCodeStyle checks a style given for a domain or workspace; empty means the default.
+func validCodeStyle(spec string) error {
+	if spec == "" {
+		return nil
+	}
+	if err := codegen.Validate(spec); err != nil {
+		return invalidField("code_style", "is invalid: "+err.Error())
+	}
+	return nil
+}
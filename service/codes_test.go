@@ -0,0 +1,128 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/codegen"
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShorten_CodeStyles(t *testing.T) {
+	workspaces := newMockWorkspaceStore()
+	wsSvc := service.NewWorkspaceService(workspaces, time.Hour)
+	owner := memberCtx(1, 0, "owner@example.com")
+
+	_, err := wsSvc.Create(owner, model.WorkspaceRequest{Name: "team", CodeStyle: ptrString("snowflake")})
+	assert.ErrorContains(t, err, "code_style is invalid")
+	team, err := wsSvc.Create(owner, model.WorkspaceRequest{Name: "team", CodeStyle: ptrString("words:2")})
+	require.NoError(t, err)
+	assert.Equal(t, "words:2", team.CodeStyle)
+	plain, err := wsSvc.Create(owner, model.WorkspaceRequest{Name: "plain"})
+	require.NoError(t, err)
+
+	verified := time.Now()
+	urls := newMockStore()
+	domains := &mockDomainStore{urls: urls, domains: map[int64]model.Domain{
+		1: {ID: 1, Name: "go.acme.com", WorkspaceID: team.ID, VerifiedAt: &verified, CodeStyle: "nolookalike:9"},
+	}}
+	svc := service.New(urls, service.WithWorkspaceStore(workspaces), service.WithDomainStore(domains),
+		service.WithCodeStyle("random:8"))
+
+	// The workspace's style
+	link, err := svc.Shorten(memberCtx(1, team.ID, "owner@example.com"), model.ShortenRequest{LongURL: "https://example.com"})
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(link.Code, "-"), 2, link.Code)
+
+	// The domain's style wins over it
+	link, err = svc.Shorten(memberCtx(1, team.ID, "owner@example.com"),
+		model.ShortenRequest{LongURL: "https://example.com", Domain: "go.acme.com"})
+	require.NoError(t, err)
+	code, _, _ := strings.Cut(link.Code, "@")
+	assert.Len(t, code, 9)
+	assert.Empty(t, strings.Trim(code, codegen.NoLookalike))
+
+	// Neither chose one
+	link, err = svc.Shorten(memberCtx(1, plain.ID, "owner@example.com"), model.ShortenRequest{LongURL: "https://example.com"})
+	require.NoError(t, err)
+	assert.Len(t, link.Code, 8)
+	link, err = svc.Shorten(context.Background(), model.ShortenRequest{LongURL: "https://example.com"})
+	require.NoError(t, err)
+	assert.Len(t, link.Code, 8)
+
+	// Resetting the workspace's style
+	_, err = wsSvc.Rename(owner, team.ID, model.WorkspaceRequest{Name: "team", CodeStyle: ptrString("")})
+	require.NoError(t, err)
+	link, err = svc.Shorten(memberCtx(1, team.ID, "owner@example.com"), model.ShortenRequest{LongURL: "https://example.com"})
+	require.NoError(t, err)
+	assert.Len(t, link.Code, 8)
+}
+
+func ptrString(s string) *string {
+	return &s
+}
+
+type mockIDBlocks struct {
+	next   map[string]int64
+	leases int
+}
+
+func (m *mockIDBlocks) Lease(ctx context.Context, name string, size int64) (int64, error) {
+	m.leases++
+	first := m.next[name]
+	m.next[name] += size
+	return first, nil
+}
+
+// countingStore counts code lookups.
+type countingStore struct {
+	*mockStore
+	lookups int
+}
+
+func (c *countingStore) GetByCode(ctx context.Context, code string) (model.URL, error) {
+	c.lookups++
+	return c.mockStore.GetByCode(ctx, code)
+}
+
+func TestShorten_SequentialCodes(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	shuffle, err := codegen.NewShuffle(key, codegen.Base62, 6)
+	require.NoError(t, err)
+	first, err := shuffle.Encode(0)
+	require.NoError(t, err)
+
+	urls := &countingStore{mockStore: newMockStore()}
+	blocks := &mockIDBlocks{next: make(map[string]int64)}
+	svc := service.New(urls, service.WithCodeStyle("sequential:6"), service.WithIDBlocks(blocks, key, 10))
+
+	// Someone already chose the code of ID 0 as their custom code
+	_, err = svc.Shorten(userCtx(1), model.ShortenRequest{LongURL: "https://example.com", CustomCode: first})
+	require.NoError(t, err)
+	urls.lookups = 0
+
+	seen := map[string]bool{first: true}
+	for i := 0; i < 25; i++ {
+		link, err := svc.Shorten(userCtx(1), model.ShortenRequest{LongURL: "https://example.com"})
+		require.NoError(t, err)
+		assert.Len(t, link.Code, 6)
+		assert.False(t, seen[link.Code], link.Code)
+		seen[link.Code] = true
+
+		id, err := shuffle.Decode(link.Code)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(i+1), id, "ID 0 was skipped")
+	}
+	assert.Zero(t, urls.lookups, "no lookups for collision-free codes")
+	assert.Equal(t, 3, blocks.leases)
+
+	// Without a counter the style can be chosen but not used
+	plain := service.New(newMockStore(), service.WithCodeStyle("sequential:6"))
+	_, err = plain.Shorten(userCtx(1), model.ShortenRequest{LongURL: "https://example.com"})
+	assert.ErrorContains(t, err, "sequential codes are not enabled")
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+)
+
+// WithDedupe, when on, makes Shorten return the caller's existing link when they shorten the
+// same destination with the same settings again, unless the request sets force_new.
+func WithDedupe(on bool) Option {
+	return func(u *urlService) { u.dedupe = on }
+}
+
+// duplicate finds a usable link of want's owner with the same destination, settings and tags.
+func (u *urlService) duplicate(ctx context.Context, want model.URL, tags []string) (model.URL, bool, error) {
+	if want.OwnerID == nil {
+		return model.URL{}, false, nil
+	}
+
+	candidates, err := u.store.GetByLongURL(ctx, *want.OwnerID, want.LongURL)
+	if err != nil {
+		return model.URL{}, false, err
+	}
+
+	now := time.Now()
+	for _, link := range candidates {
+		if link.DisabledAt != nil || (link.ExpiresAt != nil && !link.ExpiresAt.After(now)) || !sameSettings(link, want) {
+			continue
+		}
+
+		var linkTags []string
+		if u.tags != nil {
+			if linkTags, err = u.tags.GetByCode(ctx, link.Code); err != nil {
+				return model.URL{}, false, err
+			}
+		}
+		if !sameTags(linkTags, tags) {
+			continue
+		}
+
+		link.Tags = linkTags
+		link.Existing = true
+		return link, true, nil
+	}
+	return model.URL{}, false, nil
+}
+
+func sameSettings(a, b model.URL) bool {
+	return a.Domain == b.Domain && a.Visibility == b.Visibility && a.PasswordHash == b.PasswordHash &&
+		a.MaxClicks == nil && b.MaxClicks == nil &&
+		sameTime(a.ExpiresAt, b.ExpiresAt) && sameTime(a.ActivateAt, b.ActivateAt) &&
+		sameID(a.FolderID, b.FolderID) && sameID(a.WorkspaceID, b.WorkspaceID) &&
+		sameAppLink(a.IOS, b.IOS) && sameAppLink(a.Android, b.Android)
+}
+
+// sameTime compares to the microsecond, the precision the database keeps.
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Truncate(time.Microsecond).Equal(b.Truncate(time.Microsecond))
+}
+
+func sameID(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func sameAppLink(a, b *model.AppLink) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func sameTags(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
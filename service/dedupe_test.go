@@ -0,0 +1,57 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/service"
+	"github.com/Kritvi0208/ShortEdge/urlnorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShorten_Dedupe(t *testing.T) {
+	mock := newMockStore()
+	svc := service.New(mock, service.WithDedupe(true), service.WithTagStore(newMockTagStore()),
+		service.WithNormalizer(urlnorm.New(urlnorm.Config{StripDefaultPort: true})))
+	owner, other := userCtx(7), userCtx(8)
+
+	first, err := svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com/page", Tags: []string{"a", "b"}})
+	require.NoError(t, err)
+	assert.False(t, first.Existing)
+
+	// The same URL in another spelling, with the tags in another order
+	again, err := svc.Shorten(owner, model.ShortenRequest{LongURL: "HTTPS://Example.com:443/page", Tags: []string{"b", "a"}})
+	require.NoError(t, err)
+	assert.Equal(t, first.Code, again.Code)
+	assert.True(t, again.Existing)
+	assert.Len(t, mock.urls, 1)
+
+	expires := time.Now().Add(time.Hour)
+	for name, req := range map[string]model.ShortenRequest{
+		"force_new":   {LongURL: "https://example.com/page", Tags: []string{"a", "b"}, ForceNew: true},
+		"tags":        {LongURL: "https://example.com/page"},
+		"visibility":  {LongURL: "https://example.com/page", Tags: []string{"a", "b"}, Visibility: model.VisibilityUnlisted},
+		"expiry":      {LongURL: "https://example.com/page", Tags: []string{"a", "b"}, ExpiresAt: &expires},
+		"click limit": {LongURL: "https://example.com/page", Tags: []string{"a", "b"}, MaxClicks: ptrInt64(1)},
+		"custom code": {LongURL: "https://example.com/page", Tags: []string{"a", "b"}, CustomCode: "mine"},
+	} {
+		link, err := svc.Shorten(owner, req)
+		require.NoError(t, err, name)
+		assert.NotEqual(t, first.Code, link.Code, name)
+		assert.False(t, link.Existing, name)
+	}
+
+	// Owners don't share links
+	theirs, err := svc.Shorten(other, model.ShortenRequest{LongURL: "https://example.com/page", Tags: []string{"a", "b"}})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Code, theirs.Code)
+
+	// Nor are disabled links handed out again
+	now := time.Now()
+	require.NoError(t, mock.SetDisabled(owner, first.Code, &now, "flagged"))
+	link, err := svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com/page", Tags: []string{"a", "b"}})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Code, link.Code)
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/auth"
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/store"
+)
+
+type DomainService interface {
+	List(ctx context.Context, workspaceID *int64) ([]model.Domain, error)
+	Create(ctx context.Context, req model.DomainRequest) (model.Domain, error)
+	Update(ctx context.Context, id int64, req model.DomainRequest) (model.Domain, error)
+	Delete(ctx context.Context, id int64) error
+	// Verify checks the domain serves its token at model.DomainVerificationPath.
+	Verify(ctx context.Context, id int64) (model.Domain, error)
+
+	// Lookup finds the verified branded domain of a request's Host; false means the default domain.
+	Lookup(ctx context.Context, host string) (model.Domain, bool)
+	// VerificationToken returns the token to serve at model.DomainVerificationPath for host.
+	VerificationToken(ctx context.Context, host string) (string, bool)
+}
+
+type domainService struct {
+	store      store.Domain
+	workspaces store.Workspace
+	client     *http.Client
+	verifyPort string
+	now        func() time.Time
+}
+
+// NewDomainService manages branded domains. Verification fetches the token over plain HTTP from
+// the domain, on verifyPort when it's set so that it can be tried against a local instance.
+func NewDomainService(s store.Domain, workspaces store.Workspace, client *http.Client, verifyPort string) DomainService {
+	return &domainService{store: s, workspaces: workspaces, client: client, verifyPort: verifyPort, now: time.Now}
+}
+
+// normalizeHost lowercases a host name and strips its port and trailing dot.
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+func validDomainName(name string) error {
+	if net.ParseIP(name) != nil {
+		return fmt.Errorf("domain must be a host name, not an IP address")
+	}
+	labels := strings.Split(name, ".")
+	if len(name) > 253 || len(labels) < 2 {
+		return fmt.Errorf("domain must be a host name such as go.example.com")
+	}
+	for _, l := range labels {
+		if l == "" || len(l) > 63 || l[0] == '-' || l[len(l)-1] == '-' ||
+			strings.Trim(l, "abcdefghijklmnopqrstuvwxyz0123456789-") != "" {
+			return fmt.Errorf("domain %q is not a valid host name", name)
+		}
+	}
+	return nil
+}
+
+// validRedirectURL accepts an empty URL, which turns the redirect off, or an http(s) URL.
+func validRedirectURL(field, raw string) error {
+	if raw == "" {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s must be an http(s) URL", field)
+	}
+	return nil
+}
+
+// require checks the caller has at least role min in the workspace.
+func (d *domainService) require(ctx context.Context, workspaceID int64, min string) error {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+
+	role, err := workspaceRole(ctx, d.workspaces, p, workspaceID)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return ErrNotMember
+	}
+	if !hasRole(role, min) {
+		return ErrWorkspaceRole
+	}
+	return nil
+}
+
+// get loads a domain the caller has at least role min for.
+func (d *domainService) get(ctx context.Context, id int64, min string) (model.Domain, error) {
+	domain, err := d.store.GetByID(ctx, id)
+	if err != nil {
+		return model.Domain{}, ErrDomainNotFound
+	}
+	if err := d.require(ctx, domain.WorkspaceID, min); err != nil {
+		return model.Domain{}, err
+	}
+	return domain, nil
+}
+
+// List returns the domains of the caller's workspace, or of workspaceID.
+func (d *domainService) List(ctx context.Context, workspaceID *int64) ([]model.Domain, error) {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	id := p.WorkspaceID
+	if workspaceID != nil {
+		id = *workspaceID
+	}
+	if err := d.require(ctx, id, model.WorkspaceViewer); err != nil {
+		return nil, err
+	}
+
+	return d.store.ListForWorkspace(ctx, id)
+}
+
+// Create registers a domain for a workspace; its admins may do so. The domain stays unusable
+// until it's verified.
+func (d *domainService) Create(ctx context.Context, req model.DomainRequest) (model.Domain, error) {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return model.Domain{}, ErrUnauthenticated
+	}
+
+	workspaceID := p.WorkspaceID
+	if req.WorkspaceID != nil {
+		workspaceID = *req.WorkspaceID
+	}
+	if err := d.require(ctx, workspaceID, model.WorkspaceAdmin); err != nil {
+		return model.Domain{}, err
+	}
+
+	name := normalizeHost(req.Name)
+	if err := validDomainName(name); err != nil {
+		return model.Domain{}, err
+	}
+	if err := validRedirectURL("root_url", req.RootURL); err != nil {
+		return model.Domain{}, err
+	}
+	if err := validRedirectURL("not_found_url", req.NotFoundURL); err != nil {
+		return model.Domain{}, err
+	}
+	if err := validCodeStyle(req.CodeStyle); err != nil {
+		return model.Domain{}, err
+	}
+
+	if _, err := d.store.GetByName(ctx, name); err == nil {
+		return model.Domain{}, fmt.Errorf("domain %s is already registered", name)
+	}
+
+	token, err := auth.NewToken("shortedge-verify-")
+	if err != nil {
+		return model.Domain{}, err
+	}
+
+	return d.store.Create(ctx, model.Domain{
+		Name:        name,
+		WorkspaceID: workspaceID,
+		RootURL:     req.RootURL,
+		NotFoundURL: req.NotFoundURL,
+		CodeStyle:   req.CodeStyle,
+		Token:       token,
+		CreatedAt:   d.now(),
+	})
+}
+
+// Update changes where the bare domain and unknown codes redirect to, and the style of new codes.
+func (d *domainService) Update(ctx context.Context, id int64, req model.DomainRequest) (model.Domain, error) {
+	domain, err := d.get(ctx, id, model.WorkspaceAdmin)
+	if err != nil {
+		return model.Domain{}, err
+	}
+
+	if err := validRedirectURL("root_url", req.RootURL); err != nil {
+		return model.Domain{}, err
+	}
+	if err := validRedirectURL("not_found_url", req.NotFoundURL); err != nil {
+		return model.Domain{}, err
+	}
+	if err := validCodeStyle(req.CodeStyle); err != nil {
+		return model.Domain{}, err
+	}
+
+	domain.RootURL, domain.NotFoundURL, domain.CodeStyle = req.RootURL, req.NotFoundURL, req.CodeStyle
+	return domain, d.store.Update(ctx, domain)
+}
+
+// Delete removes a domain that no links are served from anymore.
+func (d *domainService) Delete(ctx context.Context, id int64) error {
+	domain, err := d.get(ctx, id, model.WorkspaceAdmin)
+	if err != nil {
+		return err
+	}
+
+	used, err := d.store.InUse(ctx, domain.Name)
+	if err != nil {
+		return err
+	}
+	if used {
+		return fmt.Errorf("domain %s still has links; delete them first", domain.Name)
+	}
+
+	return d.store.Delete(ctx, id)
+}
+
+func (d *domainService) Verify(ctx context.Context, id int64) (model.Domain, error) {
+	domain, err := d.get(ctx, id, model.WorkspaceAdmin)
+	if err != nil {
+		return model.Domain{}, err
+	}
+	if domain.VerifiedAt != nil {
+		return domain, nil
+	}
+
+	host := domain.Name
+	if d.verifyPort != "" {
+		host = net.JoinHostPort(host, d.verifyPort)
+	}
+	target := "http://" + host + model.DomainVerificationPath
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return model.Domain{}, err
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return model.Domain{}, fmt.Errorf("verification failed: could not fetch %s: %v", target, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return model.Domain{}, fmt.Errorf("verification failed: could not read %s: %v", target, err)
+	}
+	if resp.StatusCode != http.StatusOK || strings.TrimSpace(string(body)) != domain.Token {
+		return model.Domain{}, fmt.Errorf("verification failed: %s doesn't serve the verification token", target)
+	}
+
+	now := d.now()
+	if err := d.store.MarkVerified(ctx, id, now); err != nil {
+		return model.Domain{}, err
+	}
+	domain.VerifiedAt = &now
+	return domain, nil
+}
+
+func (d *domainService) Lookup(ctx context.Context, host string) (model.Domain, bool) {
+	domain, err := d.store.GetByName(ctx, normalizeHost(host))
+	if err != nil || domain.VerifiedAt == nil {
+		return model.Domain{}, false
+	}
+	return domain, true
+}
+
+func (d *domainService) VerificationToken(ctx context.Context, host string) (string, bool) {
+	domain, err := d.store.GetByName(ctx, normalizeHost(host))
+	if err != nil {
+		return "", false
+	}
+	return domain.Token, true
+}
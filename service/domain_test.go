@@ -0,0 +1,169 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/reqinfo"
+	"github.com/Kritvi0208/ShortEdge/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockDomainStore struct {
+	domains map[int64]model.Domain
+	urls    *mockStore
+}
+
+func (m *mockDomainStore) Create(ctx context.Context, d model.Domain) (model.Domain, error) {
+	d.ID = int64(len(m.domains) + 1)
+	m.domains[d.ID] = d
+	return d, nil
+}
+
+func (m *mockDomainStore) GetByID(ctx context.Context, id int64) (model.Domain, error) {
+	d, ok := m.domains[id]
+	if !ok {
+		return model.Domain{}, sql.ErrNoRows
+	}
+	return d, nil
+}
+
+func (m *mockDomainStore) GetByName(ctx context.Context, name string) (model.Domain, error) {
+	for _, d := range m.domains {
+		if d.Name == name {
+			return d, nil
+		}
+	}
+	return model.Domain{}, sql.ErrNoRows
+}
+
+func (m *mockDomainStore) ListForWorkspace(ctx context.Context, workspaceID int64) ([]model.Domain, error) {
+	var list []model.Domain
+	for _, d := range m.domains {
+		if d.WorkspaceID == workspaceID {
+			list = append(list, d)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockDomainStore) Update(ctx context.Context, d model.Domain) error {
+	m.domains[d.ID] = d
+	return nil
+}
+
+func (m *mockDomainStore) MarkVerified(ctx context.Context, id int64, at time.Time) error {
+	d := m.domains[id]
+	d.VerifiedAt = &at
+	m.domains[id] = d
+	return nil
+}
+
+func (m *mockDomainStore) Delete(ctx context.Context, id int64) error {
+	delete(m.domains, id)
+	return nil
+}
+
+func (m *mockDomainStore) InUse(ctx context.Context, name string) (bool, error) {
+	for _, u := range m.urls.urls {
+		if u.Domain == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// onHost is a visitor request sent to host.
+func onHost(host string) context.Context {
+	return reqinfo.WithInfo(context.Background(), reqinfo.Info{Host: host})
+}
+
+func TestDomains(t *testing.T) {
+	workspaces := newMockWorkspaceStore()
+	team, _ := workspaces.Create(context.Background(), model.Workspace{Name: "team"}, 1)
+	other, _ := workspaces.Create(context.Background(), model.Workspace{Name: "other"}, 9)
+	workspaces.members[team.ID][2] = model.WorkspaceEditor
+	owner := memberCtx(1, team.ID, "owner@example.com")
+	editor := memberCtx(2, team.ID, "editor@example.com")
+
+	urls := newMockStore()
+	domains := &mockDomainStore{domains: make(map[int64]model.Domain), urls: urls}
+
+	// The domain points at ShortEdge, which serves the verification token itself.
+	var domainSvc service.DomainService
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token, ok := domainSvc.VerificationToken(r.Context(), r.Host); ok && r.URL.Path == model.DomainVerificationPath {
+			fmt.Fprint(w, token)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+		},
+	}}
+	domainSvc = service.NewDomainService(domains, workspaces, client, "")
+
+	_, err := domainSvc.Create(owner, model.DomainRequest{Name: "127.0.0.1"})
+	assert.Error(t, err)
+	_, err = domainSvc.Create(editor, model.DomainRequest{Name: "go.acme.com"})
+	assert.ErrorIs(t, err, service.ErrWorkspaceRole)
+
+	acme, err := domainSvc.Create(owner, model.DomainRequest{Name: "Go.Acme.com.", NotFoundURL: "https://acme.com/404"})
+	require.NoError(t, err)
+	assert.Equal(t, "go.acme.com", acme.Name)
+	assert.NotEmpty(t, acme.Token)
+	_, err = domainSvc.Create(memberCtx(9, other.ID, "x@example.com"), model.DomainRequest{Name: "go.acme.com"})
+	assert.Error(t, err, "names are unique")
+
+	svc := service.New(urls, service.WithWorkspaceStore(workspaces), service.WithDomainStore(domains))
+
+	_, err = svc.Shorten(editor, model.ShortenRequest{LongURL: "https://acme.com/sale", CustomCode: "sale", Domain: "go.acme.com"})
+	assert.Error(t, err, "unverified domains can't be used")
+
+	acme, err = domainSvc.Verify(owner, acme.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, acme.VerifiedAt)
+
+	// The same code exists once per domain.
+	branded, err := svc.Shorten(editor, model.ShortenRequest{LongURL: "https://acme.com/sale", CustomCode: "sale", Domain: "go.acme.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "go.acme.com", branded.Domain)
+	_, err = svc.Shorten(editor, model.ShortenRequest{LongURL: "https://example.com/sale", CustomCode: "sale"})
+	require.NoError(t, err)
+	_, err = svc.Shorten(editor, model.ShortenRequest{LongURL: "https://acme.com/x", CustomCode: "sale", Domain: "go.acme.com"})
+	assert.Error(t, err)
+	_, err = svc.Shorten(memberCtx(9, other.ID, "x@example.com"),
+		model.ShortenRequest{LongURL: "https://acme.com/x", Domain: "go.acme.com"})
+	assert.Error(t, err, "domains belong to a workspace")
+
+	link, err := svc.Resolve(onHost("go.acme.com:443"), "sale", "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://acme.com/sale", link.LongURL)
+	link, err = svc.Resolve(onHost("localhost:8080"), "sale", "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/sale", link.LongURL)
+	_, err = svc.Resolve(onHost("localhost:8080"), "sale@go.acme.com", "")
+	assert.ErrorIs(t, err, service.ErrLinkNotFound)
+
+	// Unknown codes on the domain go to its 404 target.
+	_, err = svc.Resolve(onHost("go.acme.com"), "missing", "")
+	var unavailable service.LinkUnavailableError
+	require.ErrorAs(t, err, &unavailable)
+	assert.Equal(t, "https://acme.com/404", unavailable.Fallback)
+	assert.ErrorIs(t, err, service.ErrLinkNotFound)
+
+	assert.Error(t, domainSvc.Delete(owner, acme.ID), "the domain still has links")
+	require.NoError(t, svc.Delete(editor, branded.Code))
+	assert.NoError(t, domainSvc.Delete(owner, acme.ID))
+}
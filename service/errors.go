@@ -0,0 +1,76 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// statusError is an error that gofr's responder turns into the matching HTTP status code.
+type statusError struct {
+	status  int
+	message string
+}
+
+func (e statusError) Error() string {
+	return e.message
+}
+
+func (e statusError) StatusCode() int {
+	return e.status
+}
+
+var (
+	ErrUnauthenticated    = statusError{http.StatusUnauthorized, "authentication required"}
+	ErrInvalidCredentials = statusError{http.StatusUnauthorized, "invalid email or password"}
+	ErrForbidden          = statusError{http.StatusForbidden, "you are not allowed to manage this link"}
+	ErrEmailTaken         = statusError{http.StatusConflict, "email is already registered"}
+	ErrKeyManagement      = statusError{http.StatusForbidden, "managing api keys needs a session or a key with the admin scope"}
+	ErrLinkNotFound       = statusError{http.StatusNotFound, "URL not found"}
+	ErrPrivateLink        = statusError{http.StatusForbidden, "this link is private"}
+	ErrInternalLink       = statusError{http.StatusForbidden, "this link is only available on the internal network"}
+	ErrNotMember          = statusError{http.StatusForbidden, "you are not a member of this workspace"}
+	ErrWorkspaceRole      = statusError{http.StatusForbidden, "your workspace role doesn't allow this"}
+	ErrAdminScope         = statusError{http.StatusForbidden, "only admins can grant the admin scope"}
+	ErrPasswordRequired   = statusError{http.StatusUnauthorized, "this link is password protected"}
+	ErrWrongPassword      = statusError{http.StatusUnauthorized, "wrong password"}
+	ErrPlanChange         = statusError{http.StatusForbidden, "only admins can change a workspace's plan"}
+	ErrDomainNotFound     = statusError{http.StatusNotFound, "domain not found"}
+)
+
+// LinkUnavailableError is returned for links that exist but can't be opened, such as links
+// that used up their clicks. Visitors are sent to Fallback instead when it's set.
+type LinkUnavailableError struct {
+	statusError
+	Fallback string
+}
+
+// Is matches regardless of the fallback, so errors.Is(err, ErrClicksExhausted) works. It also
+// matches the plain error it carries, like ErrLinkNotFound with a domain's 404 target.
+func (e LinkUnavailableError) Is(target error) bool {
+	switch t := target.(type) {
+	case LinkUnavailableError:
+		return t.statusError == e.statusError
+	case statusError:
+		return t == e.statusError
+	}
+	return false
+}
+
+var (
+	ErrClicksExhausted = LinkUnavailableError{statusError: statusError{http.StatusGone, "this link has reached its click limit"}}
+	ErrNotActive       = LinkUnavailableError{statusError: statusError{http.StatusNotFound, "coming soon: this link is not active yet"}}
+	ErrLinkDisabled    = LinkUnavailableError{statusError: statusError{http.StatusGone, "this link was disabled because its destination was flagged as unsafe"}}
+	ErrLinkExpired     = LinkUnavailableError{statusError: statusError{http.StatusGone, "this link has expired"}}
+)
+
+// tooManyAttempts locks a protected link after too many wrong passwords.
+func tooManyAttempts(retryAfter time.Duration) error {
+	return statusError{http.StatusTooManyRequests,
+		fmt.Sprintf("too many wrong passwords, try again in %s", retryAfter.Round(time.Second))}
+}
+
+// ErrMissingScope is returned when an API key lacks the scope a route needs.
+func ErrMissingScope(scope string) error {
+	return statusError{http.StatusForbidden, fmt.Sprintf("api key lacks the %q scope", scope)}
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/store"
+)
+
+type FolderService interface {
+	GetAll(ctx context.Context) ([]model.Folder, error)
+	Create(ctx context.Context, req model.FolderRequest) (model.Folder, error)
+	Update(ctx context.Context, id int64, req model.FolderRequest) (model.Folder, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+type folderService struct {
+	store store.Folder
+}
+
+func NewFolderService(s store.Folder) FolderService {
+	return &folderService{store: s}
+}
+
+func (f *folderService) GetAll(ctx context.Context) ([]model.Folder, error) {
+	return f.store.GetAll(ctx)
+}
+
+func (f *folderService) Create(ctx context.Context, req model.FolderRequest) (model.Folder, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return model.Folder{}, fmt.Errorf("folder name is required")
+	}
+
+	if req.ParentID != nil {
+		if _, err := f.store.GetByID(ctx, *req.ParentID); err != nil {
+			return model.Folder{}, fmt.Errorf("parent folder %d not found", *req.ParentID)
+		}
+	}
+
+	return f.store.Create(ctx, model.Folder{
+		Name:      name,
+		ParentID:  req.ParentID,
+		CreatedAt: time.Now(),
+	})
+}
+
+// Update renames a folder and moves it under req.ParentID. An empty name keeps the current one,
+// a nil parent moves the folder to the top level.
+func (f *folderService) Update(ctx context.Context, id int64, req model.FolderRequest) (model.Folder, error) {
+	existing, err := f.store.GetByID(ctx, id)
+	if err != nil {
+		return model.Folder{}, fmt.Errorf("folder %d not found", id)
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" {
+		existing.Name = name
+	}
+
+	if req.ParentID != nil {
+		// A folder can't be moved below itself or one of its own sub-folders.
+		subtree, err := f.store.Descendants(ctx, id)
+		if err != nil {
+			return model.Folder{}, err
+		}
+		for _, sub := range subtree {
+			if sub == *req.ParentID {
+				return model.Folder{}, fmt.Errorf("folder %d can't be moved into its own sub-folder", id)
+			}
+		}
+
+		if _, err := f.store.GetByID(ctx, *req.ParentID); err != nil {
+			return model.Folder{}, fmt.Errorf("parent folder %d not found", *req.ParentID)
+		}
+	}
+	existing.ParentID = req.ParentID
+
+	if err := f.store.Update(ctx, existing); err != nil {
+		return model.Folder{}, err
+	}
+
+	return existing, nil
+}
+
+func (f *folderService) Delete(ctx context.Context, id int64) error {
+	return f.store.Delete(ctx, id)
+}
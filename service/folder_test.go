@@ -0,0 +1,29 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFolderUpdate_RejectsCycles(t *testing.T) {
+	folders := newMockFolderStore(
+		model.Folder{ID: 1, Name: "root"},
+		model.Folder{ID: 2, Name: "child", ParentID: ptrInt64(1)},
+	)
+	svc := service.NewFolderService(folders)
+
+	_, err := svc.Update(context.Background(), 1, model.FolderRequest{ParentID: ptrInt64(2)})
+	assert.Error(t, err)
+
+	_, err = svc.Update(context.Background(), 1, model.FolderRequest{ParentID: ptrInt64(1)})
+	assert.Error(t, err)
+
+	moved, err := svc.Update(context.Background(), 2, model.FolderRequest{Name: "top"})
+	assert.NoError(t, err)
+	assert.Equal(t, "top", moved.Name)
+	assert.Nil(t, moved.ParentID)
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/store"
+)
+
+// LinkChecker requests a link's destination and reports how it went.
+type LinkChecker interface {
+	Check(ctx context.Context, rawURL string) model.HealthCheck
+}
+
+type HealthService interface {
+	Check(ctx context.Context, code, longURL string) (model.LinkHealth, error)
+	CheckDue(ctx context.Context) (int, error)
+	Report(ctx context.Context, filter model.URLFilter, certWithin time.Duration) (model.HealthReport, error)
+}
+
+// HealthConfig tunes the destination checks.
+type HealthConfig struct {
+	Interval         time.Duration // how often each link is checked
+	Batch            int           // links per CheckDue call, 0 means 100
+	BrokenAfter      int           // consecutive failed checks that mark a link broken, 0 means 3
+	Timeout          time.Duration // per link, 0 means 30s
+	ConcurrentChecks int           // 0 means 8
+}
+
+type healthService struct {
+	store   store.Health
+	links   URLService
+	checker LinkChecker
+	cfg     HealthConfig
+}
+
+// NewHealthService checks destinations with checker. Reports list the links that links' GetAll
+// returns for the caller, with their health attached.
+func NewHealthService(s store.Health, links URLService, checker LinkChecker, cfg HealthConfig) HealthService {
+	if cfg.Batch <= 0 {
+		cfg.Batch = 100
+	}
+	if cfg.BrokenAfter <= 0 {
+		cfg.BrokenAfter = 3
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	if cfg.ConcurrentChecks <= 0 {
+		cfg.ConcurrentChecks = 8
+	}
+
+	return &healthService{store: s, links: links, checker: checker, cfg: cfg}
+}
+
+// Check checks one link's destination and stores the result. A link is broken once BrokenAfter
+// checks in a row failed and stays so until a check succeeds.
+func (h *healthService) Check(ctx context.Context, code, longURL string) (model.LinkHealth, error) {
+	prev, err := h.store.GetByCode(ctx, code)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return model.LinkHealth{}, err
+	}
+
+	health := model.LinkHealth{HealthCheck: h.checker.Check(ctx, longURL), LastOKAt: prev.LastOKAt}
+	switch {
+	case health.OK():
+		health.Status = model.HealthOK
+		lastOK := health.CheckedAt
+		health.LastOKAt = &lastOK
+	case prev.ConsecutiveFailures+1 >= h.cfg.BrokenAfter:
+		health.Status = model.HealthBroken
+		health.ConsecutiveFailures = prev.ConsecutiveFailures + 1
+	default:
+		health.Status = model.HealthFailing
+		health.ConsecutiveFailures = prev.ConsecutiveFailures + 1
+	}
+
+	return health, h.store.Upsert(ctx, code, health)
+}
+
+// CheckDue checks up to one batch of links that weren't checked within the interval, a few at a
+// time, and returns how many were checked.
+func (h *healthService) CheckDue(ctx context.Context) (int, error) {
+	due, err := h.store.ListDue(ctx, time.Now().Add(-h.cfg.Interval), h.cfg.Batch)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		checked int
+	)
+	slots := make(chan struct{}, h.cfg.ConcurrentChecks)
+	for _, link := range due {
+		if ctx.Err() != nil {
+			break
+		}
+
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(link model.URL) {
+			defer func() { <-slots; wg.Done() }()
+
+			checkCtx, cancel := context.WithTimeout(ctx, h.cfg.Timeout)
+			defer cancel()
+
+			if _, err := h.Check(checkCtx, link.Code, link.LongURL); err != nil {
+				log.Printf("health check of %s failed: %v", link.Code, err)
+				return
+			}
+			mu.Lock()
+			checked++
+			mu.Unlock()
+		}(link)
+	}
+	wg.Wait()
+
+	return checked, nil
+}
+
+// Report counts the health states of the links the caller may list and details those that
+// aren't OK or whose certificate expires within certWithin, worst first.
+func (h *healthService) Report(ctx context.Context, filter model.URLFilter, certWithin time.Duration) (model.HealthReport, error) {
+	links, err := h.links.GetAll(ctx, filter)
+	if err != nil {
+		return model.HealthReport{}, err
+	}
+
+	report := model.HealthReport{Links: []model.LinkHealthReport{}}
+	certDeadline := time.Now().Add(certWithin)
+	for _, link := range links {
+		if link.Health == nil {
+			report.Unchecked++
+			continue
+		}
+		report.Checked++
+
+		switch link.Health.Status {
+		case model.HealthOK:
+			report.OK++
+			expires := link.Health.TLSExpiresAt
+			if expires == nil || expires.After(certDeadline) {
+				continue
+			}
+		case model.HealthFailing:
+			report.Failing++
+		case model.HealthBroken:
+			report.Broken++
+		}
+		report.Links = append(report.Links, model.LinkHealthReport{Code: link.Code, LongURL: link.LongURL, Health: *link.Health})
+	}
+
+	severity := map[string]int{model.HealthBroken: 0, model.HealthFailing: 1, model.HealthOK: 2}
+	sort.Slice(report.Links, func(i, j int) bool {
+		a, b := report.Links[i].Health, report.Links[j].Health
+		if severity[a.Status] != severity[b.Status] {
+			return severity[a.Status] < severity[b.Status]
+		}
+		if a.ConsecutiveFailures != b.ConsecutiveFailures {
+			return a.ConsecutiveFailures > b.ConsecutiveFailures
+		}
+		return report.Links[i].Code < report.Links[j].Code
+	})
+	return report, nil
+}
@@ -0,0 +1,140 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/health"
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockHealthStore struct {
+	mu     sync.Mutex // CheckDue checks links concurrently
+	byCode map[string]model.LinkHealth
+	urls   *mockStore
+}
+
+func (m *mockHealthStore) Upsert(ctx context.Context, code string, h model.LinkHealth) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.byCode[code] = h
+	return nil
+}
+
+func (m *mockHealthStore) GetByCode(ctx context.Context, code string) (model.LinkHealth, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.byCode[code]
+	if !ok {
+		return model.LinkHealth{}, sql.ErrNoRows
+	}
+	return h, nil
+}
+
+func (m *mockHealthStore) GetAllByCode(ctx context.Context) (map[string]model.LinkHealth, error) {
+	return m.byCode, nil
+}
+
+func (m *mockHealthStore) ListDue(ctx context.Context, checkedBefore time.Time, limit int) ([]model.URL, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var due []model.URL
+	for _, u := range m.urls.urls {
+		if h, ok := m.byCode[u.Code]; (!ok || h.CheckedAt.Before(checkedBefore)) && len(due) < limit {
+			due = append(due, u)
+		}
+	}
+	return due, nil
+}
+
+func (m *mockHealthStore) Delete(ctx context.Context, code string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.byCode, code)
+	return nil
+}
+
+func TestHealth(t *testing.T) {
+	var up atomic.Bool
+	up.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/flaky" && !up.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		if r.URL.Path == "/gone" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	urls := newMockStore()
+	store := &mockHealthStore{byCode: map[string]model.LinkHealth{}, urls: urls}
+	svc := service.New(urls, service.WithHealthStore(store))
+	owner := userCtx(7)
+	for code, path := range map[string]string{"flaky": "/flaky", "gone": "/gone", "fine": "/"} {
+		_, err := svc.Shorten(owner, model.ShortenRequest{LongURL: srv.URL + path, CustomCode: code})
+		require.NoError(t, err)
+	}
+
+	healthSvc := service.NewHealthService(store, svc, health.NewChecker(health.Config{AllowPrivate: true}),
+		service.HealthConfig{Interval: time.Hour, BrokenAfter: 2})
+
+	n, err := healthSvc.CheckDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	n, err = healthSvc.CheckDue(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n, "checked within the interval")
+
+	assert.Equal(t, model.HealthOK, store.byCode["flaky"].Status)
+	assert.NotNil(t, store.byCode["flaky"].LastOKAt)
+	assert.Equal(t, model.HealthFailing, store.byCode["gone"].Status)
+	assert.Equal(t, http.StatusNotFound, store.byCode["gone"].StatusCode)
+	assert.Equal(t, http.MethodGet, store.byCode["gone"].Method, "HEAD failed, so GET was tried")
+
+	// Broken after two failures in a row, and OK again after one success.
+	up.Store(false)
+	for i := 0; i < 2; i++ {
+		_, err = healthSvc.Check(context.Background(), "flaky", srv.URL+"/flaky")
+		require.NoError(t, err)
+	}
+	h, err := healthSvc.Check(context.Background(), "gone", srv.URL+"/gone")
+	require.NoError(t, err)
+	assert.Equal(t, model.HealthBroken, h.Status)
+	assert.Equal(t, 2, h.ConsecutiveFailures)
+
+	links, err := svc.GetAll(owner, model.URLFilter{})
+	require.NoError(t, err)
+	for _, link := range links {
+		require.NotNil(t, link.Health, link.Code)
+	}
+
+	report, err := healthSvc.Report(owner, model.URLFilter{}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Checked)
+	assert.Equal(t, 1, report.OK)
+	assert.Equal(t, 2, report.Broken)
+	require.Len(t, report.Links, 2)
+	assert.Equal(t, "flaky", report.Links[0].Code)
+	assert.Equal(t, "gone", report.Links[1].Code)
+
+	up.Store(true)
+	h, err = healthSvc.Check(context.Background(), "flaky", srv.URL+"/flaky")
+	require.NoError(t, err)
+	assert.Equal(t, model.HealthOK, h.Status)
+	assert.Zero(t, h.ConsecutiveFailures)
+
+	// A new destination is checked afresh.
+	_, err = svc.Update(owner, "gone", model.ShortenRequest{LongURL: srv.URL + "/"})
+	require.NoError(t, err)
+	assert.NotContains(t, store.byCode, "gone")
+}
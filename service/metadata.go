@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/store"
+)
+
+// MetadataFetcher downloads a destination page and extracts its metadata.
+type MetadataFetcher interface {
+	Fetch(ctx context.Context, rawURL string) (model.LinkMetadata, error)
+}
+
+type MetadataService interface {
+	FetchAsync(code, longURL string)
+	Refresh(ctx context.Context, code, longURL string) (model.LinkMetadata, error)
+	RefreshStale(ctx context.Context) (int, error)
+	GetAllByCode(ctx context.Context) (map[string]model.LinkMetadata, error)
+	GetByCode(ctx context.Context, code string) (model.LinkMetadata, error)
+	Delete(ctx context.Context, code string) error
+}
+
+type metadataService struct {
+	store   store.Metadata
+	fetcher MetadataFetcher
+	maxAge  time.Duration
+	batch   int
+	timeout time.Duration
+	slots   chan struct{} // bounds concurrent background fetches
+}
+
+// NewMetadataService refreshes metadata older than maxAge, batch links per RefreshStale call.
+func NewMetadataService(s store.Metadata, f MetadataFetcher, maxAge time.Duration, batch int) MetadataService {
+	if batch <= 0 {
+		batch = 100
+	}
+
+	return &metadataService{
+		store:   s,
+		fetcher: f,
+		maxAge:  maxAge,
+		batch:   batch,
+		timeout: 30 * time.Second,
+		slots:   make(chan struct{}, 8),
+	}
+}
+
+// FetchAsync fetches in the background, detached from the request that created the link. When
+// too many fetches are in flight the link is skipped; it has no metadata yet, so the next
+// RefreshStale run picks it up.
+func (m *metadataService) FetchAsync(code, longURL string) {
+	select {
+	case m.slots <- struct{}{}:
+	default:
+		return
+	}
+
+	go func() {
+		defer func() { <-m.slots }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+		defer cancel()
+
+		if _, err := m.Refresh(ctx, code, longURL); err != nil {
+			log.Printf("metadata fetch for %s failed: %v", code, err)
+		}
+	}()
+}
+
+// Refresh fetches and stores the metadata of one link. A failed fetch keeps the previously
+// extracted fields and records the error.
+func (m *metadataService) Refresh(ctx context.Context, code, longURL string) (model.LinkMetadata, error) {
+	meta, fetchErr := m.fetcher.Fetch(ctx, longURL)
+	if fetchErr != nil {
+		meta, _ = m.store.GetByCode(ctx, code)
+		meta.Error = fetchErr.Error()
+		meta.FetchedAt = time.Now()
+	}
+
+	if err := m.store.Upsert(ctx, code, meta); err != nil {
+		return meta, err
+	}
+
+	return meta, fetchErr
+}
+
+// RefreshStale re-fetches up to one batch of links with missing or outdated metadata and
+// returns how many were refreshed successfully.
+func (m *metadataService) RefreshStale(ctx context.Context) (int, error) {
+	stale, err := m.store.ListStale(ctx, time.Now().Add(-m.maxAge), m.batch)
+	if err != nil {
+		return 0, err
+	}
+
+	refreshed := 0
+	for _, link := range stale {
+		if ctx.Err() != nil {
+			break
+		}
+
+		fetchCtx, cancel := context.WithTimeout(ctx, m.timeout)
+		_, err := m.Refresh(fetchCtx, link.Code, link.LongURL)
+		cancel()
+
+		if err == nil {
+			refreshed++
+		}
+	}
+
+	return refreshed, nil
+}
+
+func (m *metadataService) GetAllByCode(ctx context.Context) (map[string]model.LinkMetadata, error) {
+	return m.store.GetAllByCode(ctx)
+}
+
+func (m *metadataService) GetByCode(ctx context.Context, code string) (model.LinkMetadata, error) {
+	return m.store.GetByCode(ctx, code)
+}
+
+func (m *metadataService) Delete(ctx context.Context, code string) error {
+	return m.store.Delete(ctx, code)
+}
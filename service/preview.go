@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+)
+
+// Preview describes a link for its preview page without following it. Visibility applies as for
+// redirects, but no click is taken, and the destination of password protected links is withheld.
+func (u *urlService) Preview(ctx context.Context, code string) (model.LinkPreview, error) {
+	key, _, ok := u.hostCode(ctx, code)
+	if !ok {
+		return model.LinkPreview{}, ErrLinkNotFound
+	}
+
+	link, err := u.getLink(ctx, key)
+	if err != nil {
+		return model.LinkPreview{}, ErrLinkNotFound
+	}
+
+	a, err := u.access(ctx)
+	if err != nil {
+		return model.LinkPreview{}, err
+	}
+	if err := u.checkVisible(ctx, a, link); err != nil {
+		return model.LinkPreview{}, err
+	}
+	if link.DisabledAt != nil {
+		return model.LinkPreview{}, ErrLinkDisabled
+	}
+
+	preview := model.LinkPreview{
+		Code:              link.Code,
+		CreatedAt:         link.CreatedAt,
+		PasswordProtected: link.PasswordProtected,
+	}
+
+	if !link.PasswordProtected {
+		preview.LongURL = link.LongURL
+		preview.Safety = safetyOf(link.LongURL)
+
+		if u.metadata != nil {
+			if meta, err := u.metadata.GetByCode(ctx, link.Code); err == nil {
+				preview.Title, preview.Description = meta.Title, meta.Description
+			}
+		}
+	} else {
+		preview.Safety = model.Safety{Status: model.SafetyCaution,
+			Warnings: []string{"the destination is hidden until the link's password is entered"}}
+	}
+
+	if u.workspaces != nil && link.WorkspaceID != nil {
+		if ws, err := u.workspaces.GetByID(ctx, *link.WorkspaceID); err == nil {
+			preview.Workspace = ws.Name
+		}
+	}
+
+	return preview, nil
+}
+
+// safetyOf flags destinations that are common in phishing or that can't be trusted in transit.
+func safetyOf(longURL string) model.Safety {
+	var warnings []string
+
+	u, err := url.Parse(longURL)
+	if err != nil || u.Host == "" {
+		warnings = append(warnings, "the destination is not a valid web address")
+	} else {
+		if u.Scheme != "https" {
+			warnings = append(warnings, "the destination doesn't use HTTPS")
+		}
+		if u.User != nil {
+			warnings = append(warnings, "the destination puts a user name before its host, which can disguise the real site")
+		}
+		host := u.Hostname()
+		if net.ParseIP(host) != nil {
+			warnings = append(warnings, "the destination is an IP address rather than a domain name")
+		}
+		for _, label := range strings.Split(strings.ToLower(host), ".") {
+			if strings.HasPrefix(label, "xn--") {
+				warnings = append(warnings, "the destination's domain uses international characters, which can imitate other domains")
+				break
+			}
+		}
+	}
+
+	if len(warnings) > 0 {
+		return model.Safety{Status: model.SafetyCaution, Warnings: warnings}
+	}
+	return model.Safety{Status: model.SafetyOK}
+}
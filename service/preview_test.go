@@ -0,0 +1,68 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreview(t *testing.T) {
+	mock := newMockStore()
+	svc := service.New(mock)
+	owner := userCtx(7)
+
+	_, err := svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com", CustomCode: "abc+"})
+	assert.Error(t, err, "'+' is reserved for previews")
+
+	_, err = svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com/docs", CustomCode: "docs"})
+	require.NoError(t, err)
+	_, err = svc.Shorten(owner, model.ShortenRequest{LongURL: "http://user@203.0.113.7/login", CustomCode: "sketchy"})
+	require.NoError(t, err)
+	secret := "hunter2"
+	_, err = svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com/secret", CustomCode: "secret",
+		Password: &secret})
+	require.NoError(t, err)
+	_, err = svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com/once", CustomCode: "once",
+		MaxClicks: ptrInt64(1)})
+	require.NoError(t, err)
+
+	preview, err := svc.Preview(context.Background(), "docs")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/docs", preview.LongURL)
+	assert.Equal(t, model.SafetyOK, preview.Safety.Status)
+
+	preview, err = svc.Preview(context.Background(), "sketchy")
+	require.NoError(t, err)
+	assert.Equal(t, model.SafetyCaution, preview.Safety.Status)
+	assert.Len(t, preview.Safety.Warnings, 3)
+
+	preview, err = svc.Preview(context.Background(), "secret")
+	require.NoError(t, err)
+	assert.True(t, preview.PasswordProtected)
+	assert.Empty(t, preview.LongURL)
+
+	// Previewing doesn't use up clicks.
+	_, err = svc.Preview(context.Background(), "once")
+	require.NoError(t, err)
+	_, err = svc.Resolve(context.Background(), "once", "")
+	assert.NoError(t, err)
+
+	_, err = svc.Preview(context.Background(), "missing")
+	assert.ErrorIs(t, err, service.ErrLinkNotFound)
+
+	// Previews are counted apart from clicks.
+	visits := &mockVisitStore{}
+	visitSvc := service.NewVisitService(visits, svc)
+	require.NoError(t, visitSvc.LogVisit(context.Background(), model.Visit{Code: "docs", VisitorKey: "a"}))
+	require.NoError(t, visitSvc.LogPreview(context.Background(), model.Visit{Code: "docs", VisitorKey: "a"}))
+	require.NoError(t, visitSvc.LogPreview(context.Background(), model.Visit{Code: "docs", VisitorKey: "a"}))
+	require.NoError(t, visitSvc.LogPreview(context.Background(), model.Visit{Code: "docs", VisitorKey: "b"}))
+
+	stats, err := visitSvc.PreviewStats(owner, "docs")
+	require.NoError(t, err)
+	assert.Equal(t, model.PreviewStats{Code: "docs", Previews: 3, UniqueVisitors: 2, Clicks: 1}, stats)
+}
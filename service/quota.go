@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/auth"
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/store"
+)
+
+// QuotaService meters workspace usage against the quotas of its plan.
+type QuotaService interface {
+	// ReserveLink counts a new link, failing with a quota error when the plan doesn't allow it.
+	// release undoes the reservation if the link ends up not being created.
+	ReserveLink(ctx context.Context, workspaceID int64, customCode bool) (release func(), err error)
+	// ReleaseLink frees the active-link slot of a deleted link.
+	ReleaseLink(ctx context.Context, workspaceID int64) error
+	// MoveLink moves an active-link slot between workspaces; either side may be nil.
+	MoveLink(ctx context.Context, from, to *int64) error
+	// CountClick reports whether a click may still be tracked this month.
+	CountClick(ctx context.Context, workspaceID int64) (bool, error)
+
+	Usage(ctx context.Context, workspaceID *int64) (model.Usage, error)
+	SetPlan(ctx context.Context, workspaceID int64, req model.PlanRequest) (model.Workspace, error)
+}
+
+// DefaultPlan applies to workspaces without a plan unless configured otherwise. It has no
+// limits, so existing installations keep working after an upgrade.
+const DefaultPlan = "unlimited"
+
+// allTime is the period of counters that never reset.
+var allTime = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// DefaultPlans returns the built-in plans.
+func DefaultPlans() map[string]model.Plan {
+	return map[string]model.Plan{
+		"unlimited": {Name: "unlimited"},
+		"free":      {Name: "free", LinksPerMonth: 100, ActiveLinks: 500, CustomCodesPerMonth: 10, ClicksPerMonth: 10000},
+		"pro":       {Name: "pro", LinksPerMonth: 5000, ActiveLinks: 50000, CustomCodesPerMonth: 1000, ClicksPerMonth: 1000000},
+	}
+}
+
+// LoadPlans reads a JSON array of plans from path on top of the built-in ones; plans with the
+// name of a built-in plan replace it. An empty path returns the built-in plans.
+func LoadPlans(path string) (map[string]model.Plan, error) {
+	plans := DefaultPlans()
+	if path == "" {
+		return plans, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []model.Plan
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("invalid plans file %s: %w", path, err)
+	}
+
+	for _, p := range list {
+		p.Name = strings.ToLower(strings.TrimSpace(p.Name))
+		if p.Name == "" {
+			return nil, fmt.Errorf("invalid plans file %s: every plan needs a name", path)
+		}
+		if p.LinksPerMonth < 0 || p.ActiveLinks < 0 || p.CustomCodesPerMonth < 0 || p.ClicksPerMonth < 0 {
+			return nil, fmt.Errorf("invalid plans file %s: limits of plan %q can't be negative", path, p.Name)
+		}
+		plans[p.Name] = p
+	}
+	return plans, nil
+}
+
+type quotaService struct {
+	usage       store.Usage
+	workspaces  store.Workspace
+	plans       map[string]model.Plan
+	defaultPlan string
+	now         func() time.Time
+}
+
+// NewQuotaService enforces plans on workspaces; defaultPlan applies to workspaces without one.
+func NewQuotaService(usage store.Usage, workspaces store.Workspace, plans map[string]model.Plan, defaultPlan string) (QuotaService, error) {
+	if _, ok := plans[defaultPlan]; !ok {
+		return nil, fmt.Errorf("default plan %q is not defined", defaultPlan)
+	}
+	return &quotaService{usage: usage, workspaces: workspaces, plans: plans, defaultPlan: defaultPlan, now: time.Now}, nil
+}
+
+// period returns the start of the current billing month and when it resets, both in UTC.
+func (q *quotaService) period() (time.Time, time.Time) {
+	now := q.now().UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
+
+func (q *quotaService) plan(ctx context.Context, workspaceID int64) (model.Plan, error) {
+	ws, err := q.workspaces.GetByID(ctx, workspaceID)
+	if err != nil {
+		return model.Plan{}, err
+	}
+	if p, ok := q.plans[ws.Plan]; ok {
+		return p, nil
+	}
+	return q.plans[q.defaultPlan], nil
+}
+
+type counter struct {
+	metric string
+	period time.Time
+	limit  int64
+}
+
+func (q *quotaService) ReserveLink(ctx context.Context, workspaceID int64, customCode bool) (func(), error) {
+	plan, err := q.plan(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	month, resets := q.period()
+	counters := []counter{
+		{model.MetricLinksCreated, month, plan.LinksPerMonth},
+		{model.MetricActiveLinks, allTime, plan.ActiveLinks},
+	}
+	if customCode {
+		counters = append(counters, counter{model.MetricCustomCodes, month, plan.CustomCodesPerMonth})
+	}
+
+	var taken []counter
+	release := func() {
+		// The caller's context may be cancelled already; the counters must be given back anyway
+		for _, c := range taken {
+			_ = q.usage.Decrement(context.Background(), workspaceID, c.metric, c.period)
+		}
+	}
+
+	for _, c := range counters {
+		ok, err := q.usage.Increment(ctx, workspaceID, c.metric, c.period, c.limit)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		if !ok {
+			release()
+			return nil, quotaExceeded(plan, c, resets)
+		}
+		taken = append(taken, c)
+	}
+
+	return release, nil
+}
+
+func (q *quotaService) ReleaseLink(ctx context.Context, workspaceID int64) error {
+	return q.usage.Decrement(ctx, workspaceID, model.MetricActiveLinks, allTime)
+}
+
+func (q *quotaService) MoveLink(ctx context.Context, from, to *int64) error {
+	if from != nil && to != nil && *from == *to {
+		return nil
+	}
+
+	if to != nil {
+		plan, err := q.plan(ctx, *to)
+		if err != nil {
+			return err
+		}
+		c := counter{model.MetricActiveLinks, allTime, plan.ActiveLinks}
+		ok, err := q.usage.Increment(ctx, *to, c.metric, c.period, c.limit)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return quotaExceeded(plan, c, time.Time{})
+		}
+	}
+
+	if from != nil {
+		return q.ReleaseLink(ctx, *from)
+	}
+	return nil
+}
+
+func (q *quotaService) CountClick(ctx context.Context, workspaceID int64) (bool, error) {
+	plan, err := q.plan(ctx, workspaceID)
+	if err != nil {
+		return false, err
+	}
+	month, _ := q.period()
+	return q.usage.Increment(ctx, workspaceID, model.MetricClicks, month, plan.ClicksPerMonth)
+}
+
+// Usage reports the consumption of the caller's workspace, or of workspaceID, against its plan.
+func (q *quotaService) Usage(ctx context.Context, workspaceID *int64) (model.Usage, error) {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return model.Usage{}, ErrUnauthenticated
+	}
+
+	id := p.WorkspaceID
+	if workspaceID != nil {
+		id = *workspaceID
+	}
+	if id == 0 {
+		return model.Usage{}, fmt.Errorf("workspace is required")
+	}
+
+	role, err := workspaceRole(ctx, q.workspaces, p, id)
+	if err != nil {
+		return model.Usage{}, err
+	}
+	if role == "" {
+		return model.Usage{}, ErrNotMember
+	}
+
+	plan, err := q.plan(ctx, id)
+	if err != nil {
+		return model.Usage{}, err
+	}
+
+	month, resets := q.period()
+	usage := model.Usage{WorkspaceID: id, Plan: plan.Name, PeriodStart: month, ResetsAt: resets}
+
+	for _, c := range []counter{
+		{model.MetricLinksCreated, month, plan.LinksPerMonth},
+		{model.MetricActiveLinks, allTime, plan.ActiveLinks},
+		{model.MetricCustomCodes, month, plan.CustomCodesPerMonth},
+		{model.MetricClicks, month, plan.ClicksPerMonth},
+	} {
+		used, err := q.usage.Get(ctx, id, c.metric, c.period)
+		if err != nil {
+			return model.Usage{}, err
+		}
+
+		m := model.UsageMetric{Name: c.metric, Used: used, Limit: c.limit}
+		if c.period.Equal(month) {
+			m.ResetsAt = &resets
+		}
+		usage.Metrics = append(usage.Metrics, m)
+	}
+
+	return usage, nil
+}
+
+// SetPlan changes the plan of a workspace; only platform admins may do so.
+func (q *quotaService) SetPlan(ctx context.Context, workspaceID int64, req model.PlanRequest) (model.Workspace, error) {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return model.Workspace{}, ErrUnauthenticated
+	}
+	if !p.IsAdmin() {
+		return model.Workspace{}, ErrPlanChange
+	}
+
+	name := strings.ToLower(strings.TrimSpace(req.Plan))
+	if _, ok := q.plans[name]; !ok {
+		return model.Workspace{}, fmt.Errorf("unknown plan %q", req.Plan)
+	}
+
+	ws, err := q.workspaces.GetByID(ctx, workspaceID)
+	if err != nil {
+		return model.Workspace{}, fmt.Errorf("workspace not found")
+	}
+
+	if err := q.workspaces.SetPlan(ctx, workspaceID, name); err != nil {
+		return model.Workspace{}, err
+	}
+
+	ws.Plan = name
+	return ws, nil
+}
+
+var quotaNames = map[string]string{
+	model.MetricLinksCreated: "links per month",
+	model.MetricActiveLinks:  "active links",
+	model.MetricCustomCodes:  "custom codes per month",
+	model.MetricClicks:       "tracked clicks per month",
+}
+
+// quotaExceeded explains which quota was hit and, for monthly ones, when it resets.
+func quotaExceeded(plan model.Plan, c counter, resets time.Time) error {
+	msg := fmt.Sprintf("quota exceeded: the %q plan allows %d %s", plan.Name, c.limit, quotaNames[c.metric])
+	if c.period.Equal(allTime) {
+		msg += "; delete links or upgrade the plan"
+	} else {
+		msg += fmt.Sprintf(", resets on %s", resets.Format("2006-01-02"))
+	}
+	return statusError{http.StatusForbidden, msg}
+}
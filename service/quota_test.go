@@ -0,0 +1,169 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/auth"
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockUsageStore struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func newMockUsageStore() *mockUsageStore {
+	return &mockUsageStore{counts: make(map[string]int64)}
+}
+
+func usageKey(workspaceID int64, metric string, period time.Time) string {
+	return fmt.Sprintf("%d/%s/%s", workspaceID, metric, period.Format("2006-01-02"))
+}
+
+func (m *mockUsageStore) Increment(ctx context.Context, workspaceID int64, metric string, period time.Time, limit int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := usageKey(workspaceID, metric, period)
+	if limit > 0 && m.counts[key] >= limit {
+		return false, nil
+	}
+	m.counts[key]++
+	return true, nil
+}
+
+func (m *mockUsageStore) Decrement(ctx context.Context, workspaceID int64, metric string, period time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if key := usageKey(workspaceID, metric, period); m.counts[key] > 0 {
+		m.counts[key]--
+	}
+	return nil
+}
+
+func (m *mockUsageStore) Get(ctx context.Context, workspaceID int64, metric string, period time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counts[usageKey(workspaceID, metric, period)], nil
+}
+
+func usageOf(u model.Usage, metric string) model.UsageMetric {
+	for _, m := range u.Metrics {
+		if m.Name == metric {
+			return m
+		}
+	}
+	return model.UsageMetric{}
+}
+
+func TestQuotas(t *testing.T) {
+	workspaces := newMockWorkspaceStore()
+	team, _ := workspaces.Create(context.Background(), model.Workspace{Name: "team", Plan: "tiny"}, 1)
+
+	plans := service.DefaultPlans()
+	plans["tiny"] = model.Plan{Name: "tiny", LinksPerMonth: 3, ActiveLinks: 2, CustomCodesPerMonth: 1, ClicksPerMonth: 2}
+
+	usage := newMockUsageStore()
+	quotas, err := service.NewQuotaService(usage, workspaces, plans, service.DefaultPlan)
+	require.NoError(t, err)
+
+	svc := service.New(newMockStore(), service.WithWorkspaceStore(workspaces), service.WithQuotaService(quotas))
+	owner := memberCtx(1, team.ID, "owner@example.com")
+
+	_, err = svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com/1", CustomCode: "one"})
+	require.NoError(t, err)
+
+	// Custom codes have their own quota.
+	_, err = svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com/2", CustomCode: "two"})
+	assert.ErrorContains(t, err, "custom codes per month")
+
+	_, err = svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com/2"})
+	require.NoError(t, err)
+
+	_, err = svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com/3"})
+	assert.ErrorContains(t, err, "active links")
+
+	// Deleting frees an active slot but not the monthly creations.
+	require.NoError(t, svc.Delete(owner, "one"))
+	_, err = svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com/3"})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Delete(owner, codeOf(t, svc, owner, "https://example.com/3")))
+	_, err = svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com/4"})
+	assert.ErrorContains(t, err, "links per month")
+
+	report, err := quotas.Usage(owner, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "tiny", report.Plan)
+	assert.Equal(t, model.UsageMetric{Name: model.MetricLinksCreated, Used: 3, Limit: 3, ResetsAt: &report.ResetsAt},
+		usageOf(report, model.MetricLinksCreated))
+	assert.Equal(t, int64(1), usageOf(report, model.MetricActiveLinks).Used)
+	assert.Nil(t, usageOf(report, model.MetricActiveLinks).ResetsAt)
+	assert.Equal(t, 1, report.ResetsAt.Day())
+
+	_, err = quotas.Usage(memberCtx(9, 0, "outsider@example.com"), &team.ID)
+	assert.ErrorIs(t, err, service.ErrNotMember)
+
+	// Clicks beyond the quota are not tracked.
+	for _, want := range []bool{true, true, false} {
+		ok, err := quotas.CountClick(context.Background(), team.ID)
+		require.NoError(t, err)
+		assert.Equal(t, want, ok)
+	}
+
+	_, err = quotas.SetPlan(owner, team.ID, model.PlanRequest{Plan: "pro"})
+	assert.ErrorIs(t, err, service.ErrPlanChange)
+
+	admin := auth.WithPrincipal(context.Background(), auth.Principal{UserID: 5, Role: auth.RoleAdmin})
+	ws, err := quotas.SetPlan(admin, team.ID, model.PlanRequest{Plan: "Pro"})
+	require.NoError(t, err)
+	assert.Equal(t, "pro", ws.Plan)
+
+	_, err = svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com/4"})
+	assert.NoError(t, err)
+}
+
+func TestQuotas_Concurrent(t *testing.T) {
+	workspaces := newMockWorkspaceStore()
+	team, _ := workspaces.Create(context.Background(), model.Workspace{Name: "team", Plan: "free"}, 1)
+
+	quotas, err := service.NewQuotaService(newMockUsageStore(), workspaces, service.DefaultPlans(), service.DefaultPlan)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	reserved := 0
+	for i := 0; i < 150; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := quotas.ReserveLink(context.Background(), team.ID, false); err == nil {
+				mu.Lock()
+				reserved++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 100, reserved)
+}
+
+func codeOf(t *testing.T, svc service.URLService, ctx context.Context, longURL string) string {
+	t.Helper()
+	all, err := svc.GetAll(ctx, model.URLFilter{})
+	require.NoError(t, err)
+	for _, l := range all {
+		if l.LongURL == longURL {
+			return l.Code
+		}
+	}
+	t.Fatalf("no link to %s", longURL)
+	return ""
+}
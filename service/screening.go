@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DestinationScreener vets link destinations, returning an error that explains why one must not
+// be used; screening.Screener implements it.
+type DestinationScreener interface {
+	Screen(ctx context.Context, rawURL string) error
+}
+
+// WithScreener rejects unsafe destinations of new and changed links, and lets RescreenLinks
+// disable links whose destinations were flagged later.
+func WithScreener(s DestinationScreener) Option {
+	return func(u *urlService) { u.screener = s }
+}
+
+// RescreenLinks screens the destinations of every enabled link again, including their targeting
+// rules and A/B variants, and disables the links with a destination that is blocked now, e.g.
+// after the blocklist changed. It returns how many links were disabled.
+func (u *urlService) RescreenLinks(ctx context.Context) (int, error) {
+	if u.screener == nil {
+		return 0, nil
+	}
+
+	all, err := u.store.GetAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	disabled := 0
+	for _, link := range all {
+		if ctx.Err() != nil {
+			break
+		}
+		if link.DisabledAt != nil || (link.ExpiresAt != nil && link.ExpiresAt.Before(now)) {
+			continue
+		}
+
+		reason, err := u.blockedDestination(ctx, link.Code, link.LongURL)
+		if err != nil {
+			return disabled, err
+		}
+		if reason != "" {
+			if err := u.store.SetDisabled(ctx, link.Code, &now, reason); err != nil {
+				return disabled, err
+			}
+			disabled++
+		}
+	}
+	return disabled, nil
+}
+
+// blockedDestination screens every destination of a link and returns why the first blocked one
+// was rejected, or "" when they all pass.
+func (u *urlService) blockedDestination(ctx context.Context, code, longURL string) (string, error) {
+	destinations, err := u.destinations(ctx, code, longURL)
+	if err != nil {
+		return "", err
+	}
+	for _, dest := range destinations {
+		if err := u.screener.Screen(ctx, dest); err != nil {
+			return err.Error(), nil
+		}
+	}
+	return "", nil
+}
+
+// destinations lists every URL a link can send visitors to.
+func (u *urlService) destinations(ctx context.Context, code, longURL string) ([]string, error) {
+	destinations := []string{longURL}
+
+	if u.targeting != nil {
+		rules, err := u.targeting.Rules(ctx, code)
+		if err != nil {
+			return nil, fmt.Errorf("targeting rules of %s: %w", code, err)
+		}
+		for _, r := range rules {
+			destinations = append(destinations, r.LongURL)
+		}
+	}
+
+	if u.variants != nil {
+		variants, err := u.variants.Variants(ctx, code, false)
+		if err != nil {
+			return nil, fmt.Errorf("variants of %s: %w", code, err)
+		}
+		for _, v := range variants {
+			destinations = append(destinations, v.LongURL)
+		}
+	}
+	return destinations, nil
+}
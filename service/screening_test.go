@@ -0,0 +1,78 @@
+package service_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/screening"
+	"github.com/Kritvi0208/ShortEdge/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScreening(t *testing.T) {
+	blocklist := filepath.Join(t.TempDir(), "blocklist.txt")
+	require.NoError(t, os.WriteFile(blocklist, []byte("phish.example\n"), 0o600))
+	screener, err := screening.New(screening.Config{BlocklistFile: blocklist, ReloadEvery: time.Millisecond})
+	require.NoError(t, err)
+
+	mock := newMockStore()
+	targeting := &mockTargetingStore{rules: make(map[string][]model.TargetingRule)}
+	svc := service.New(mock, service.WithScreener(screener), service.WithTargeting(targeting, mockGeo{}))
+	owner := userCtx(7)
+
+	_, err = svc.Shorten(owner, model.ShortenRequest{LongURL: "javascript:alert(document.cookie)"})
+	assert.EqualError(t, err, "long_url was rejected: destination blocked: javascript: URLs are not allowed")
+	_, err = svc.Shorten(owner, model.ShortenRequest{LongURL: "https://login.phish.example/"})
+	assert.ErrorContains(t, err, "blocklist")
+
+	_, err = svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com/a", CustomCode: "a"})
+	require.NoError(t, err)
+	_, err = svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com/b", CustomCode: "b"})
+	require.NoError(t, err)
+	_, err = svc.Update(owner, "a", model.ShortenRequest{LongURL: "data:text/html,hi"})
+	assert.Error(t, err)
+
+	n, err := svc.RescreenLinks(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	// Flagged after creation: the link stops redirecting
+	require.NoError(t, os.WriteFile(blocklist, []byte("example.com/b\n"), 0o600))
+	later := time.Now().Add(time.Second)
+	require.NoError(t, os.Chtimes(blocklist, later, later))
+	time.Sleep(2 * time.Millisecond)
+
+	n, err = svc.RescreenLinks(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.NotNil(t, mock.urls["b"].DisabledAt)
+	assert.Contains(t, mock.urls["b"].DisabledReason, "blocklist")
+
+	_, err = svc.Resolve(context.Background(), "b", "")
+	assert.ErrorIs(t, err, service.ErrLinkDisabled)
+	_, err = svc.Preview(context.Background(), "b")
+	assert.ErrorIs(t, err, service.ErrLinkDisabled)
+	_, err = svc.Resolve(context.Background(), "a", "")
+	assert.NoError(t, err)
+
+	// Pointing it somewhere safe enables it again, but only once its other destinations are
+	// safe too
+	targeting.rules["b"] = []model.TargetingRule{{Code: "b", Countries: []string{"DE"}, LongURL: "https://example.com/b/de"}}
+	link, err := svc.Update(owner, "b", model.ShortenRequest{LongURL: "https://example.org/b"})
+	require.NoError(t, err)
+	assert.NotNil(t, link.DisabledAt)
+	_, err = svc.Resolve(context.Background(), "b", "")
+	assert.ErrorIs(t, err, service.ErrLinkDisabled)
+
+	targeting.rules["b"][0].LongURL = "https://example.org/b/de"
+	link, err = svc.Update(owner, "b", model.ShortenRequest{LongURL: "https://example.org/b"})
+	require.NoError(t, err)
+	assert.Nil(t, link.DisabledAt)
+	_, err = svc.Resolve(context.Background(), "b", "")
+	assert.NoError(t, err)
+}
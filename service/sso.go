@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/auth"
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/store"
+)
+
+// GroupRole grants a workspace role to members of an identity provider group.
+type GroupRole struct {
+	Group       string
+	WorkspaceID int64
+	Role        string
+}
+
+// ClaimMapping describes how the claims of a validated token translate into a user and their rights.
+type ClaimMapping struct {
+	EmailClaim  string   // defaults to "email"
+	GroupsClaim string   // defaults to "groups"
+	AdminGroups []string // members act as platform admins
+	GroupRoles  []GroupRole
+}
+
+// ParseGroupRoles parses "group=workspace_id:role" entries, e.g. "marketing=3:editor".
+func ParseGroupRoles(entries []string) ([]GroupRole, error) {
+	var roles []GroupRole
+	for _, e := range entries {
+		if e = strings.TrimSpace(e); e == "" {
+			continue
+		}
+
+		group, grant, ok := strings.Cut(e, "=")
+		workspace, role, ok2 := strings.Cut(grant, ":")
+		id, err := strconv.ParseInt(strings.TrimSpace(workspace), 10, 64)
+		if !ok || !ok2 || err != nil || strings.TrimSpace(group) == "" {
+			return nil, fmt.Errorf("invalid group role %q, want group=workspace_id:role", e)
+		}
+
+		role, err = normalizeWorkspaceRole(role)
+		if err != nil {
+			return nil, fmt.Errorf("invalid group role %q: %w", e, err)
+		}
+
+		roles = append(roles, GroupRole{Group: strings.TrimSpace(group), WorkspaceID: id, Role: role})
+	}
+	return roles, nil
+}
+
+// SSOService maps the claims of tokens issued by an OIDC provider to local users.
+type SSOService interface {
+	Authenticate(ctx context.Context, claims map[string]any) (auth.Principal, error)
+}
+
+type ssoService struct {
+	users      store.User
+	workspaces store.Workspace
+	mapping    ClaimMapping
+}
+
+func NewSSOService(users store.User, workspaces store.Workspace, mapping ClaimMapping) SSOService {
+	if mapping.EmailClaim == "" {
+		mapping.EmailClaim = "email"
+	}
+	if mapping.GroupsClaim == "" {
+		mapping.GroupsClaim = "groups"
+	}
+	return &ssoService{users: users, workspaces: workspaces, mapping: mapping}
+}
+
+// Authenticate finds the user linked to the token's issuer and subject, linking or creating one
+// by email on first sight, and grants the workspace roles its groups map to. Roles are only ever
+// added or raised; removing access stays a manual step.
+func (s *ssoService) Authenticate(ctx context.Context, claims map[string]any) (auth.Principal, error) {
+	issuer, _ := claims["iss"].(string)
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return auth.Principal{}, ErrUnauthenticated
+	}
+
+	user, err := s.user(ctx, claims, issuer, subject)
+	if err != nil {
+		return auth.Principal{}, err
+	}
+
+	groups := stringList(claims[s.mapping.GroupsClaim])
+	if err := s.grantRoles(ctx, user.ID, groups); err != nil {
+		return auth.Principal{}, err
+	}
+
+	p := userPrincipal(user)
+	for _, g := range s.mapping.AdminGroups {
+		if containsString(groups, g) {
+			p.Role = auth.RoleAdmin
+		}
+	}
+	return p, nil
+}
+
+func (s *ssoService) user(ctx context.Context, claims map[string]any, issuer, subject string) (model.User, error) {
+	user, err := s.users.GetByIdentity(ctx, issuer, subject)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return model.User{}, err
+	}
+
+	email, _ := claims[s.mapping.EmailClaim].(string)
+	email = normalizeEmail(email)
+	if verified, ok := claims["email_verified"].(bool); email == "" || (ok && !verified) {
+		return model.User{}, ErrUnauthenticated
+	}
+
+	user, err = s.users.GetByEmail(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		// No password: the account can only sign in through the provider.
+		user, err = s.users.Create(ctx, model.User{Email: email, Role: auth.RoleUser, CreatedAt: time.Now()})
+	}
+	if err != nil {
+		return model.User{}, err
+	}
+
+	return user, s.users.LinkIdentity(ctx, issuer, subject, user.ID)
+}
+
+func (s *ssoService) grantRoles(ctx context.Context, userID int64, groups []string) error {
+	if len(s.mapping.GroupRoles) == 0 || len(groups) == 0 {
+		return nil
+	}
+
+	roles, err := s.workspaces.Roles(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, gr := range s.mapping.GroupRoles {
+		if !containsString(groups, gr.Group) || hasRole(roles[gr.WorkspaceID], gr.Role) {
+			continue
+		}
+		if err := s.workspaces.AddMember(ctx, gr.WorkspaceID, userID, gr.Role); err != nil {
+			return err
+		}
+		roles[gr.WorkspaceID] = gr.Role
+	}
+	return nil
+}
+
+// stringList reads a claim holding a list of strings, or a single space separated string.
+func stringList(v any) []string {
+	switch v := v.(type) {
+	case []string:
+		return v
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	case string:
+		return strings.Fields(v)
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Kritvi0208/ShortEdge/auth"
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGroupRoles(t *testing.T) {
+	roles, err := service.ParseGroupRoles([]string{"marketing=3:Editor", " ", "eng = 4:viewer"})
+	require.NoError(t, err)
+	assert.Equal(t, []service.GroupRole{
+		{Group: "marketing", WorkspaceID: 3, Role: model.WorkspaceEditor},
+		{Group: "eng", WorkspaceID: 4, Role: model.WorkspaceViewer},
+	}, roles)
+
+	for _, bad := range []string{"marketing", "marketing=x:editor", "marketing=3:boss", "=3:editor"} {
+		_, err := service.ParseGroupRoles([]string{bad})
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestSSOAuthenticate(t *testing.T) {
+	users := newMockUserStore()
+	workspaces := newMockWorkspaceStore()
+	team, _ := workspaces.Create(context.Background(), model.Workspace{Name: "team"}, 99)
+
+	svc := service.NewSSOService(users, workspaces, service.ClaimMapping{
+		AdminGroups: []string{"platform-admins"},
+		GroupRoles: []service.GroupRole{
+			{Group: "marketing", WorkspaceID: team.ID, Role: model.WorkspaceViewer},
+			{Group: "editors", WorkspaceID: team.ID, Role: model.WorkspaceEditor},
+		},
+	})
+	ctx := context.Background()
+
+	claims := map[string]any{
+		"iss":    "https://idp.example.com",
+		"sub":    "abc123",
+		"email":  "Dev@Example.com",
+		"groups": []any{"marketing", "editors"},
+	}
+
+	p, err := svc.Authenticate(ctx, claims)
+	require.NoError(t, err)
+	assert.Equal(t, "dev@example.com", p.Email)
+	assert.Equal(t, auth.RoleUser, p.Role)
+	assert.Equal(t, model.WorkspaceEditor, workspaces.members[team.ID][p.UserID])
+
+	// The identity stays linked when the email changes at the provider.
+	claims["email"] = "renamed@example.com"
+	claims["groups"] = "platform-admins"
+	again, err := svc.Authenticate(ctx, claims)
+	require.NoError(t, err)
+	assert.Equal(t, p.UserID, again.UserID)
+	assert.True(t, again.IsAdmin())
+	assert.Len(t, users.users, 1)
+
+	_, err = svc.Authenticate(ctx, map[string]any{"iss": "x", "sub": "new", "email": "a@example.com",
+		"email_verified": false})
+	assert.ErrorIs(t, err, service.ErrUnauthenticated)
+
+	_, err = svc.Authenticate(ctx, map[string]any{"email": "a@example.com"})
+	assert.ErrorIs(t, err, service.ErrUnauthenticated)
+}
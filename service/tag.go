@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/store"
+)
+
+type TagService interface {
+	GetAll(ctx context.Context) ([]model.Tag, error)
+	Create(ctx context.Context, req model.TagRequest) (model.Tag, error)
+	Rename(ctx context.Context, id int64, req model.TagRequest) (model.Tag, error)
+	Merge(ctx context.Context, targetID int64, req model.MergeTagsRequest) (model.Tag, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+type tagService struct {
+	store store.Tag
+}
+
+func NewTagService(s store.Tag) TagService {
+	return &tagService{store: s}
+}
+
+func (t *tagService) GetAll(ctx context.Context) ([]model.Tag, error) {
+	return t.store.GetAll(ctx)
+}
+
+func (t *tagService) Create(ctx context.Context, req model.TagRequest) (model.Tag, error) {
+	name := normalizeTag(req.Name)
+	if name == "" {
+		return model.Tag{}, fmt.Errorf("tag name is required")
+	}
+
+	return t.store.Create(ctx, name)
+}
+
+func (t *tagService) Rename(ctx context.Context, id int64, req model.TagRequest) (model.Tag, error) {
+	name := normalizeTag(req.Name)
+	if name == "" {
+		return model.Tag{}, fmt.Errorf("tag name is required")
+	}
+
+	tag, err := t.store.GetByID(ctx, id)
+	if err != nil {
+		return model.Tag{}, fmt.Errorf("tag %d not found", id)
+	}
+
+	if err := t.store.Rename(ctx, id, name); err != nil {
+		return model.Tag{}, err
+	}
+
+	tag.Name = name
+	return tag, nil
+}
+
+// Merge folds the source tags into the target tag. Links keep a single copy of the target tag.
+func (t *tagService) Merge(ctx context.Context, targetID int64, req model.MergeTagsRequest) (model.Tag, error) {
+	target, err := t.store.GetByID(ctx, targetID)
+	if err != nil {
+		return model.Tag{}, fmt.Errorf("tag %d not found", targetID)
+	}
+
+	var sources []int64
+	for _, id := range req.SourceIDs {
+		if id == targetID {
+			continue
+		}
+		if _, err := t.store.GetByID(ctx, id); err != nil {
+			return model.Tag{}, fmt.Errorf("tag %d not found", id)
+		}
+		sources = append(sources, id)
+	}
+
+	if len(sources) == 0 {
+		return model.Tag{}, fmt.Errorf("at least one source tag is required")
+	}
+
+	if err := t.store.Merge(ctx, targetID, sources); err != nil {
+		return model.Tag{}, err
+	}
+
+	return target, nil
+}
+
+func (t *tagService) Delete(ctx context.Context, id int64) error {
+	return t.store.Delete(ctx, id)
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Kritvi0208/ShortEdge/geo"
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/reqinfo"
+	"github.com/Kritvi0208/ShortEdge/store"
+	"golang.org/x/text/language"
+)
+
+// maxTargetingRules keeps the per-redirect evaluation cheap.
+const maxTargetingRules = 50
+
+// WithTargeting enables per-link rules choosing destinations by country and language. Country
+// rules need geo to locate visitors.
+func WithTargeting(t store.Targeting, geo geo.Resolver) Option {
+	return func(u *urlService) { u.targeting, u.geo = t, geo }
+}
+
+func (u *urlService) TargetingRules(ctx context.Context, code string) ([]model.TargetingRule, error) {
+	if u.targeting == nil {
+		return nil, fmt.Errorf("targeting is not enabled")
+	}
+
+	link, _, err := u.authorize(ctx, code, model.WorkspaceViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.targeting.Rules(ctx, link.Code)
+}
+
+// SetTargetingRules replaces the link's rules; their order in req is their priority. An empty
+// list removes all rules.
+func (u *urlService) SetTargetingRules(ctx context.Context, code string, req model.TargetingRulesRequest) ([]model.TargetingRule, error) {
+	if u.targeting == nil {
+		return nil, fmt.Errorf("targeting is not enabled")
+	}
+
+	link, _, err := u.authorize(ctx, code, model.WorkspaceEditor)
+	if err != nil {
+		return nil, err
+	}
+	code = link.Code
+
+	if len(req.Rules) > maxTargetingRules {
+		return nil, fmt.Errorf("a link can have at most %d targeting rules", maxTargetingRules)
+	}
+
+	rules := make([]model.TargetingRule, 0, len(req.Rules))
+	for i, r := range req.Rules {
+		rule, err := normalizeRule(r)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		if rule.LongURL, err = u.destination(ctx, fmt.Sprintf("rules[%d].long_url", i), rule.LongURL); err != nil {
+			return nil, err
+		}
+		rule.Priority = i + 1
+		rules = append(rules, rule)
+	}
+
+	return u.targeting.Replace(ctx, code, rules)
+}
+
+func normalizeRule(r model.TargetingRuleRequest) (model.TargetingRule, error) {
+	rule := model.TargetingRule{LongURL: r.LongURL}
+	for _, c := range r.Countries {
+		c = strings.ToUpper(strings.TrimSpace(c))
+		if len(c) != 2 || strings.Trim(c, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			return model.TargetingRule{}, fmt.Errorf("country %q is not an ISO 3166-1 alpha-2 code", c)
+		}
+		rule.Countries = append(rule.Countries, c)
+	}
+
+	for _, l := range r.Languages {
+		tag, err := language.Parse(strings.TrimSpace(l))
+		if err != nil {
+			return model.TargetingRule{}, fmt.Errorf("language %q is not a valid language tag", l)
+		}
+		rule.Languages = append(rule.Languages, tag.String())
+	}
+
+	if len(rule.Countries) == 0 && len(rule.Languages) == 0 {
+		return model.TargetingRule{}, fmt.Errorf("countries or languages are required; the link's own URL is the default")
+	}
+	return rule, nil
+}
+
+// target picks the destination for the visitor from the link's rules, the first matching rule
+// winning. Visitors matching none, or whose country can't be determined, keep the link's URL.
+func (u *urlService) target(ctx context.Context, link model.URL) (model.URL, error) {
+	if u.targeting == nil {
+		return link, nil
+	}
+
+	rules, err := u.targeting.Rules(ctx, link.Code)
+	if err != nil || len(rules) == 0 {
+		return link, err
+	}
+
+	info := reqinfo.FromContext(ctx)
+	accepted, _, _ := language.ParseAcceptLanguage(info.AcceptLanguage)
+
+	country, located := "", false
+	for _, r := range rules {
+		if len(r.Countries) > 0 {
+			// Only look the visitor up once, and only for links with country rules
+			if !located {
+				country, located = u.country(ctx, info), true
+			}
+			if !containsString(r.Countries, country) {
+				continue
+			}
+		}
+		if len(r.Languages) > 0 && !acceptsLanguage(accepted, r.Languages) {
+			continue
+		}
+
+		link.LongURL = r.LongURL
+		link.RuleID = &r.ID
+		return link, nil
+	}
+	return link, nil
+}
+
+func (u *urlService) country(ctx context.Context, info reqinfo.Info) string {
+	if u.geo == nil {
+		return ""
+	}
+	loc, err := u.geo.Lookup(ctx, info.IP)
+	if err != nil {
+		return ""
+	}
+	return loc.CountryCode
+}
+
+// acceptsLanguage reports whether the visitor accepts one of a rule's languages. A rule language
+// without a region, like "de", matches all its regional variants.
+func acceptsLanguage(accepted []language.Tag, languages []string) bool {
+	for _, l := range languages {
+		want, err := language.Parse(l)
+		if err != nil {
+			continue
+		}
+		wantBase, _ := want.Base()
+		general := want == language.Make(wantBase.String())
+
+		for _, tag := range accepted {
+			base, _ := tag.Base()
+			if tag == want || (general && base == wantBase) {
+				return true
+			}
+		}
+	}
+	return false
+}
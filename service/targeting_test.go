@@ -0,0 +1,101 @@
+package service_test
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	"github.com/Kritvi0208/ShortEdge/geo"
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/reqinfo"
+	"github.com/Kritvi0208/ShortEdge/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockTargetingStore struct {
+	rules map[string][]model.TargetingRule
+}
+
+func (m *mockTargetingStore) Rules(ctx context.Context, code string) ([]model.TargetingRule, error) {
+	return m.rules[code], nil
+}
+
+func (m *mockTargetingStore) Replace(ctx context.Context, code string, rules []model.TargetingRule) ([]model.TargetingRule, error) {
+	for i := range rules {
+		rules[i].ID = int64(i + 1)
+		rules[i].Code = code
+	}
+	m.rules[code] = rules
+	return rules, nil
+}
+
+func (m *mockTargetingStore) DeleteAll(ctx context.Context, code string) error {
+	delete(m.rules, code)
+	return nil
+}
+
+type mockGeo map[netip.Addr]string
+
+func (m mockGeo) Lookup(ctx context.Context, ip netip.Addr) (geo.Location, error) {
+	return geo.Location{CountryCode: m[ip]}, nil
+}
+
+func visitor(ip, acceptLanguage string) context.Context {
+	return reqinfo.WithInfo(context.Background(),
+		reqinfo.Info{IP: netip.MustParseAddr(ip), AcceptLanguage: acceptLanguage})
+}
+
+func TestTargetingRules(t *testing.T) {
+	mock := newMockStore()
+	targeting := &mockTargetingStore{rules: make(map[string][]model.TargetingRule)}
+	countries := mockGeo{
+		netip.MustParseAddr("203.0.113.1"): "DE",
+		netip.MustParseAddr("203.0.113.2"): "FR",
+		netip.MustParseAddr("203.0.113.3"): "US",
+	}
+	svc := service.New(mock, service.WithTargeting(targeting, countries))
+	owner := userCtx(7)
+
+	_, err := svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com", CustomCode: "shop"})
+	require.NoError(t, err)
+
+	_, err = svc.SetTargetingRules(owner, "shop", model.TargetingRulesRequest{Rules: []model.TargetingRuleRequest{
+		{LongURL: "https://example.com/x"},
+	}})
+	assert.Error(t, err, "rules need a condition")
+	_, err = svc.SetTargetingRules(owner, "shop", model.TargetingRulesRequest{Rules: []model.TargetingRuleRequest{
+		{Countries: []string{"Germany"}, LongURL: "https://example.com/x"},
+	}})
+	assert.Error(t, err)
+	_, err = svc.SetTargetingRules(userCtx(8), "shop", model.TargetingRulesRequest{})
+	assert.ErrorIs(t, err, service.ErrForbidden)
+
+	rules, err := svc.SetTargetingRules(owner, "shop", model.TargetingRulesRequest{Rules: []model.TargetingRuleRequest{
+		{Countries: []string{"de", "AT"}, Languages: []string{"en"}, LongURL: "https://example.com/de-en"},
+		{Countries: []string{"DE"}, LongURL: "https://example.com/de"},
+		{Languages: []string{"fr"}, LongURL: "https://example.com/fr"},
+		{Languages: []string{"pt-BR"}, LongURL: "https://example.com/br"},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DE", "AT"}, rules[0].Countries)
+	assert.Equal(t, 3, rules[2].Priority)
+
+	for _, tc := range []struct {
+		ctx  context.Context
+		want string
+		rule *int64
+	}{
+		{visitor("203.0.113.1", "en-GB,de;q=0.8"), "https://example.com/de-en", &rules[0].ID},
+		{visitor("203.0.113.1", "de"), "https://example.com/de", &rules[1].ID},
+		{visitor("203.0.113.3", "fr-CA,en;q=0.5"), "https://example.com/fr", &rules[2].ID},
+		{visitor("203.0.113.3", "pt-BR"), "https://example.com/br", &rules[3].ID},
+		{visitor("203.0.113.3", "pt-PT"), "https://example.com", nil},
+		{visitor("203.0.113.2", ""), "https://example.com", nil},
+	} {
+		link, err := svc.Resolve(tc.ctx, "shop", "")
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, link.LongURL)
+		assert.Equal(t, tc.rule, link.RuleID)
+	}
+}
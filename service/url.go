@@ -2,40 +2,218 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/Kritvi0208/ShortEdge/auth"
+	"github.com/Kritvi0208/ShortEdge/geo"
 	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/ratelimit"
+	"github.com/Kritvi0208/ShortEdge/reqinfo"
 	"github.com/Kritvi0208/ShortEdge/store"
-	"math/rand"
+	"log"
+	"net/netip"
 	"strings"
 	"time"
 )
 
 type URLService interface {
-	GetAll(ctx context.Context) ([]model.URL, error)
+	GetAll(ctx context.Context, filter model.URLFilter) ([]model.URL, error)
 	Shorten(ctx context.Context, req model.ShortenRequest) (model.URL, error)
 	GetByCode(ctx context.Context, code string) (model.URL, error)
+	Resolve(ctx context.Context, code, password string) (model.URL, error)
+	Preview(ctx context.Context, code string) (model.LinkPreview, error)
 	Update(ctx context.Context, code string, req model.ShortenRequest) (model.URL, error)
 	Delete(ctx context.Context, code string) error
+	AttachTags(ctx context.Context, code string, tags []string) (model.URL, error)
+	DetachTags(ctx context.Context, code string, tags []string) (model.URL, error)
+	AuthorizeAnalytics(ctx context.Context, code string) error
+	AnalyticsCodes(ctx context.Context, filter model.URLFilter) ([]string, error)
+	Move(ctx context.Context, code string, workspaceID int64) (model.URL, error)
+
+	ScheduleChange(ctx context.Context, code string, req model.ScheduledChangeRequest) (model.ScheduledChange, error)
+	ScheduledChanges(ctx context.Context, code string) ([]model.ScheduledChange, error)
+	CancelChange(ctx context.Context, code string, id int64) error
+	ApplyScheduledChanges(ctx context.Context) (int, error)
+	RescreenLinks(ctx context.Context) (int, error)
+
+	TargetingRules(ctx context.Context, code string) ([]model.TargetingRule, error)
+	SetTargetingRules(ctx context.Context, code string, req model.TargetingRulesRequest) ([]model.TargetingRule, error)
+
+	Variants(ctx context.Context, code string) ([]model.Variant, error)
+	SetVariants(ctx context.Context, code string, req model.VariantsRequest) ([]model.Variant, error)
 }
 
 type urlService struct {
-	store store.URL
+	store      store.URL
+	tags       store.Tag
+	folders    store.Folder
+	metadata   MetadataService
+	internal   []netip.Prefix
+	workspaces store.Workspace
+	quotas     QuotaService
+
+	attempts     ratelimit.Backend
+	attemptLimit ratelimit.Limit
+	exhaustedURL string
+
+	schedule      store.Schedule
+	comingSoonURL string
+
+	targeting store.Targeting
+	geo       geo.Resolver
+
+	variants store.Variant
+
+	domains store.Domain
+
+	health store.Health
+
+	screener   DestinationScreener
+	normalizer URLNormalizer
+
+	dedupe     bool
+	codePolicy CodePolicy
+
+	codes codeStyles
+}
+
+// Option configures optional collaborators of the URL service.
+type Option func(*urlService)
+
+// WithTagStore enables tagging of links.
+func WithTagStore(t store.Tag) Option {
+	return func(u *urlService) { u.tags = t }
+}
+
+// WithFolderStore enables filing links into folders.
+func WithFolderStore(f store.Folder) Option {
+	return func(u *urlService) { u.folders = f }
+}
+
+// WithMetadataService fetches destination metadata for new and changed links.
+func WithMetadataService(m MetadataService) Option {
+	return func(u *urlService) { u.metadata = m }
+}
+
+// WithInternalNetworks sets the client networks that may open internal links.
+func WithInternalNetworks(prefixes []netip.Prefix) Option {
+	return func(u *urlService) { u.internal = prefixes }
+}
+
+// WithWorkspaceStore scopes links to workspaces and their members' roles.
+func WithWorkspaceStore(w store.Workspace) Option {
+	return func(u *urlService) { u.workspaces = w }
+}
+
+// WithQuotaService enforces the plan quotas of workspaces on new and moved links.
+func WithQuotaService(q QuotaService) Option {
+	return func(u *urlService) { u.quotas = q }
+}
+
+// WithPasswordAttempts limits wrong passwords per protected link; once used up, the link can't
+// be unlocked until the bucket refills.
+func WithPasswordAttempts(backend ratelimit.Backend, limit ratelimit.Limit) Option {
+	return func(u *urlService) { u.attempts, u.attemptLimit = backend, limit }
+}
+
+// WithExhaustedURL sends visitors of links that used up their clicks to url rather than
+// answering 410 Gone.
+func WithExhaustedURL(url string) Option {
+	return func(u *urlService) { u.exhaustedURL = url }
 }
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
+// WithScheduleStore enables scheduled destination changes.
+func WithScheduleStore(s store.Schedule) Option {
+	return func(u *urlService) { u.schedule = s }
 }
 
-func New(s store.URL) URLService {
-	return &urlService{store: s}
+// WithComingSoonURL sends visitors of links that aren't active yet to url rather than
+// answering 404.
+func WithComingSoonURL(url string) Option {
+	return func(u *urlService) { u.comingSoonURL = url }
 }
 
-func (u *urlService) GetAll(ctx context.Context) ([]model.URL, error) {
+// WithDomainStore serves links from branded domains, each with its own namespace of codes.
+func WithDomainStore(d store.Domain) Option {
+	return func(u *urlService) { u.domains = d }
+}
+
+// WithHealthStore shows the destination health of links in listings and resets it when their
+// destination changes.
+func WithHealthStore(h store.Health) Option {
+	return func(u *urlService) { u.health = h }
+}
+
+func New(s store.URL, opts ...Option) URLService {
+	u := &urlService{store: s}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
+}
+
+// GetAll lists the links of the caller's workspace, or of filter.WorkspaceID. Anonymous callers
+// and users without a workspace see public links.
+func (u *urlService) GetAll(ctx context.Context, filter model.URLFilter) ([]model.URL, error) {
+	a, err := u.access(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaceID, err := u.listedWorkspace(a, filter.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+
 	all, err := u.store.GetAll(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	all, err = u.applyFilter(ctx, all, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.tags != nil {
+		byCode, err := u.tags.GetAllByCode(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for i := range all {
+			all[i].Tags = byCode[all[i].Code]
+		}
+	}
+
+	if u.metadata != nil {
+		byCode, err := u.metadata.GetAllByCode(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for i := range all {
+			if meta, ok := byCode[all[i].Code]; ok {
+				all[i].Metadata = &meta
+			}
+		}
+	}
+
+	if u.health != nil {
+		byCode, err := u.health.GetAllByCode(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for i := range all {
+			if h, ok := byCode[all[i].Code]; ok {
+				all[i].Health = &h
+			}
+		}
+	}
+
+	if filter.Query != "" {
+		all = search(all, filter.Query)
+	}
+
 	now := time.Now()
 	var valid []model.URL
 
@@ -43,7 +221,8 @@ func (u *urlService) GetAll(ctx context.Context) ([]model.URL, error) {
 		// Include only if:
 		// - There's no expiry (ExpiresAt == nil)
 		// - OR expiry is in the future
-		if url.ExpiresAt == nil || url.ExpiresAt.After(now) {
+		// - The caller may see it in listings
+		if (url.ExpiresAt == nil || url.ExpiresAt.After(now)) && u.listed(ctx, a, url, workspaceID) {
 			valid = append(valid, url)
 		}
 	}
@@ -53,87 +232,984 @@ func (u *urlService) GetAll(ctx context.Context) ([]model.URL, error) {
 
 func (u *urlService) Shorten(ctx context.Context, req model.ShortenRequest) (model.URL, error) {
 	// Validation
-	if req.LongURL == "" {
-		return model.URL{}, fmt.Errorf("long URL is required")
+	longURL, err := u.destination(ctx, "long_url", req.LongURL)
+	if err != nil {
+		return model.URL{}, err
 	}
+	req.LongURL = longURL
 
 	code := req.CustomCode
+	domain := normalizeHost(req.Domain)
 
 	// If custom code provided, check if it exists
 	if code != "" {
-		_, err := u.store.GetByCode(ctx, code)
+		if code, err = u.customCode(code); err != nil {
+			return model.URL{}, err
+		}
+		_, err := u.store.GetByCode(ctx, domainCode(code, domain))
 		if err == nil {
 			return model.URL{}, fmt.Errorf("custom code '%s' already exists", code)
 		}
 	}
 
-	// If no custom code, generate unique code with retries
-	if code == "" {
-		const maxRetries = 5
-		for i := 0; i < maxRetries; i++ {
-			code = generateCode()
-			_, err := u.store.GetByCode(ctx, code)
-			if err != nil {
-				// Not found, safe to use this code
-				break
-			}
-			code = "" // reset to retry
+	if err := u.checkFolder(ctx, req.FolderID); err != nil {
+		return model.URL{}, err
+	}
+
+	a, err := u.access(ctx)
+	if err != nil {
+		return model.URL{}, err
+	}
+
+	workspaceID, err := u.targetWorkspace(a, req.WorkspaceID)
+	if err != nil {
+		return model.URL{}, err
+	}
+
+	if err := u.checkDomain(ctx, domain, workspaceID); err != nil {
+		return model.URL{}, err
+	}
+
+	tags := normalizeTags(req.Tags)
+	if len(tags) > 0 && u.tags == nil {
+		return model.URL{}, fmt.Errorf("tagging is not enabled")
+	}
+
+	visibility, err := normalizeVisibility(req.Visibility)
+	if err != nil {
+		return model.URL{}, err
+	}
+
+	if req.MaxClicks != nil && *req.MaxClicks < 1 {
+		return model.URL{}, fmt.Errorf("max_clicks must be at least 1")
+	}
+
+	ios, err := normalizeAppLink(req.IOS)
+	if err != nil {
+		return model.URL{}, fmt.Errorf("ios: %w", err)
+	}
+	android, err := normalizeAppLink(req.Android)
+	if err != nil {
+		return model.URL{}, fmt.Errorf("android: %w", err)
+	}
+
+	link := model.URL{
+		Domain:      domain,
+		LongURL:     req.LongURL,
+		Visibility:  visibility,
+		CreatedAt:   time.Now(),
+		ExpiresAt:   req.ExpiresAt,
+		FolderID:    req.FolderID,
+		CreatedBy:   req.CreatedBy,
+		WorkspaceID: workspaceID,
+		MaxClicks:   req.MaxClicks,
+		ActivateAt:  req.ActivateAt,
+		IOS:         ios,
+		Android:     android,
+	}
+	link.RemainingClicks = link.MaxClicks
+
+	if a.ok {
+		link.OwnerID = &a.p.UserID
+	}
+
+	// Links with a password or click limit are never shared, and a custom code asks for a new link
+	if u.dedupe && !req.ForceNew && code == "" && (req.Password == nil || *req.Password == "") && req.MaxClicks == nil {
+		if existing, ok, err := u.duplicate(ctx, link, tags); err != nil || ok {
+			return existing, err
 		}
-		if code == "" {
-			return model.URL{}, fmt.Errorf("failed to generate unique short code after %d attempts", maxRetries)
+	}
+
+	if req.Password != nil && *req.Password != "" {
+		if link.PasswordHash, err = hashLinkPassword(*req.Password); err != nil {
+			return model.URL{}, err
 		}
+		link.PasswordProtected = true
 	}
 
-	// Normalize visibility
-	visibility := strings.ToLower(req.Visibility)
-	if visibility != "private" {
-		visibility = "public"
+	if u.quotas != nil && workspaceID != nil {
+		release, err := u.quotas.ReserveLink(ctx, *workspaceID, req.CustomCode != "")
+		if err != nil {
+			return model.URL{}, err
+		}
+		if err := u.insert(ctx, &link, code); err != nil {
+			release()
+			return link, err
+		}
+	} else if err := u.insert(ctx, &link, code); err != nil {
+		return link, err
 	}
-	link := model.URL{
-		Code:       code,
-		LongURL:    req.LongURL,
-		Visibility: visibility,
-		CreatedAt:  time.Now(),
-		ExpiresAt:  req.ExpiresAt, 
+
+	if u.metadata != nil {
+		u.metadata.FetchAsync(link.Code, link.LongURL)
 	}
 
-	err := u.store.Create(ctx, link)
-	return link, err
+	if len(tags) > 0 {
+		return u.attachTags(ctx, link, tags)
+	}
+
+	return link, nil
 }
 
+// GetByCode loads a link by its stored code, enforcing its visibility for the caller like
+// Preview does, so private and internal links can't be probed through it.
 func (u *urlService) GetByCode(ctx context.Context, code string) (model.URL, error) {
-	return u.store.GetByCode(ctx, code)
+	link, err := u.getLink(ctx, code)
+	if err != nil {
+		return model.URL{}, ErrLinkNotFound
+	}
+
+	a, err := u.access(ctx)
+	if err != nil {
+		return model.URL{}, err
+	}
+	if err := u.checkVisible(ctx, a, link); err != nil {
+		return model.URL{}, err
+	}
+	return link, nil
+}
+
+// getLink loads a link by its stored code. When the code policy stores custom codes in
+// lowercase, codes typed in another case find them too; exact matches come first, as generated
+// codes keep their case.
+func (u *urlService) getLink(ctx context.Context, code string) (model.URL, error) {
+	link, err := u.store.GetByCode(ctx, code)
+	if err != nil && u.codePolicy != nil {
+		if folded := u.codePolicy.Fold(code); folded != code {
+			return u.store.GetByCode(ctx, folded)
+		}
+	}
+	return link, err
+}
+
+// Resolve loads a link for redirecting, enforcing its visibility for the caller and its
+// password, if it has one.
+func (u *urlService) Resolve(ctx context.Context, code, password string) (model.URL, error) {
+	code, domain, ok := u.hostCode(ctx, code)
+	if !ok {
+		return model.URL{}, ErrLinkNotFound
+	}
+
+	link, err := u.getLink(ctx, code)
+	if err != nil {
+		if domain.NotFoundURL != "" {
+			return model.URL{}, LinkUnavailableError{statusError: ErrLinkNotFound, Fallback: domain.NotFoundURL}
+		}
+		return model.URL{}, ErrLinkNotFound
+	}
+	code = link.Code
+
+	a, err := u.access(ctx)
+	if err != nil {
+		return model.URL{}, err
+	}
+
+	if err := u.checkVisible(ctx, a, link); err != nil {
+		return model.URL{}, err
+	}
+
+	if link.DisabledAt != nil {
+		return model.URL{}, ErrLinkDisabled
+	}
+
+	// Both are checked before a click is taken, so they don't use up click-limited links
+	now := time.Now()
+	if link.ExpiresAt != nil && now.After(*link.ExpiresAt) {
+		return model.URL{}, ErrLinkExpired
+	}
+	if link.ActivateAt != nil && now.Before(*link.ActivateAt) {
+		e := ErrNotActive
+		e.Fallback = u.comingSoonURL
+		return model.URL{}, e
+	}
+
+	if u.schedule != nil {
+		applied, err := u.schedule.ApplyDue(ctx, code, now)
+		if err != nil {
+			return model.URL{}, err
+		}
+		if longURL, ok := applied[code]; ok {
+			link.LongURL = longURL
+			u.destinationChanged(ctx, code, longURL)
+		}
+	}
+
+	if err := u.checkPassword(ctx, link, password); err != nil {
+		return model.URL{}, err
+	}
+
+	link, err = u.target(ctx, link)
+	if err != nil {
+		return model.URL{}, err
+	}
+
+	link, err = u.rotate(ctx, link)
+	if err != nil {
+		return model.URL{}, err
+	}
+
+	// The click is taken last, so errors above don't use up clicks of limited links
+	if link.MaxClicks != nil {
+		remaining, ok, err := u.store.TakeClick(ctx, code)
+		if err != nil {
+			return model.URL{}, err
+		}
+		if !ok {
+			e := ErrClicksExhausted
+			e.Fallback = u.exhaustedURL
+			return model.URL{}, e
+		}
+		link.RemainingClicks = &remaining
+	}
+
+	return openInApp(ctx, link), nil
+}
+
+// hashLinkPassword hashes a link's new password, rejecting ones too long for bcrypt.
+func hashLinkPassword(password string) (string, error) {
+	if len(password) > auth.MaxPasswordLength {
+		return "", invalidField("password", fmt.Sprintf("must be at most %d bytes", auth.MaxPasswordLength))
+	}
+	return auth.HashPassword(password)
+}
+
+// checkPassword verifies the password of a protected link. Only wrong passwords count against
+// the attempt limit, so visitors who know it aren't locked out by each other.
+func (u *urlService) checkPassword(ctx context.Context, link model.URL, password string) error {
+	if link.PasswordHash == "" {
+		return nil
+	}
+	if password == "" {
+		return ErrPasswordRequired
+	}
+
+	// Every attempt takes a token before the password is checked, so parallel guesses can't
+	// all get past the limit; correct passwords give theirs back.
+	key := "link-password:" + link.Code
+	taken := false
+	if u.attempts != nil && u.attemptLimit.Enabled() {
+		// Like the request rate limiter, fail open if the backend is unavailable
+		res, err := u.attempts.Allow(ctx, key, u.attemptLimit)
+		if err == nil && !res.Allowed {
+			return tooManyAttempts(res.RetryAfter)
+		}
+		taken = err == nil
+	}
+
+	if auth.VerifyPassword(link.PasswordHash, password) {
+		if taken {
+			_ = u.attempts.Refund(ctx, key, u.attemptLimit)
+		}
+		return nil
+	}
+	return ErrWrongPassword
 }
 
 func (u *urlService) Update(ctx context.Context, code string, req model.ShortenRequest) (model.URL, error) {
-	existing, err := u.store.GetByCode(ctx, code)
+	existing, _, err := u.authorize(ctx, code, model.WorkspaceEditor)
 	if err != nil {
-		return model.URL{}, fmt.Errorf("short code not found")
+		return model.URL{}, err
+	}
+	code = existing.Code
+
+	// A nil folder keeps the current one, 0 takes the link out of its folder
+	folderID := existing.FolderID
+	if req.FolderID != nil {
+		folderID = nil
+		if *req.FolderID != 0 {
+			if err := u.checkFolder(ctx, req.FolderID); err != nil {
+				return model.URL{}, err
+			}
+			folderID = req.FolderID
+		}
+	}
+
+	// Codes can't be changed, but clients may send the current one back. Codes from before the
+	// policy changed are accepted as they are.
+	if req.CustomCode != "" && domainCode(req.CustomCode, existing.Domain) != existing.Code {
+		custom, err := u.customCode(req.CustomCode)
+		if err != nil {
+			return model.URL{}, err
+		}
+		if domainCode(custom, existing.Domain) != existing.Code {
+			return model.URL{}, invalidField("custom_code", "can't be changed, create a new link instead")
+		}
+	}
+
+	// Screening the destination even when it didn't change lets a fixed link be enabled again
+	if req.LongURL, err = u.destination(ctx, "long_url", req.LongURL); err != nil {
+		return model.URL{}, err
 	}
 
+	// An empty visibility keeps the current one
+	visibility := existing.Visibility
+	if req.Visibility != "" {
+		if visibility, err = normalizeVisibility(req.Visibility); err != nil {
+			return model.URL{}, err
+		}
+	}
+
+	destinationChanged := existing.LongURL != req.LongURL
+
 	// Update fields
 	existing.LongURL = req.LongURL
-	existing.Visibility = req.Visibility
+	existing.Visibility = visibility
 	existing.CreatedAt = time.Now()
+	existing.FolderID = folderID
+	if req.ActivateAt != nil {
+		existing.ActivateAt = req.ActivateAt
+	}
+	if req.IOS != nil {
+		if existing.IOS, err = normalizeAppLink(req.IOS); err != nil {
+			return model.URL{}, fmt.Errorf("ios: %w", err)
+		}
+	}
+	if req.Android != nil {
+		if existing.Android, err = normalizeAppLink(req.Android); err != nil {
+			return model.URL{}, fmt.Errorf("android: %w", err)
+		}
+	}
+
+	if req.MaxClicks != nil && *req.MaxClicks < 0 {
+		return model.URL{}, fmt.Errorf("max_clicks can't be negative")
+	}
+
+	// A nil password keeps the current one, an empty one removes it
+	if req.Password != nil {
+		existing.PasswordHash = ""
+		if *req.Password != "" {
+			if existing.PasswordHash, err = hashLinkPassword(*req.Password); err != nil {
+				return model.URL{}, err
+			}
+		}
+		existing.PasswordProtected = existing.PasswordHash != ""
+	}
 
 	err = u.store.Update(ctx, code, existing)
 	if err != nil {
 		return model.URL{}, err
 	}
 
+	// Click limits change separately from the rest of the link so that redirects running
+	// meanwhile aren't lost; 0 removes the limit
+	if req.MaxClicks != nil {
+		existing.MaxClicks = req.MaxClicks
+		if *req.MaxClicks == 0 {
+			existing.MaxClicks = nil
+		}
+		if existing.RemainingClicks, err = u.store.SetMaxClicks(ctx, code, existing.MaxClicks); err != nil {
+			return model.URL{}, err
+		}
+	}
+
+	// Disabled links come back once none of their destinations is blocked, including targeting
+	// rules and variants, which this update didn't touch
+	if existing.DisabledAt != nil && u.screener != nil {
+		reason, err := u.blockedDestination(ctx, code, existing.LongURL)
+		if err != nil {
+			return model.URL{}, err
+		}
+		if reason == "" {
+			if err := u.store.SetDisabled(ctx, code, nil, ""); err != nil {
+				return model.URL{}, err
+			}
+			existing.DisabledAt, existing.DisabledReason = nil, ""
+		} else if reason != existing.DisabledReason {
+			if err := u.store.SetDisabled(ctx, code, existing.DisabledAt, reason); err != nil {
+				return model.URL{}, err
+			}
+			existing.DisabledReason = reason
+		}
+	}
+
+	if destinationChanged {
+		u.destinationChanged(ctx, code, existing.LongURL)
+	}
+
 	return existing, nil
 }
 
 func (u *urlService) Delete(ctx context.Context, code string) error {
-	return u.store.Delete(ctx, code)
+	link, _, err := u.authorize(ctx, code, model.WorkspaceEditor)
+	if err != nil {
+		return err
+	}
+	code = link.Code
+
+	if err := u.store.Delete(ctx, code); err != nil {
+		return err
+	}
+
+	if u.quotas != nil && link.WorkspaceID != nil {
+		if err := u.quotas.ReleaseLink(ctx, *link.WorkspaceID); err != nil {
+			return err
+		}
+	}
+
+	if u.tags != nil {
+		if err := u.tags.DetachAll(ctx, code); err != nil {
+			return err
+		}
+	}
+
+	if u.schedule != nil {
+		if err := u.schedule.DeleteAll(ctx, code); err != nil {
+			return err
+		}
+	}
+
+	if u.targeting != nil {
+		if err := u.targeting.DeleteAll(ctx, code); err != nil {
+			return err
+		}
+	}
+
+	if u.variants != nil {
+		if err := u.variants.DeleteAll(ctx, code); err != nil {
+			return err
+		}
+	}
+
+	if u.health != nil {
+		if err := u.health.Delete(ctx, code); err != nil {
+			return err
+		}
+	}
+
+	if u.metadata != nil {
+		return u.metadata.Delete(ctx, code)
+	}
+
+	return nil
+}
+
+// AttachTags adds tags to a link in a single transaction, creating tags that don't exist yet.
+func (u *urlService) AttachTags(ctx context.Context, code string, tags []string) (model.URL, error) {
+	if u.tags == nil {
+		return model.URL{}, fmt.Errorf("tagging is not enabled")
+	}
+
+	link, _, err := u.authorize(ctx, code, model.WorkspaceEditor)
+	if err != nil {
+		return model.URL{}, err
+	}
+	code = link.Code
+
+	names := normalizeTags(tags)
+	if len(names) == 0 {
+		return model.URL{}, fmt.Errorf("at least one tag is required")
+	}
+
+	return u.attachTags(ctx, link, names)
+}
+
+func (u *urlService) attachTags(ctx context.Context, link model.URL, names []string) (model.URL, error) {
+	if err := u.tags.Attach(ctx, link.Code, names); err != nil {
+		return model.URL{}, err
+	}
+
+	var err error
+	link.Tags, err = u.tags.GetByCode(ctx, link.Code)
+	return link, err
+}
+
+// DetachTags removes tags from a link; tags the link doesn't carry are ignored.
+func (u *urlService) DetachTags(ctx context.Context, code string, tags []string) (model.URL, error) {
+	if u.tags == nil {
+		return model.URL{}, fmt.Errorf("tagging is not enabled")
+	}
+
+	link, _, err := u.authorize(ctx, code, model.WorkspaceEditor)
+	if err != nil {
+		return model.URL{}, err
+	}
+	code = link.Code
+
+	if err := u.tags.Detach(ctx, code, normalizeTags(tags)); err != nil {
+		return model.URL{}, err
+	}
+
+	link.Tags, err = u.tags.GetByCode(ctx, code)
+	return link, err
+}
+
+// AuthorizeAnalytics checks that the caller may read the visit logs of a link: they must be a
+// member of its workspace and be allowed to open it.
+func (u *urlService) AuthorizeAnalytics(ctx context.Context, code string) error {
+	link, a, err := u.authorize(ctx, code, model.WorkspaceViewer)
+	if err != nil {
+		return err
+	}
+	return u.checkVisible(ctx, a, link)
+}
+
+// AnalyticsCodes returns the codes of links matching filter whose analytics the caller may read.
+func (u *urlService) AnalyticsCodes(ctx context.Context, filter model.URLFilter) ([]string, error) {
+	a, err := u.access(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !a.ok {
+		return nil, ErrUnauthenticated
+	}
+
+	links, err := u.GetAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	for _, l := range links {
+		if hasRole(a.role(l), model.WorkspaceViewer) {
+			codes = append(codes, l.Code)
+		}
+	}
+	return codes, nil
+}
+
+// Move transfers a link to another workspace. The code stays the same, so its analytics move
+// along with it.
+func (u *urlService) Move(ctx context.Context, code string, workspaceID int64) (model.URL, error) {
+	if u.workspaces == nil {
+		return model.URL{}, fmt.Errorf("workspaces are not enabled")
+	}
+
+	link, a, err := u.authorize(ctx, code, model.WorkspaceEditor)
+	if err != nil {
+		return model.URL{}, err
+	}
+	code = link.Code
+
+	target, err := u.targetWorkspace(a, &workspaceID)
+	if err != nil {
+		return model.URL{}, err
+	}
+	if link.Domain != "" {
+		if err := u.checkDomain(ctx, link.Domain, target); err != nil {
+			return model.URL{}, fmt.Errorf("links on %s can't leave the domain's workspace", link.Domain)
+		}
+	}
+
+	if u.quotas != nil {
+		if err := u.quotas.MoveLink(ctx, link.WorkspaceID, target); err != nil {
+			return model.URL{}, err
+		}
+	}
+
+	source := link.WorkspaceID
+	link.WorkspaceID = target
+	if err := u.store.Update(ctx, code, link); err != nil {
+		if u.quotas != nil {
+			_ = u.quotas.MoveLink(ctx, target, source)
+		}
+		return model.URL{}, err
+	}
+
+	return link, nil
+}
+
+// access holds what the caller may do with links, resolved once per operation.
+type access struct {
+	p     auth.Principal
+	ok    bool             // false for anonymous callers
+	roles map[int64]string // the caller's workspace roles
+}
+
+func (u *urlService) access(ctx context.Context) (access, error) {
+	p, ok := auth.FromContext(ctx)
+	a := access{p: p, ok: ok}
+	if !ok || p.IsAdmin() || u.workspaces == nil {
+		return a, nil
+	}
+
+	var err error
+	a.roles, err = u.workspaces.Roles(ctx, p.UserID)
+	return a, err
+}
+
+// roleIn returns the caller's role in a workspace; platform admins act as owners everywhere.
+func (a access) roleIn(workspaceID int64) string {
+	if !a.ok {
+		return ""
+	}
+	if a.p.IsAdmin() {
+		return model.WorkspaceOwner
+	}
+	return a.roles[workspaceID]
+}
+
+// role returns the caller's role for a link. Links outside any workspace belong to their
+// creator alone; anonymous ones only to admins.
+func (a access) role(link model.URL) string {
+	if link.WorkspaceID != nil {
+		return a.roleIn(*link.WorkspaceID)
+	}
+	if a.p.IsAdmin() || (a.ok && link.OwnerID != nil && *link.OwnerID == a.p.UserID) {
+		return model.WorkspaceOwner
+	}
+	return ""
+}
+
+// authorize loads a link the caller holds at least role min for. Codes are looked up like on
+// redirects, so callers should use the link's Code rather than the one they were given.
+func (u *urlService) authorize(ctx context.Context, code string, min string) (model.URL, access, error) {
+	link, err := u.getLink(ctx, code)
+	if err != nil {
+		return model.URL{}, access{}, fmt.Errorf("short code not found")
+	}
+
+	a, err := u.access(ctx)
+	if err != nil {
+		return model.URL{}, access{}, err
+	}
+	if !a.ok {
+		return model.URL{}, access{}, ErrUnauthenticated
+	}
+	if !hasRole(a.role(link), min) {
+		return model.URL{}, access{}, ErrForbidden
+	}
+
+	return link, a, nil
+}
+
+// listedWorkspace picks the workspace a listing covers: the requested one, else the caller's
+// default. It returns nil when listing isn't scoped to a workspace.
+func (u *urlService) listedWorkspace(a access, requested *int64) (*int64, error) {
+	if requested == nil {
+		if !a.ok || a.p.WorkspaceID == 0 || u.workspaces == nil {
+			return nil, nil
+		}
+		id := a.p.WorkspaceID
+		requested = &id
+	}
+
+	if !a.ok {
+		return nil, ErrUnauthenticated
+	}
+	if a.roleIn(*requested) == "" {
+		return nil, ErrNotMember
+	}
+	return requested, nil
+}
+
+// targetWorkspace picks the workspace new or moved links go to and checks the caller may add
+// links there. Anonymous links belong to no workspace.
+func (u *urlService) targetWorkspace(a access, requested *int64) (*int64, error) {
+	if !a.ok {
+		if requested != nil {
+			return nil, ErrUnauthenticated
+		}
+		return nil, nil
+	}
+	if u.workspaces == nil {
+		if requested != nil {
+			return nil, fmt.Errorf("workspaces are not enabled")
+		}
+		return nil, nil
+	}
+
+	if requested == nil {
+		if a.p.WorkspaceID == 0 {
+			return nil, nil
+		}
+		id := a.p.WorkspaceID
+		requested = &id
+	}
+
+	switch role := a.roleIn(*requested); {
+	case role == "":
+		return nil, ErrNotMember
+	case !hasRole(role, model.WorkspaceEditor):
+		return nil, ErrWorkspaceRole
+	}
+	return requested, nil
+}
+
+// checkVisible enforces a link's visibility: private links only open for members of its
+// workspace and internal links only from the internal networks.
+func (u *urlService) checkVisible(ctx context.Context, a access, link model.URL) error {
+	switch visibilityOf(link) {
+	case model.VisibilityPrivate:
+		if !a.ok {
+			return ErrUnauthenticated
+		}
+		if a.role(link) == "" {
+			return ErrPrivateLink
+		}
+	case model.VisibilityInternal:
+		if !reqinfo.InPrefixes(reqinfo.FromContext(ctx).IP, u.internal) {
+			return ErrInternalLink
+		}
+	}
+	return nil
+}
+
+// listed reports whether a link shows up in the caller's listing. Workspace listings hold every
+// link of the workspace the caller can open; otherwise callers see their own links and public ones.
+func (u *urlService) listed(ctx context.Context, a access, link model.URL, workspaceID *int64) bool {
+	if workspaceID != nil {
+		return link.WorkspaceID != nil && *link.WorkspaceID == *workspaceID && u.checkVisible(ctx, a, link) == nil
+	}
+	if a.role(link) != "" {
+		return u.checkVisible(ctx, a, link) == nil
+	}
+	return visibilityOf(link) == model.VisibilityPublic
+}
+
+// visibilityOf treats links stored before visibility modes existed as public.
+func visibilityOf(link model.URL) string {
+	switch link.Visibility {
+	case model.VisibilityUnlisted, model.VisibilityPrivate, model.VisibilityInternal:
+		return link.Visibility
+	}
+	return model.VisibilityPublic
+}
+
+func normalizeVisibility(v string) (string, error) {
+	switch v = strings.ToLower(strings.TrimSpace(v)); v {
+	case "":
+		return model.VisibilityPublic, nil
+	case model.VisibilityPublic, model.VisibilityUnlisted, model.VisibilityPrivate, model.VisibilityInternal:
+		return v, nil
+	}
+	return "", fmt.Errorf("visibility must be public, unlisted, private or internal")
+}
+
+// ScheduleChange switches the link to a new destination at req.At.
+func (u *urlService) ScheduleChange(ctx context.Context, code string, req model.ScheduledChangeRequest) (model.ScheduledChange, error) {
+	if u.schedule == nil {
+		return model.ScheduledChange{}, fmt.Errorf("scheduled changes are not enabled")
+	}
+
+	link, _, err := u.authorize(ctx, code, model.WorkspaceEditor)
+	if err != nil {
+		return model.ScheduledChange{}, err
+	}
+	code = link.Code
+
+	longURL, err := u.destination(ctx, "long_url", req.LongURL)
+	if err != nil {
+		return model.ScheduledChange{}, err
+	}
+
+	now := time.Now()
+	if !req.At.After(now) {
+		return model.ScheduledChange{}, fmt.Errorf("at must be in the future")
+	}
+
+	return u.schedule.Create(ctx, model.ScheduledChange{Code: code, LongURL: longURL, At: req.At, CreatedAt: now})
 }
 
-func generateCode() string {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	const length = 6
-	b := make([]byte, length)
-	for i := range b {
-		b[i] = charset[rand.Intn(len(charset))]
+// ScheduledChanges lists the link's pending changes, earliest first.
+func (u *urlService) ScheduledChanges(ctx context.Context, code string) ([]model.ScheduledChange, error) {
+	if u.schedule == nil {
+		return nil, fmt.Errorf("scheduled changes are not enabled")
+	}
+
+	link, _, err := u.authorize(ctx, code, model.WorkspaceViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.schedule.Pending(ctx, link.Code)
+}
+
+func (u *urlService) CancelChange(ctx context.Context, code string, id int64) error {
+	if u.schedule == nil {
+		return fmt.Errorf("scheduled changes are not enabled")
+	}
+
+	link, _, err := u.authorize(ctx, code, model.WorkspaceEditor)
+	if err != nil {
+		return err
+	}
+	code = link.Code
+
+	if err := u.schedule.Cancel(ctx, code, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("scheduled change not found")
+		}
+		return err
+	}
+	return nil
+}
+
+// ApplyScheduledChanges applies every due change, so listings are up to date even for links
+// nobody opened since their cut-over. Redirects apply their link's changes themselves.
+func (u *urlService) ApplyScheduledChanges(ctx context.Context) (int, error) {
+	if u.schedule == nil {
+		return 0, nil
+	}
+
+	applied, err := u.schedule.ApplyDue(ctx, "", time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	for code, longURL := range applied {
+		u.destinationChanged(ctx, code, longURL)
+	}
+	return len(applied), nil
+}
+
+// destinationChanged refreshes the metadata of a link that now points elsewhere and forgets the
+// health of its old destination, so the new one is checked on the next run.
+func (u *urlService) destinationChanged(ctx context.Context, code, longURL string) {
+	if u.metadata != nil {
+		u.metadata.FetchAsync(code, longURL)
+	}
+	if u.health != nil {
+		if err := u.health.Delete(ctx, code); err != nil {
+			log.Printf("resetting health of %s failed: %v", code, err)
+		}
+	}
+}
+
+func (u *urlService) applyFilter(ctx context.Context, all []model.URL, filter model.URLFilter) ([]model.URL, error) {
+	if filter.Tag == "" && filter.FolderID == nil {
+		return all, nil
+	}
+
+	var tagged map[string]bool
+	if filter.Tag != "" {
+		if u.tags == nil {
+			return nil, nil
+		}
+		codes, err := u.tags.CodesByTag(ctx, normalizeTag(filter.Tag))
+		if err != nil {
+			return nil, err
+		}
+		tagged = make(map[string]bool, len(codes))
+		for _, c := range codes {
+			tagged[c] = true
+		}
+	}
+
+	var inFolder map[int64]bool
+	if filter.FolderID != nil {
+		if u.folders == nil {
+			return nil, nil
+		}
+		ids, err := u.folders.Descendants(ctx, *filter.FolderID)
+		if err != nil {
+			return nil, err
+		}
+		inFolder = make(map[int64]bool, len(ids))
+		for _, id := range ids {
+			inFolder[id] = true
+		}
+	}
+
+	var matched []model.URL
+	for _, url := range all {
+		if tagged != nil && !tagged[url.Code] {
+			continue
+		}
+		if inFolder != nil && (url.FolderID == nil || !inFolder[*url.FolderID]) {
+			continue
+		}
+		matched = append(matched, url)
+	}
+
+	return matched, nil
+}
+
+func (u *urlService) checkFolder(ctx context.Context, folderID *int64) error {
+	if folderID == nil {
+		return nil
+	}
+	if u.folders == nil {
+		return fmt.Errorf("folders are not enabled")
+	}
+	if _, err := u.folders.GetByID(ctx, *folderID); err != nil {
+		return fmt.Errorf("folder %d not found", *folderID)
+	}
+	return nil
+}
+
+// search keeps links whose code, destination, tags or fetched page metadata contain query.
+func search(all []model.URL, query string) []model.URL {
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	var matched []model.URL
+	for _, url := range all {
+		fields := []string{url.Code, url.LongURL}
+		fields = append(fields, url.Tags...)
+		if m := url.Metadata; m != nil {
+			fields = append(fields, m.Title, m.Description, m.OGTitle, m.OGDescription, m.OGSiteName,
+				m.TwitterTitle, m.TwitterDescription)
+		}
+
+		for _, f := range fields {
+			if strings.Contains(strings.ToLower(f), query) {
+				matched = append(matched, url)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// normalizeTags lowercases, trims and de-duplicates tag names, dropping empty ones.
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	var names []string
+	for _, t := range tags {
+		name := normalizeTag(t)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// domainCode is the code a link is stored under: codes on branded domains are qualified with
+// the domain, so each domain has its own namespace.
+func domainCode(code, domain string) string {
+	if domain == "" {
+		return code
+	}
+	return code + "@" + domain
+}
+
+// checkDomain makes sure a new link's branded domain is verified and belongs to its workspace.
+func (u *urlService) checkDomain(ctx context.Context, domain string, workspaceID *int64) error {
+	if domain == "" {
+		return nil
+	}
+	if u.domains == nil {
+		return fmt.Errorf("branded domains are not enabled")
+	}
+
+	d, err := u.domains.GetByName(ctx, domain)
+	if err != nil || workspaceID == nil || d.WorkspaceID != *workspaceID {
+		return fmt.Errorf("domain %s is not registered for this workspace", domain)
+	}
+	if d.VerifiedAt == nil {
+		return fmt.Errorf("domain %s is not verified yet", domain)
+	}
+	return nil
+}
+
+// hostCode qualifies a requested code with the branded domain the request was sent to. Codes
+// qualified by the visitor aren't accepted, so links can't be opened from another domain.
+func (u *urlService) hostCode(ctx context.Context, code string) (string, model.Domain, bool) {
+	if strings.Contains(code, "@") {
+		return "", model.Domain{}, false
+	}
+	if u.domains == nil {
+		return code, model.Domain{}, true
+	}
+
+	d, err := u.domains.GetByName(ctx, normalizeHost(reqinfo.FromContext(ctx).Host))
+	if err != nil || d.VerifiedAt == nil {
+		return code, model.Domain{}, true
 	}
-	return string(b)
+	return domainCode(code, d.Name), d, true
 }
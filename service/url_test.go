@@ -2,13 +2,21 @@ package service_test
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"net/netip"
+	"sort"
 	"testing"
 	"time"
 
+	"github.com/Kritvi0208/ShortEdge/auth"
 	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/ratelimit"
+	"github.com/Kritvi0208/ShortEdge/reqinfo"
 	"github.com/Kritvi0208/ShortEdge/service"
+	"github.com/Kritvi0208/ShortEdge/store"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type mockStore struct {
@@ -37,7 +45,7 @@ func (m *mockStore) GetByCode(ctx context.Context, code string) (model.URL, erro
 
 func (m *mockStore) Create(ctx context.Context, url model.URL) error {
 	if _, exists := m.urls[url.Code]; exists {
-		return errors.New("code already exists")
+		return store.ErrCodeTaken
 	}
 	m.urls[url.Code] = url
 	return nil
@@ -59,6 +67,50 @@ func (m *mockStore) Delete(ctx context.Context, code string) error {
 	return nil
 }
 
+func (m *mockStore) TakeClick(ctx context.Context, code string) (int64, bool, error) {
+	link := m.urls[code]
+	if link.RemainingClicks == nil || *link.RemainingClicks == 0 {
+		return 0, false, nil
+	}
+	remaining := *link.RemainingClicks - 1
+	link.RemainingClicks = &remaining
+	m.urls[code] = link
+	return remaining, true, nil
+}
+
+func (m *mockStore) SetMaxClicks(ctx context.Context, code string, limit *int64) (*int64, error) {
+	link := m.urls[code]
+	switch {
+	case limit == nil:
+		link.RemainingClicks = nil
+	case link.MaxClicks == nil:
+		link.RemainingClicks = ptrInt64(*limit)
+	default:
+		link.RemainingClicks = ptrInt64(max(*limit-(*link.MaxClicks-*link.RemainingClicks), 0))
+	}
+	link.MaxClicks = limit
+	m.urls[code] = link
+	return link.RemainingClicks, nil
+}
+
+func (m *mockStore) SetDisabled(ctx context.Context, code string, at *time.Time, reason string) error {
+	link := m.urls[code]
+	link.DisabledAt, link.DisabledReason = at, reason
+	m.urls[code] = link
+	return nil
+}
+
+func (m *mockStore) GetByLongURL(ctx context.Context, ownerID int64, longURL string) ([]model.URL, error) {
+	var found []model.URL
+	for _, v := range m.urls {
+		if v.OwnerID != nil && *v.OwnerID == ownerID && v.LongURL == longURL {
+			found = append(found, v)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].CreatedAt.Before(found[j].CreatedAt) })
+	return found, nil
+}
+
 func TestShorten_WithCustomCode(t *testing.T) {
 	mock := newMockStore()
 	svc := service.New(mock)
@@ -101,7 +153,7 @@ func TestGetByCode(t *testing.T) {
 
 func TestUpdate(t *testing.T) {
 	mock := newMockStore()
-	mock.urls["abc123"] = model.URL{Code: "abc123", LongURL: "old"}
+	mock.urls["abc123"] = model.URL{Code: "abc123", LongURL: "old", OwnerID: ptrInt64(7)}
 	svc := service.New(mock)
 
 	req := model.ShortenRequest{
@@ -109,7 +161,7 @@ func TestUpdate(t *testing.T) {
 		Visibility: "private",
 	}
 
-	updated, err := svc.Update(context.Background(), "abc123", req)
+	updated, err := svc.Update(userCtx(7), "abc123", req)
 	assert.NoError(t, err)
 	assert.Equal(t, "https://new.com", updated.LongURL)
 	assert.Equal(t, "private", updated.Visibility)
@@ -120,13 +172,49 @@ func TestDelete(t *testing.T) {
 	mock.urls["to-delete"] = model.URL{Code: "to-delete"}
 	svc := service.New(mock)
 
-	err := svc.Delete(context.Background(), "to-delete")
+	err := svc.Delete(adminCtx(), "to-delete")
 	assert.NoError(t, err)
 
 	_, err = svc.GetByCode(context.Background(), "to-delete")
 	assert.Error(t, err)
 }
 
+func TestUpdateDelete_RequireOwnerOrAdmin(t *testing.T) {
+	mock := newMockStore()
+	mock.urls["mine"] = model.URL{Code: "mine", LongURL: "https://a.com", OwnerID: ptrInt64(7)}
+	mock.urls["anon"] = model.URL{Code: "anon", LongURL: "https://b.com"}
+	svc := service.New(mock)
+
+	req := model.ShortenRequest{LongURL: "https://evil.com"}
+
+	_, err := svc.Update(context.Background(), "mine", req)
+	assert.ErrorIs(t, err, service.ErrUnauthenticated)
+
+	_, err = svc.Update(userCtx(8), "mine", req)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+
+	assert.ErrorIs(t, svc.Delete(userCtx(8), "mine"), service.ErrForbidden)
+	assert.ErrorIs(t, svc.Delete(userCtx(7), "anon"), service.ErrForbidden)
+	assert.ErrorIs(t, svc.AuthorizeAnalytics(userCtx(8), "mine"), service.ErrForbidden)
+	assert.NoError(t, svc.AuthorizeAnalytics(userCtx(7), "mine"))
+
+	assert.NoError(t, svc.Delete(adminCtx(), "anon"))
+	assert.Equal(t, "https://a.com", mock.urls["mine"].LongURL)
+}
+
+func TestShorten_SetsOwner(t *testing.T) {
+	svc := service.New(newMockStore())
+
+	link, err := svc.Shorten(userCtx(7), model.ShortenRequest{LongURL: "https://example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, ptrInt64(7), link.OwnerID)
+
+	anon, err := svc.Shorten(context.Background(), model.ShortenRequest{LongURL: "https://example.com", CreatedBy: "browser-1"})
+	assert.NoError(t, err)
+	assert.Nil(t, anon.OwnerID)
+	assert.Equal(t, "browser-1", anon.CreatedBy)
+}
+
 func TestGetAll_FilterExpired(t *testing.T) {
 	mock := newMockStore()
 
@@ -150,11 +238,601 @@ func TestGetAll_FilterExpired(t *testing.T) {
 
 	svc := service.New(mock)
 
-	all, err := svc.GetAll(context.Background())
+	all, err := svc.GetAll(context.Background(), model.URLFilter{})
 	assert.NoError(t, err)
 	assert.Len(t, all, 2)
 }
 
+type mockTagStore struct {
+	byCode map[string][]string
+}
+
+func newMockTagStore() *mockTagStore {
+	return &mockTagStore{byCode: make(map[string][]string)}
+}
+
+func (m *mockTagStore) Create(ctx context.Context, name string) (model.Tag, error) {
+	return model.Tag{Name: name}, nil
+}
+
+func (m *mockTagStore) GetAll(ctx context.Context) ([]model.Tag, error) { return nil, nil }
+
+func (m *mockTagStore) GetByID(ctx context.Context, id int64) (model.Tag, error) {
+	return model.Tag{ID: id}, nil
+}
+
+func (m *mockTagStore) Rename(ctx context.Context, id int64, name string) error { return nil }
+
+func (m *mockTagStore) Merge(ctx context.Context, targetID int64, sourceIDs []int64) error {
+	return nil
+}
+
+func (m *mockTagStore) Delete(ctx context.Context, id int64) error { return nil }
+
+func (m *mockTagStore) Attach(ctx context.Context, code string, names []string) error {
+	for _, n := range names {
+		if !contains(m.byCode[code], n) {
+			m.byCode[code] = append(m.byCode[code], n)
+		}
+	}
+	return nil
+}
+
+func (m *mockTagStore) Detach(ctx context.Context, code string, names []string) error {
+	var kept []string
+	for _, n := range m.byCode[code] {
+		if !contains(names, n) {
+			kept = append(kept, n)
+		}
+	}
+	m.byCode[code] = kept
+	return nil
+}
+
+func (m *mockTagStore) DetachAll(ctx context.Context, code string) error {
+	delete(m.byCode, code)
+	return nil
+}
+
+func (m *mockTagStore) GetByCode(ctx context.Context, code string) ([]string, error) {
+	return m.byCode[code], nil
+}
+
+func (m *mockTagStore) GetAllByCode(ctx context.Context) (map[string][]string, error) {
+	return m.byCode, nil
+}
+
+func (m *mockTagStore) CodesByTag(ctx context.Context, name string) ([]string, error) {
+	var codes []string
+	for code, names := range m.byCode {
+		if contains(names, name) {
+			codes = append(codes, code)
+		}
+	}
+	return codes, nil
+}
+
+type mockFolderStore struct {
+	folders map[int64]model.Folder
+}
+
+func newMockFolderStore(folders ...model.Folder) *mockFolderStore {
+	m := &mockFolderStore{folders: make(map[int64]model.Folder)}
+	for _, f := range folders {
+		m.folders[f.ID] = f
+	}
+	return m
+}
+
+func (m *mockFolderStore) Create(ctx context.Context, f model.Folder) (model.Folder, error) {
+	f.ID = int64(len(m.folders) + 1)
+	m.folders[f.ID] = f
+	return f, nil
+}
+
+func (m *mockFolderStore) GetAll(ctx context.Context) ([]model.Folder, error) { return nil, nil }
+
+func (m *mockFolderStore) GetByID(ctx context.Context, id int64) (model.Folder, error) {
+	f, ok := m.folders[id]
+	if !ok {
+		return model.Folder{}, errors.New("not found")
+	}
+	return f, nil
+}
+
+func (m *mockFolderStore) Update(ctx context.Context, f model.Folder) error {
+	m.folders[f.ID] = f
+	return nil
+}
+
+func (m *mockFolderStore) Delete(ctx context.Context, id int64) error {
+	delete(m.folders, id)
+	return nil
+}
+
+func (m *mockFolderStore) Descendants(ctx context.Context, id int64) ([]int64, error) {
+	ids := []int64{id}
+	for _, f := range m.folders {
+		if f.ParentID != nil && *f.ParentID == id {
+			sub, _ := m.Descendants(ctx, f.ID)
+			ids = append(ids, sub...)
+		}
+	}
+	return ids, nil
+}
+
+func TestShorten_WithTags(t *testing.T) {
+	mock := newMockStore()
+	tags := newMockTagStore()
+	svc := service.New(mock, service.WithTagStore(tags))
+
+	req := model.ShortenRequest{
+		LongURL:    "https://example.com",
+		CustomCode: "tagged",
+		Tags:       []string{" Launch ", "launch", "Q3", ""},
+	}
+
+	result, err := svc.Shorten(userCtx(7), req)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"launch", "q3"}, result.Tags)
+
+	result, err = svc.DetachTags(userCtx(7), "tagged", []string{"LAUNCH"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"q3"}, result.Tags)
+}
+
+func TestGetAll_FilterByTagAndFolder(t *testing.T) {
+	mock := newMockStore()
+	tags := newMockTagStore()
+	folders := newMockFolderStore(
+		model.Folder{ID: 1, Name: "marketing"},
+		model.Folder{ID: 2, Name: "emails", ParentID: ptrInt64(1)},
+		model.Folder{ID: 3, Name: "sales"},
+	)
+
+	mock.urls["a"] = model.URL{Code: "a", FolderID: ptrInt64(1)}
+	mock.urls["b"] = model.URL{Code: "b", FolderID: ptrInt64(2)}
+	mock.urls["c"] = model.URL{Code: "c", FolderID: ptrInt64(3)}
+	mock.urls["d"] = model.URL{Code: "d"}
+	tags.byCode["b"] = []string{"promo"}
+	tags.byCode["c"] = []string{"promo"}
+
+	svc := service.New(mock, service.WithTagStore(tags), service.WithFolderStore(folders))
+
+	inMarketing, err := svc.GetAll(context.Background(), model.URLFilter{FolderID: ptrInt64(1)})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "b"}, codesOf(inMarketing))
+
+	promo, err := svc.GetAll(context.Background(), model.URLFilter{Tag: "Promo"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"b", "c"}, codesOf(promo))
+
+	both, err := svc.GetAll(context.Background(), model.URLFilter{Tag: "promo", FolderID: ptrInt64(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b"}, codesOf(both))
+}
+
+func TestShorten_UnknownFolder(t *testing.T) {
+	svc := service.New(newMockStore(), service.WithFolderStore(newMockFolderStore()))
+
+	_, err := svc.Shorten(context.Background(), model.ShortenRequest{
+		LongURL:  "https://example.com",
+		FolderID: ptrInt64(42),
+	})
+	assert.Error(t, err)
+}
+
+func TestUpdate_KeepsFolder(t *testing.T) {
+	mock := newMockStore()
+	svc := service.New(mock, service.WithFolderStore(newMockFolderStore(model.Folder{ID: 1, Name: "marketing"})))
+	owner := userCtx(7)
+
+	_, err := svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com", CustomCode: "filed", FolderID: ptrInt64(1)})
+	require.NoError(t, err)
+
+	link, err := svc.Update(owner, "filed", model.ShortenRequest{LongURL: "https://example.org"})
+	require.NoError(t, err)
+	assert.Equal(t, ptrInt64(1), link.FolderID)
+
+	_, err = svc.Update(owner, "filed", model.ShortenRequest{LongURL: "https://example.org", FolderID: ptrInt64(42)})
+	assert.Error(t, err)
+
+	link, err = svc.Update(owner, "filed", model.ShortenRequest{LongURL: "https://example.org", FolderID: ptrInt64(0)})
+	require.NoError(t, err)
+	assert.Nil(t, link.FolderID)
+}
+
 func ptrTime(t time.Time) *time.Time {
 	return &t
 }
+
+func userCtx(id int64) context.Context {
+	return auth.WithPrincipal(context.Background(), auth.Principal{UserID: id, Role: auth.RoleUser})
+}
+
+func adminCtx() context.Context {
+	return auth.WithPrincipal(context.Background(), auth.Principal{UserID: 1, Role: auth.RoleAdmin})
+}
+
+func ptrInt64(i int64) *int64 {
+	return &i
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func codesOf(urls []model.URL) []string {
+	var codes []string
+	for _, u := range urls {
+		codes = append(codes, u.Code)
+	}
+	return codes
+}
+
+func TestVisibility(t *testing.T) {
+	mock := newMockStore()
+	owner := ptrInt64(7)
+	for _, v := range []string{"public", "unlisted", "private", "internal"} {
+		mock.urls[v] = model.URL{Code: v, LongURL: "https://example.com/" + v, Visibility: v, OwnerID: owner}
+	}
+
+	svc := service.New(mock, service.WithInternalNetworks([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}))
+
+	anon := context.Background()
+	inside := reqinfo.WithInfo(anon, reqinfo.Info{IP: netip.MustParseAddr("10.1.2.3")})
+	outside := reqinfo.WithInfo(anon, reqinfo.Info{IP: netip.MustParseAddr("203.0.113.9")})
+
+	_, err := svc.Resolve(anon, "public", "")
+	assert.NoError(t, err)
+	_, err = svc.Resolve(anon, "unlisted", "")
+	assert.NoError(t, err)
+	_, err = svc.Resolve(anon, "private", "")
+	assert.ErrorIs(t, err, service.ErrUnauthenticated)
+	_, err = svc.Resolve(userCtx(8), "private", "")
+	assert.ErrorIs(t, err, service.ErrPrivateLink)
+	_, err = svc.Resolve(userCtx(7), "private", "")
+	assert.NoError(t, err)
+	_, err = svc.Resolve(outside, "internal", "")
+	assert.ErrorIs(t, err, service.ErrInternalLink)
+	_, err = svc.Resolve(inside, "internal", "")
+	assert.NoError(t, err)
+	_, err = svc.Resolve(anon, "missing", "")
+	assert.ErrorIs(t, err, service.ErrLinkNotFound)
+
+	// Looking links up, as for QR codes, is held to the same rules
+	_, err = svc.GetByCode(anon, "private")
+	assert.ErrorIs(t, err, service.ErrUnauthenticated)
+	_, err = svc.GetByCode(userCtx(8), "private")
+	assert.ErrorIs(t, err, service.ErrPrivateLink)
+	_, err = svc.GetByCode(userCtx(7), "private")
+	assert.NoError(t, err)
+	_, err = svc.GetByCode(outside, "internal")
+	assert.ErrorIs(t, err, service.ErrInternalLink)
+	_, err = svc.GetByCode(inside, "internal")
+	assert.NoError(t, err)
+
+	all, err := svc.GetAll(anon, model.URLFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"public"}, codesOf(all))
+
+	all, err = svc.GetAll(userCtx(7), model.URLFilter{})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"public", "unlisted", "private"}, codesOf(all))
+
+	// Analytics of internal links are only readable from the internal network, even by the owner.
+	assert.ErrorIs(t, svc.AuthorizeAnalytics(userCtx(7), "internal"), service.ErrInternalLink)
+	assert.NoError(t, svc.AuthorizeAnalytics(reqinfo.WithInfo(userCtx(7), reqinfo.Info{IP: netip.MustParseAddr("10.0.0.1")}), "internal"))
+}
+
+func TestShorten_Visibility(t *testing.T) {
+	svc := service.New(newMockStore())
+
+	link, err := svc.Shorten(context.Background(), model.ShortenRequest{LongURL: "https://example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, model.VisibilityPublic, link.Visibility)
+
+	link, err = svc.Shorten(context.Background(), model.ShortenRequest{LongURL: "https://example.com", Visibility: " Unlisted"})
+	assert.NoError(t, err)
+	assert.Equal(t, model.VisibilityUnlisted, link.Visibility)
+
+	_, err = svc.Shorten(context.Background(), model.ShortenRequest{LongURL: "https://example.com", Visibility: "secret"})
+	assert.Error(t, err)
+}
+
+func TestLinkPassword(t *testing.T) {
+	mock := newMockStore()
+	attempts := ratelimit.NewMemory()
+	svc := service.New(mock, service.WithPasswordAttempts(attempts, ratelimit.Limit{Burst: 2, Period: time.Hour}))
+
+	owner := userCtx(7)
+	secret := "hunter2"
+	link, err := svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com/doc", CustomCode: "doc",
+		Password: &secret})
+	assert.NoError(t, err)
+	assert.True(t, link.PasswordProtected)
+	assert.NotContains(t, mock.urls["doc"].PasswordHash, secret)
+
+	_, err = svc.Resolve(context.Background(), "doc", "")
+	assert.ErrorIs(t, err, service.ErrPasswordRequired)
+	_, err = svc.Resolve(context.Background(), "doc", secret)
+	assert.NoError(t, err)
+
+	// Correct passwords don't use up attempts; wrong ones lock the link.
+	_, err = svc.Resolve(context.Background(), "doc", secret)
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = svc.Resolve(context.Background(), "doc", "guess")
+		assert.ErrorIs(t, err, service.ErrWrongPassword)
+	}
+	_, err = svc.Resolve(context.Background(), "doc", secret)
+	assert.ErrorContains(t, err, "too many wrong passwords")
+
+	// Updates keep the password unless one is given; an empty one removes it.
+	_, err = svc.Update(owner, "doc", model.ShortenRequest{LongURL: "https://example.com/doc2"})
+	assert.NoError(t, err)
+	assert.True(t, mock.urls["doc"].PasswordProtected)
+
+	empty := ""
+	link, err = svc.Update(owner, "doc", model.ShortenRequest{LongURL: "https://example.com/doc2", Password: &empty})
+	assert.NoError(t, err)
+	assert.False(t, link.PasswordProtected)
+	_, err = svc.Resolve(context.Background(), "doc", "")
+	assert.NoError(t, err)
+}
+
+func TestLinkPassword_ParallelGuesses(t *testing.T) {
+	mock := newMockStore()
+	svc := service.New(mock, service.WithPasswordAttempts(ratelimit.NewMemory(), ratelimit.Limit{Burst: 2, Period: time.Hour}))
+	secret := "hunter2"
+	_, err := svc.Shorten(userCtx(7), model.ShortenRequest{LongURL: "https://example.com/doc", CustomCode: "doc",
+		Password: &secret})
+	require.NoError(t, err)
+
+	// Guesses sent at once still only get as many tries as the limit allows
+	errs := make(chan error, 10)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			_, err := svc.Resolve(context.Background(), "doc", "guess")
+			errs <- err
+		}()
+	}
+	wrong := 0
+	for i := 0; i < cap(errs); i++ {
+		if errors.Is(<-errs, service.ErrWrongPassword) {
+			wrong++
+		}
+	}
+	assert.Equal(t, 2, wrong)
+}
+
+func TestClickLimit(t *testing.T) {
+	mock := newMockStore()
+	svc := service.New(mock)
+	owner := userCtx(7)
+
+	_, err := svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com", MaxClicks: ptrInt64(0)})
+	assert.Error(t, err)
+
+	link, err := svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com/invite", CustomCode: "invite",
+		MaxClicks: ptrInt64(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), *link.RemainingClicks)
+
+	link, err = svc.Resolve(context.Background(), "invite", "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), *link.RemainingClicks)
+
+	_, err = svc.Resolve(context.Background(), "invite", "")
+	assert.ErrorIs(t, err, service.ErrClicksExhausted)
+
+	// Raising the limit keeps the clicks already used.
+	link, err = svc.Update(owner, "invite", model.ShortenRequest{LongURL: "https://example.com/invite", MaxClicks: ptrInt64(3)})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), *link.RemainingClicks)
+
+	link, err = svc.Update(owner, "invite", model.ShortenRequest{LongURL: "https://example.com/invite", MaxClicks: ptrInt64(0)})
+	assert.NoError(t, err)
+	assert.Nil(t, link.MaxClicks)
+	assert.Nil(t, link.RemainingClicks)
+
+	// A fallback is passed along with the error.
+	svc = service.New(mock, service.WithExhaustedURL("https://example.com/gone"))
+	_, err = svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com", CustomCode: "once", MaxClicks: ptrInt64(1)})
+	assert.NoError(t, err)
+	_, _ = svc.Resolve(context.Background(), "once", "")
+	_, err = svc.Resolve(context.Background(), "once", "")
+	var unavailable service.LinkUnavailableError
+	assert.ErrorAs(t, err, &unavailable)
+	assert.Equal(t, "https://example.com/gone", unavailable.Fallback)
+	assert.ErrorIs(t, err, service.ErrClicksExhausted)
+
+	// Expired links don't take clicks
+	expired := time.Now().Add(-time.Minute)
+	_, err = svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com", CustomCode: "old", MaxClicks: ptrInt64(2),
+		ExpiresAt: &expired})
+	require.NoError(t, err)
+	_, err = svc.Resolve(context.Background(), "old", "")
+	assert.ErrorIs(t, err, service.ErrLinkExpired)
+	assert.Equal(t, int64(2), *mock.urls["old"].RemainingClicks)
+
+	// Neither do lookups that fail before the redirect
+	broken := service.New(mock, service.WithTargeting(failingTargetingStore{}, mockGeo{}))
+	_, err = broken.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com", CustomCode: "limited", MaxClicks: ptrInt64(2)})
+	require.NoError(t, err)
+	_, err = broken.Resolve(context.Background(), "limited", "")
+	assert.Error(t, err)
+	assert.Equal(t, int64(2), *mock.urls["limited"].RemainingClicks)
+}
+
+type failingTargetingStore struct {
+	*mockTargetingStore
+}
+
+func (failingTargetingStore) Rules(ctx context.Context, code string) ([]model.TargetingRule, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestAppLinks(t *testing.T) {
+	mock := newMockStore()
+	svc := service.New(mock)
+	owner := userCtx(7)
+
+	_, err := svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com",
+		IOS: &model.AppLink{AppURL: "javascript:alert(1)"}})
+	assert.Error(t, err)
+	_, err = svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com",
+		Android: &model.AppLink{StoreURL: "market://details?id=com.example"}})
+	assert.Error(t, err)
+
+	_, err = svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com/app", CustomCode: "app",
+		IOS:     &model.AppLink{AppURL: "example://home", StoreURL: "https://apps.apple.com/app/id1"},
+		Android: &model.AppLink{StoreURL: "https://play.google.com/store/apps/details?id=com.example"}})
+	require.NoError(t, err)
+
+	device := func(userAgent string) context.Context {
+		return reqinfo.WithInfo(context.Background(), reqinfo.Info{UserAgent: userAgent})
+	}
+
+	link, err := svc.Resolve(device("Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)"), "app", "")
+	require.NoError(t, err)
+	assert.Equal(t, "example://home", link.LongURL)
+	assert.Equal(t, "https://apps.apple.com/app/id1", link.AppFallbackURL)
+
+	link, err = svc.Resolve(device("Mozilla/5.0 (Linux; Android 14; Pixel 8)"), "app", "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://play.google.com/store/apps/details?id=com.example", link.LongURL)
+	assert.Empty(t, link.AppFallbackURL)
+
+	link, err = svc.Resolve(device("Mozilla/5.0 (X11; Linux x86_64)"), "app", "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/app", link.LongURL)
+
+	// An empty app link removes it; nil keeps it.
+	link, err = svc.Update(owner, "app", model.ShortenRequest{LongURL: "https://example.com/app", IOS: &model.AppLink{}})
+	require.NoError(t, err)
+	assert.Nil(t, link.IOS)
+	assert.NotNil(t, link.Android)
+
+	// Without a store listing, visitors without the app fall back to the web page
+	_, err = svc.Update(owner, "app", model.ShortenRequest{LongURL: "https://example.com/app",
+		Android: &model.AppLink{AppURL: "example://home"}})
+	require.NoError(t, err)
+	link, err = svc.Resolve(device("Mozilla/5.0 (Linux; Android 14; Pixel 8)"), "app", "")
+	require.NoError(t, err)
+	assert.Equal(t, "example://home", link.LongURL)
+	assert.Equal(t, "https://example.com/app", link.AppFallbackURL)
+}
+
+type mockScheduleStore struct {
+	urls    *mockStore
+	changes []model.ScheduledChange
+}
+
+func (m *mockScheduleStore) Create(ctx context.Context, c model.ScheduledChange) (model.ScheduledChange, error) {
+	c.ID = int64(len(m.changes) + 1)
+	m.changes = append(m.changes, c)
+	return c, nil
+}
+
+func (m *mockScheduleStore) Pending(ctx context.Context, code string) ([]model.ScheduledChange, error) {
+	var list []model.ScheduledChange
+	for _, c := range m.changes {
+		if c.Code == code && c.AppliedAt == nil {
+			list = append(list, c)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockScheduleStore) Cancel(ctx context.Context, code string, id int64) error {
+	for i, c := range m.changes {
+		if c.ID == id && c.Code == code && c.AppliedAt == nil {
+			m.changes = append(m.changes[:i], m.changes[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (m *mockScheduleStore) DeleteAll(ctx context.Context, code string) error {
+	return nil
+}
+
+func (m *mockScheduleStore) ApplyDue(ctx context.Context, code string, now time.Time) (map[string]string, error) {
+	applied := make(map[string]string)
+	latest := make(map[string]time.Time)
+	for i, c := range m.changes {
+		if c.AppliedAt != nil || c.At.After(now) || (code != "" && c.Code != code) {
+			continue
+		}
+		m.changes[i].AppliedAt = &now
+		if c.At.After(latest[c.Code]) {
+			latest[c.Code] = c.At
+			applied[c.Code] = c.LongURL
+		}
+	}
+	for c, longURL := range applied {
+		link := m.urls.urls[c]
+		link.LongURL = longURL
+		m.urls.urls[c] = link
+	}
+	return applied, nil
+}
+
+func TestScheduling(t *testing.T) {
+	mock := newMockStore()
+	schedule := &mockScheduleStore{urls: mock}
+	svc := service.New(mock, service.WithScheduleStore(schedule), service.WithComingSoonURL("https://example.com/soon"))
+	owner := userCtx(7)
+
+	launch := time.Now().Add(time.Hour)
+	_, err := svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com/launch", CustomCode: "launch",
+		ActivateAt: &launch})
+	assert.NoError(t, err)
+
+	_, err = svc.Resolve(context.Background(), "launch", "")
+	assert.ErrorIs(t, err, service.ErrNotActive)
+	var unavailable service.LinkUnavailableError
+	assert.ErrorAs(t, err, &unavailable)
+	assert.Equal(t, "https://example.com/soon", unavailable.Fallback)
+
+	_, err = svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com/v1", CustomCode: "page"})
+	assert.NoError(t, err)
+
+	_, err = svc.ScheduleChange(owner, "page", model.ScheduledChangeRequest{LongURL: "https://example.com/v2",
+		At: time.Now().Add(-time.Minute)})
+	assert.Error(t, err, "changes must be in the future")
+	_, err = svc.ScheduleChange(userCtx(8), "page", model.ScheduledChangeRequest{LongURL: "https://example.com/v2",
+		At: time.Now().Add(time.Hour)})
+	assert.ErrorIs(t, err, service.ErrForbidden)
+
+	later, err := svc.ScheduleChange(owner, "page", model.ScheduledChangeRequest{LongURL: "https://example.com/v3",
+		At: time.Now().Add(2 * time.Hour)})
+	assert.NoError(t, err)
+	_, err = svc.ScheduleChange(owner, "page", model.ScheduledChangeRequest{LongURL: "https://example.com/v2",
+		At: time.Now().Add(time.Hour)})
+	assert.NoError(t, err)
+
+	pending, err := svc.ScheduledChanges(owner, "page")
+	assert.NoError(t, err)
+	assert.Len(t, pending, 2)
+
+	assert.NoError(t, svc.CancelChange(owner, "page", later.ID))
+	assert.Error(t, svc.CancelChange(owner, "page", later.ID))
+
+	// Once due, the redirect switches to the new destination.
+	schedule.changes[0].At = time.Now().Add(-time.Second)
+	link, err := svc.Resolve(context.Background(), "page", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/v2", link.LongURL)
+	assert.Equal(t, "https://example.com/v2", mock.urls["page"].LongURL)
+
+	pending, _ = svc.ScheduledChanges(owner, "page")
+	assert.Empty(t, pending)
+}
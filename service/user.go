@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/auth"
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/store"
+)
+
+const minPasswordLength = 8
+
+type UserService interface {
+	Register(ctx context.Context, req model.RegisterRequest) (model.User, error)
+	Login(ctx context.Context, req model.LoginRequest) (model.Session, error)
+	Logout(ctx context.Context) error
+	Me(ctx context.Context) (model.User, error)
+	Authenticate(ctx context.Context, token string) (auth.Principal, error)
+	PromoteAdmin(ctx context.Context, email string) (model.User, error)
+}
+
+type userService struct {
+	store      store.User
+	sessionTTL time.Duration
+}
+
+// NewUserService creates accounts. Registering never makes anyone an admin, as nothing proves
+// the caller owns the address; admins are promoted with PromoteAdmin.
+func NewUserService(s store.User, sessionTTL time.Duration) UserService {
+	return &userService{store: s, sessionTTL: sessionTTL}
+}
+
+func (u *userService) Register(ctx context.Context, req model.RegisterRequest) (model.User, error) {
+	email := normalizeEmail(req.Email)
+	if _, err := mail.ParseAddress(email); err != nil || email == "" {
+		return model.User{}, fmt.Errorf("a valid email is required")
+	}
+	if len(req.Password) < minPasswordLength {
+		return model.User{}, fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	if len(req.Password) > auth.MaxPasswordLength {
+		return model.User{}, fmt.Errorf("password must be at most %d bytes", auth.MaxPasswordLength)
+	}
+
+	if _, err := u.store.GetByEmail(ctx, email); err == nil {
+		return model.User{}, ErrEmailTaken
+	}
+
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	return u.store.Create(ctx, model.User{
+		Email:        email,
+		PasswordHash: hash,
+		Role:         auth.RoleUser,
+		CreatedAt:    time.Now(),
+	})
+}
+
+func (u *userService) Login(ctx context.Context, req model.LoginRequest) (model.Session, error) {
+	user, err := u.store.GetByEmail(ctx, normalizeEmail(req.Email))
+	if err != nil || !auth.VerifyPassword(user.PasswordHash, req.Password) {
+		return model.Session{}, ErrInvalidCredentials
+	}
+
+	token, err := auth.NewToken("ses_")
+	if err != nil {
+		return model.Session{}, err
+	}
+
+	expiresAt := time.Now().Add(u.sessionTTL)
+	if err := u.store.CreateSession(ctx, auth.HashToken(token), user.ID, expiresAt); err != nil {
+		return model.Session{}, err
+	}
+
+	return model.Session{Token: token, ExpiresAt: expiresAt, User: user}, nil
+}
+
+// Logout ends the session the current request was authenticated with.
+func (u *userService) Logout(ctx context.Context) error {
+	p, ok := auth.FromContext(ctx)
+	if !ok || p.SessionID == "" {
+		return ErrUnauthenticated
+	}
+
+	return u.store.DeleteSession(ctx, p.SessionID)
+}
+
+func (u *userService) Me(ctx context.Context) (model.User, error) {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return model.User{}, ErrUnauthenticated
+	}
+
+	return u.store.GetByID(ctx, p.UserID)
+}
+
+// Authenticate resolves a session token to its principal.
+func (u *userService) Authenticate(ctx context.Context, token string) (auth.Principal, error) {
+	hash := auth.HashToken(token)
+
+	user, err := u.store.GetSessionUser(ctx, hash)
+	if err != nil {
+		return auth.Principal{}, ErrUnauthenticated
+	}
+
+	p := userPrincipal(user)
+	p.SessionID = hash
+	return p, nil
+}
+
+// PromoteAdmin makes an existing account a platform admin. It's an operator step, run from the
+// command line, so it's up to the operator to know who the account belongs to.
+func (u *userService) PromoteAdmin(ctx context.Context, email string) (model.User, error) {
+	user, err := u.store.GetByEmail(ctx, normalizeEmail(email))
+	if err != nil {
+		return model.User{}, fmt.Errorf("no account is registered with %s", email)
+	}
+	if user.Role == auth.RoleAdmin {
+		return user, nil
+	}
+
+	if err := u.store.SetRole(ctx, user.ID, auth.RoleAdmin); err != nil {
+		return model.User{}, err
+	}
+	user.Role = auth.RoleAdmin
+	return user, nil
+}
+
+func userPrincipal(user model.User) auth.Principal {
+	p := auth.Principal{UserID: user.ID, Email: user.Email, Role: user.Role}
+	if user.DefaultWorkspaceID != nil {
+		p.WorkspaceID = *user.DefaultWorkspaceID
+	}
+	return p
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
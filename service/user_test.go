@@ -0,0 +1,134 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/auth"
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockUserStore struct {
+	users      map[int64]model.User
+	sessions   map[string]int64
+	identities map[string]int64
+}
+
+func newMockUserStore() *mockUserStore {
+	return &mockUserStore{users: make(map[int64]model.User), sessions: make(map[string]int64),
+		identities: make(map[string]int64)}
+}
+
+func (m *mockUserStore) Create(ctx context.Context, u model.User) (model.User, error) {
+	u.ID = int64(len(m.users) + 1)
+	m.users[u.ID] = u
+	return u, nil
+}
+
+func (m *mockUserStore) GetByID(ctx context.Context, id int64) (model.User, error) {
+	u, ok := m.users[id]
+	if !ok {
+		return model.User{}, errors.New("not found")
+	}
+	return u, nil
+}
+
+func (m *mockUserStore) GetByEmail(ctx context.Context, email string) (model.User, error) {
+	for _, u := range m.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return model.User{}, sql.ErrNoRows
+}
+
+func (m *mockUserStore) SetRole(ctx context.Context, id int64, role string) error {
+	u := m.users[id]
+	u.Role = role
+	m.users[id] = u
+	return nil
+}
+
+func (m *mockUserStore) CreateSession(ctx context.Context, tokenHash string, userID int64, expiresAt time.Time) error {
+	m.sessions[tokenHash] = userID
+	return nil
+}
+
+func (m *mockUserStore) GetSessionUser(ctx context.Context, tokenHash string) (model.User, error) {
+	id, ok := m.sessions[tokenHash]
+	if !ok {
+		return model.User{}, errors.New("not found")
+	}
+	return m.GetByID(ctx, id)
+}
+
+func (m *mockUserStore) DeleteSession(ctx context.Context, tokenHash string) error {
+	delete(m.sessions, tokenHash)
+	return nil
+}
+
+func (m *mockUserStore) GetByIdentity(ctx context.Context, issuer, subject string) (model.User, error) {
+	id, ok := m.identities[issuer+"|"+subject]
+	if !ok {
+		return model.User{}, sql.ErrNoRows
+	}
+	return m.GetByID(ctx, id)
+}
+
+func (m *mockUserStore) LinkIdentity(ctx context.Context, issuer, subject string, userID int64) error {
+	m.identities[issuer+"|"+subject] = userID
+	return nil
+}
+
+func TestUserLifecycle(t *testing.T) {
+	users := newMockUserStore()
+	svc := service.NewUserService(users, time.Hour)
+	ctx := context.Background()
+
+	_, err := svc.Register(ctx, model.RegisterRequest{Email: "not-an-email", Password: "longenough"})
+	assert.Error(t, err)
+
+	_, err = svc.Register(ctx, model.RegisterRequest{Email: "dev@example.com", Password: "short"})
+	assert.Error(t, err)
+
+	dev, err := svc.Register(ctx, model.RegisterRequest{Email: " Dev@Example.com ", Password: "longenough"})
+	require.NoError(t, err)
+	assert.Equal(t, "dev@example.com", dev.Email)
+	assert.Equal(t, auth.RoleUser, dev.Role)
+	assert.NotContains(t, users.users[dev.ID].PasswordHash, "longenough")
+
+	_, err = svc.Register(ctx, model.RegisterRequest{Email: "dev@example.com", Password: "longenough"})
+	assert.ErrorIs(t, err, service.ErrEmailTaken)
+
+	// Admins are only ever promoted from existing accounts
+	_, err = svc.PromoteAdmin(ctx, "boss@example.com")
+	assert.Error(t, err)
+	boss, err := svc.Register(ctx, model.RegisterRequest{Email: "boss@example.com", Password: "longenough"})
+	require.NoError(t, err)
+	assert.Equal(t, auth.RoleUser, boss.Role)
+	boss, err = svc.PromoteAdmin(ctx, "Boss@Example.com")
+	require.NoError(t, err)
+	assert.Equal(t, auth.RoleAdmin, boss.Role)
+	assert.Equal(t, auth.RoleAdmin, users.users[boss.ID].Role)
+
+	_, err = svc.Login(ctx, model.LoginRequest{Email: "dev@example.com", Password: "wrong-password"})
+	assert.ErrorIs(t, err, service.ErrInvalidCredentials)
+
+	session, err := svc.Login(ctx, model.LoginRequest{Email: "dev@example.com", Password: "longenough"})
+	require.NoError(t, err)
+
+	p, err := svc.Authenticate(ctx, session.Token)
+	require.NoError(t, err)
+	assert.Equal(t, dev.ID, p.UserID)
+
+	require.NoError(t, svc.Logout(auth.WithPrincipal(ctx, p)))
+
+	_, err = svc.Authenticate(ctx, session.Token)
+	assert.ErrorIs(t, err, service.ErrUnauthenticated)
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// FieldError is a problem with one field of a request; Field is its JSON path, such as
+// "rules[1].long_url".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError rejects a request with 400 and lists the fields at fault in a "fields"
+// member of the error response.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Field + " " + f.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e ValidationError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// Response adds the field details to gofr's error response.
+func (e ValidationError) Response() map[string]any {
+	return map[string]any{"fields": e.Fields}
+}
+
+// invalidField reports a single bad field; message reads as a predicate, e.g. "is required".
+func invalidField(field, message string) error {
+	return ValidationError{Fields: []FieldError{{Field: field, Message: message}}}
+}
+
+// URLNormalizer validates destinations and brings them into canonical form; urlnorm.Normalizer
+// implements it.
+type URLNormalizer interface {
+	Normalize(rawURL string) (string, error)
+}
+
+// WithNormalizer validates and normalizes the destinations of new and changed links.
+func WithNormalizer(n URLNormalizer) Option {
+	return func(u *urlService) { u.normalizer = n }
+}
+
+// destination validates a destination given in field and returns the form to store: normalized,
+// not pointing at a verified branded domain and passing screening.
+func (u *urlService) destination(ctx context.Context, field, rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return "", invalidField(field, "is required")
+	}
+
+	if u.normalizer != nil {
+		normalized, err := u.normalizer.Normalize(rawURL)
+		if err != nil {
+			return "", invalidField(field, err.Error())
+		}
+		rawURL = normalized
+	}
+
+	// Branded domains redirect to ShortEdge, so a link to one could loop
+	if u.domains != nil {
+		if parsed, err := url.Parse(rawURL); err == nil && parsed.Host != "" {
+			d, err := u.domains.GetByName(ctx, normalizeHost(parsed.Host))
+			if err == nil && d.VerifiedAt != nil {
+				return "", invalidField(field, "points at a branded domain of ShortEdge, which would redirect in a loop")
+			}
+		}
+	}
+
+	if u.screener != nil {
+		if err := u.screener.Screen(ctx, rawURL); err != nil {
+			return "", invalidField(field, "was rejected: "+err.Error())
+		}
+	}
+	return rawURL, nil
+}
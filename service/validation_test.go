@@ -0,0 +1,68 @@
+package service_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/service"
+	"github.com/Kritvi0208/ShortEdge/urlnorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDestinationValidation(t *testing.T) {
+	verified := time.Now()
+	urls := newMockStore()
+	domains := &mockDomainStore{urls: urls, domains: map[int64]model.Domain{
+		1: {ID: 1, Name: "go.acme.com", VerifiedAt: &verified},
+		2: {ID: 2, Name: "pending.acme.com"},
+	}}
+	normalizer := urlnorm.New(urlnorm.Config{MaxLength: 64, StripDefaultPort: true, StripFragment: true,
+		OwnHosts: []string{"https://sho.rt"}})
+	svc := service.New(urls, service.WithNormalizer(normalizer), service.WithDomainStore(domains))
+	owner := userCtx(7)
+
+	link, err := svc.Shorten(owner, model.ShortenRequest{LongURL: " HTTPS://Bücher.Example:443/Path#top", CustomCode: "a"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://xn--bcher-kva.example/Path", link.LongURL)
+
+	cases := map[string]string{
+		"":                                "long_url is required",
+		"example.com/page":                "long_url must be an absolute URL with a scheme, such as https://",
+		"https://example.com:99999/":      "long_url has an invalid port",
+		"https://example.com/" + long(64): "long_url is too long, the limit is 64 characters",
+		"https://SHO.RT/abc":              "long_url points back at ShortEdge, which would redirect in a loop",
+		"https://go.acme.com/x":           "long_url points at a branded domain of ShortEdge, which would redirect in a loop",
+	}
+	for raw, want := range cases {
+		_, err := svc.Shorten(owner, model.ShortenRequest{LongURL: raw})
+		assert.EqualError(t, err, want, raw)
+
+		var verr service.ValidationError
+		if assert.True(t, errors.As(err, &verr), raw) {
+			assert.Equal(t, http.StatusBadRequest, verr.StatusCode())
+			assert.Equal(t, []service.FieldError{{Field: "long_url", Message: want[len("long_url "):]}}, verr.Response()["fields"])
+		}
+	}
+
+	// Domains only redirect to ShortEdge once verified
+	_, err = svc.Shorten(owner, model.ShortenRequest{LongURL: "https://pending.acme.com/x"})
+	assert.NoError(t, err)
+
+	_, err = svc.Update(owner, "a", model.ShortenRequest{LongURL: "https://sho.rt:443/b"})
+	assert.EqualError(t, err, "long_url points back at ShortEdge, which would redirect in a loop")
+	updated, err := svc.Update(owner, "a", model.ShortenRequest{LongURL: "http://Example.com:80"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://example.com", updated.LongURL)
+}
+
+func long(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = 'a'
+	}
+	return string(b)
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/reqinfo"
+	"github.com/Kritvi0208/ShortEdge/store"
+)
+
+// maxVariants bounds the destinations one link can split its traffic across.
+const maxVariants = 20
+
+// WithVariantStore enables splitting a link's traffic across weighted destinations.
+func WithVariantStore(v store.Variant) Option {
+	return func(u *urlService) { u.variants = v }
+}
+
+func (u *urlService) Variants(ctx context.Context, code string) ([]model.Variant, error) {
+	if u.variants == nil {
+		return nil, fmt.Errorf("A/B variants are not enabled")
+	}
+
+	link, _, err := u.authorize(ctx, code, model.WorkspaceViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.variants.Variants(ctx, link.Code, false)
+}
+
+// SetVariants replaces the link's variants. Listed variants keep their ID and visits, so weights
+// can change mid-test; an empty list stops the test and sends everyone to the link's URL again.
+func (u *urlService) SetVariants(ctx context.Context, code string, req model.VariantsRequest) ([]model.Variant, error) {
+	if u.variants == nil {
+		return nil, fmt.Errorf("A/B variants are not enabled")
+	}
+
+	link, _, err := u.authorize(ctx, code, model.WorkspaceEditor)
+	if err != nil {
+		return nil, err
+	}
+	code = link.Code
+
+	if len(req.Variants) > maxVariants {
+		return nil, fmt.Errorf("a link can have at most %d variants", maxVariants)
+	}
+
+	current, err := u.variants.Variants(ctx, code, false)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[int64]bool, len(current))
+	for _, v := range current {
+		existing[v.ID] = true
+	}
+
+	variants := make([]model.Variant, 0, len(req.Variants))
+	seen := make(map[int64]bool)
+	total := 0
+	for i, r := range req.Variants {
+		v := model.Variant{Name: strings.TrimSpace(r.Name), LongURL: strings.TrimSpace(r.LongURL), Weight: r.Weight}
+		if r.ID != nil {
+			if !existing[*r.ID] || seen[*r.ID] {
+				return nil, fmt.Errorf("variant %d: unknown variant id %d", i+1, *r.ID)
+			}
+			seen[*r.ID] = true
+			v.ID = *r.ID
+		}
+		longURL, err := u.destination(ctx, fmt.Sprintf("variants[%d].long_url", i), v.LongURL)
+		if err != nil {
+			return nil, err
+		}
+		v.LongURL = longURL
+		if v.Weight < 0 {
+			return nil, fmt.Errorf("variant %d: weight can't be negative", i+1)
+		}
+		if v.Name == "" {
+			v.Name = "Variant " + strconv.Itoa(i+1)
+		}
+		total += v.Weight
+		variants = append(variants, v)
+	}
+
+	if len(variants) > 0 && total == 0 {
+		return nil, fmt.Errorf("at least one variant needs a weight above 0")
+	}
+
+	return u.variants.Save(ctx, code, variants)
+}
+
+// rotate sends the visitor to one of the link's variants. Links without variants, and visitors
+// a targeting rule already picked a destination for, keep theirs.
+func (u *urlService) rotate(ctx context.Context, link model.URL) (model.URL, error) {
+	if u.variants == nil || link.RuleID != nil {
+		return link, nil
+	}
+
+	variants, err := u.variants.Variants(ctx, link.Code, false)
+	if err != nil || len(variants) == 0 {
+		return link, err
+	}
+
+	v, ok := pickVariant(variants, reqinfo.FromContext(ctx).VisitorKey)
+	if !ok {
+		return link, nil
+	}
+	link.LongURL = v.LongURL
+	link.VariantID = &v.ID
+	return link, nil
+}
+
+// pickVariant chooses a variant for visitorKey by weighted rendezvous hashing: every variant
+// draws a score from the key, scaled by its weight, and the lowest wins. A visitor keeps their
+// variant across visits, and changing weights or variants only moves the visitors it has to.
+func pickVariant(variants []model.Variant, visitorKey string) (model.Variant, bool) {
+	var best model.Variant
+	bestScore, found := math.Inf(1), false
+
+	for _, v := range variants {
+		if v.Weight <= 0 {
+			continue
+		}
+
+		sum := sha256.Sum256([]byte(strconv.FormatInt(v.ID, 10) + ":" + visitorKey))
+		// Uniform in (0, 1), never exactly 0 so the logarithm stays finite
+		x := (float64(binary.BigEndian.Uint64(sum[:])>>11) + 0.5) / (1 << 53)
+
+		score := -math.Log(x) / float64(v.Weight)
+		if score < bestScore {
+			best, bestScore, found = v, score, true
+		}
+	}
+	return best, found
+}
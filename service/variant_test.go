@@ -0,0 +1,193 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/reqinfo"
+	"github.com/Kritvi0208/ShortEdge/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockVariantStore struct {
+	nextID   int64
+	variants map[int64]model.Variant
+	archived map[int64]bool
+}
+
+func newMockVariantStore() *mockVariantStore {
+	return &mockVariantStore{variants: make(map[int64]model.Variant), archived: make(map[int64]bool)}
+}
+
+func (m *mockVariantStore) Variants(ctx context.Context, code string, archived bool) ([]model.Variant, error) {
+	var list []model.Variant
+	for id := int64(1); id <= m.nextID; id++ {
+		v, ok := m.variants[id]
+		if ok && v.Code == code && (archived || !m.archived[id]) {
+			list = append(list, v)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockVariantStore) Save(ctx context.Context, code string, variants []model.Variant) ([]model.Variant, error) {
+	keep := make(map[int64]bool)
+	for i := range variants {
+		if variants[i].ID == 0 {
+			m.nextID++
+			variants[i].ID = m.nextID
+		}
+		variants[i].Code = code
+		m.variants[variants[i].ID] = variants[i]
+		keep[variants[i].ID] = true
+	}
+	for id, v := range m.variants {
+		if v.Code == code && !keep[id] {
+			m.archived[id] = true
+		}
+	}
+	return variants, nil
+}
+
+func (m *mockVariantStore) DeleteAll(ctx context.Context, code string) error {
+	for id, v := range m.variants {
+		if v.Code == code {
+			delete(m.variants, id)
+		}
+	}
+	return nil
+}
+
+type mockVisitStore struct {
+	visits   []model.Visit
+	previews []model.Visit
+}
+
+func (m *mockVisitStore) LogVisit(ctx context.Context, v model.Visit) error {
+	m.visits = append(m.visits, v)
+	return nil
+}
+
+func (m *mockVisitStore) GetAnalytics(ctx context.Context, code string) ([]model.Visit, error) {
+	var list []model.Visit
+	for _, v := range m.visits {
+		if v.Code == code {
+			list = append(list, v)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockVisitStore) GetAnalyticsForCodes(ctx context.Context, codes []string) ([]model.Visit, error) {
+	return m.visits, nil
+}
+
+func (m *mockVisitStore) LogPreview(ctx context.Context, v model.Visit) error {
+	m.previews = append(m.previews, v)
+	return nil
+}
+
+func (m *mockVisitStore) PreviewCounts(ctx context.Context, code string) (int, int, error) {
+	views, visitors := 0, make(map[string]bool)
+	for _, v := range m.previews {
+		if v.Code == code {
+			views++
+			visitors[v.VisitorKey] = true
+		}
+	}
+	return views, len(visitors), nil
+}
+
+func visitorWithKey(key string) context.Context {
+	return reqinfo.WithInfo(context.Background(), reqinfo.Info{VisitorKey: key})
+}
+
+func TestVariants(t *testing.T) {
+	mock := newMockStore()
+	variants := newMockVariantStore()
+	svc := service.New(mock, service.WithVariantStore(variants))
+	owner := userCtx(7)
+
+	_, err := svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com", CustomCode: "launch"})
+	require.NoError(t, err)
+
+	_, err = svc.SetVariants(owner, "launch", model.VariantsRequest{Variants: []model.VariantRequest{
+		{LongURL: "https://example.com/a"}, {LongURL: "https://example.com/b"},
+	}})
+	assert.Error(t, err, "weights can't all be 0")
+	_, err = svc.SetVariants(owner, "launch", model.VariantsRequest{Variants: []model.VariantRequest{
+		{ID: ptrInt64(99), LongURL: "https://example.com/a", Weight: 1},
+	}})
+	assert.Error(t, err, "unknown variant")
+	_, err = svc.SetVariants(userCtx(8), "launch", model.VariantsRequest{})
+	assert.ErrorIs(t, err, service.ErrForbidden)
+
+	list, err := svc.SetVariants(owner, "launch", model.VariantsRequest{Variants: []model.VariantRequest{
+		{Name: "A", LongURL: "https://example.com/a", Weight: 70},
+		{LongURL: "https://example.com/b", Weight: 30},
+	}})
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "Variant 2", list[1].Name)
+	a, b := list[0], list[1]
+
+	// Traffic splits by weight, and each visitor keeps their variant.
+	visits := &mockVisitStore{}
+	assigned := make(map[string]int64)
+	counts := make(map[int64]int)
+	for i := 0; i < 2000; i++ {
+		key := fmt.Sprintf("visitor-%d", i)
+		link, err := svc.Resolve(visitorWithKey(key), "launch", "")
+		require.NoError(t, err)
+		require.NotNil(t, link.VariantID)
+		assigned[key] = *link.VariantID
+		counts[*link.VariantID]++
+
+		again, err := svc.Resolve(visitorWithKey(key), "launch", "")
+		require.NoError(t, err)
+		assert.Equal(t, link.VariantID, again.VariantID)
+
+		_ = visits.LogVisit(context.Background(), model.Visit{Code: "launch", VariantID: link.VariantID, VisitorKey: key})
+		_ = visits.LogVisit(context.Background(), model.Visit{Code: "launch", VariantID: again.VariantID, VisitorKey: key})
+	}
+	assert.InDelta(t, 1400, counts[a.ID], 100)
+	assert.InDelta(t, 600, counts[b.ID], 100)
+
+	// Shifting weight towards B only moves visitors from A to B.
+	_, err = svc.SetVariants(owner, "launch", model.VariantsRequest{Variants: []model.VariantRequest{
+		{ID: &a.ID, Name: "A", LongURL: a.LongURL, Weight: 50},
+		{ID: &b.ID, Name: "B", LongURL: b.LongURL, Weight: 50},
+	}})
+	require.NoError(t, err)
+	for key, id := range assigned {
+		if id != b.ID {
+			continue
+		}
+		link, err := svc.Resolve(visitorWithKey(key), "launch", "")
+		require.NoError(t, err)
+		assert.Equal(t, b.ID, *link.VariantID)
+	}
+
+	// Dropping B archives it, keeping its name for analytics.
+	_, err = svc.SetVariants(owner, "launch", model.VariantsRequest{Variants: []model.VariantRequest{
+		{ID: &a.ID, Name: "A", LongURL: a.LongURL, Weight: 1},
+	}})
+	require.NoError(t, err)
+	link, err := svc.Resolve(visitorWithKey("visitor-1"), "launch", "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/a", link.LongURL)
+
+	_ = visits.LogVisit(context.Background(), model.Visit{Code: "launch", VisitorKey: "before-the-test"})
+	breakdown, err := service.NewVisitService(visits, svc, service.WithVariantNames(variants)).VariantBreakdown(owner, "launch")
+	require.NoError(t, err)
+	require.Len(t, breakdown, 3)
+	assert.Nil(t, breakdown[0].VariantID)
+	assert.Equal(t, 1, breakdown[0].Clicks)
+	assert.Equal(t, "A", breakdown[1].Name)
+	assert.Equal(t, "B", breakdown[2].Name)
+	assert.Equal(t, 2*counts[b.ID], breakdown[2].Clicks)
+	assert.Equal(t, counts[b.ID], breakdown[2].UniqueVisitors)
+}
@@ -2,27 +2,234 @@ package service
 
 import (
 	"context"
+	"sort"
+
 	"github.com/Kritvi0208/ShortEdge/model"
 	"github.com/Kritvi0208/ShortEdge/store"
 )
 
 type VisitService interface {
 	GetAnalytics(ctx context.Context, code string) ([]model.Visit, error)
+	GetFilteredAnalytics(ctx context.Context, filter model.URLFilter) ([]model.Visit, error)
 	LogVisit(ctx context.Context, visit model.Visit) error
+	RuleBreakdown(ctx context.Context, code string) ([]model.RuleClicks, error)
+	VariantBreakdown(ctx context.Context, code string) ([]model.VariantClicks, error)
+	SourceBreakdown(ctx context.Context, code string) ([]model.SourceClicks, error)
+
+	LogPreview(ctx context.Context, visit model.Visit) error
+	PreviewStats(ctx context.Context, code string) (model.PreviewStats, error)
+}
+
+// LinkAuthorizer decides whose analytics the caller may read; URLService implements it.
+type LinkAuthorizer interface {
+	AuthorizeAnalytics(ctx context.Context, code string) error
+	AnalyticsCodes(ctx context.Context, filter model.URLFilter) ([]string, error)
 }
 
 type visitService struct {
-	store store.Visit
+	store  store.Visit
+	links  LinkAuthorizer
+	quotas QuotaService
+
+	variants store.Variant
+}
+
+// VisitOption configures optional collaborators of the visit service.
+type VisitOption func(*visitService)
+
+// WithClickQuota stops tracking clicks of workspaces that used up their monthly click quota.
+func WithClickQuota(q QuotaService) VisitOption {
+	return func(v *visitService) { v.quotas = q }
+}
+
+// WithVariantNames names the variants in A/B breakdowns.
+func WithVariantNames(s store.Variant) VisitOption {
+	return func(v *visitService) { v.variants = s }
 }
 
-func NewVisitService(s store.Visit) VisitService {
-	return &visitService{store: s}
+func NewVisitService(s store.Visit, links LinkAuthorizer, opts ...VisitOption) VisitService {
+	v := &visitService{store: s, links: links}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
 }
 
 func (v *visitService) GetAnalytics(ctx context.Context, code string) ([]model.Visit, error) {
+	if err := v.links.AuthorizeAnalytics(ctx, code); err != nil {
+		return nil, err
+	}
 	return v.store.GetAnalytics(ctx, code)
 }
 
+// GetFilteredAnalytics returns the visits of every readable link matching filter at once, e.g.
+// every link of a workspace with a given tag.
+func (v *visitService) GetFilteredAnalytics(ctx context.Context, filter model.URLFilter) ([]model.Visit, error) {
+	codes, err := v.links.AnalyticsCodes(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(codes) == 0 {
+		return nil, nil
+	}
+	return v.store.GetAnalyticsForCodes(ctx, codes)
+}
+
+// LogVisit records a visit unless the link's workspace is over its click quota; the redirect
+// itself is never blocked.
 func (v *visitService) LogVisit(ctx context.Context, visit model.Visit) error {
+	if v.quotas != nil && visit.WorkspaceID != nil {
+		ok, err := v.quotas.CountClick(ctx, *visit.WorkspaceID)
+		if err != nil || !ok {
+			return err
+		}
+	}
 	return v.store.LogVisit(ctx, visit)
 }
+
+// RuleBreakdown counts the link's visits by the targeting rule that chose their destination,
+// most clicked first.
+func (v *visitService) RuleBreakdown(ctx context.Context, code string) ([]model.RuleClicks, error) {
+	visits, err := v.GetAnalytics(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int64]int)
+	defaults := 0
+	for _, visit := range visits {
+		if visit.RuleID == nil {
+			defaults++
+			continue
+		}
+		counts[*visit.RuleID]++
+	}
+
+	breakdown := []model.RuleClicks{}
+	if defaults > 0 {
+		breakdown = append(breakdown, model.RuleClicks{Clicks: defaults})
+	}
+	for id, n := range counts {
+		breakdown = append(breakdown, model.RuleClicks{RuleID: &id, Clicks: n})
+	}
+
+	sort.Slice(breakdown, func(i, j int) bool {
+		if breakdown[i].Clicks != breakdown[j].Clicks {
+			return breakdown[i].Clicks > breakdown[j].Clicks
+		}
+		return idOrder(breakdown[i].RuleID) < idOrder(breakdown[j].RuleID)
+	})
+	return breakdown, nil
+}
+
+// idOrder sorts the default destination, with a nil ID, before rules and variants.
+func idOrder(id *int64) int64 {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
+
+// VariantBreakdown counts the link's clicks and unique visitors by the A/B variant they were
+// sent to, in the order the variants were added. Archived variants keep their counts.
+func (v *visitService) VariantBreakdown(ctx context.Context, code string) ([]model.VariantClicks, error) {
+	visits, err := v.GetAnalytics(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int64]string)
+	if v.variants != nil {
+		variants, err := v.variants.Variants(ctx, code, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, variant := range variants {
+			names[variant.ID] = variant.Name
+		}
+	}
+
+	type tally struct {
+		clicks   int
+		visitors map[string]bool
+	}
+	tallies := make(map[int64]*tally)
+	var split tally // visits not sent to a variant
+	split.visitors = make(map[string]bool)
+
+	for _, visit := range visits {
+		t := &split
+		if visit.VariantID != nil {
+			if tallies[*visit.VariantID] == nil {
+				tallies[*visit.VariantID] = &tally{visitors: make(map[string]bool)}
+			}
+			t = tallies[*visit.VariantID]
+		}
+		t.clicks++
+		if visit.VisitorKey != "" {
+			t.visitors[visit.VisitorKey] = true
+		}
+	}
+
+	breakdown := []model.VariantClicks{}
+	if split.clicks > 0 {
+		breakdown = append(breakdown, model.VariantClicks{Clicks: split.clicks, UniqueVisitors: len(split.visitors)})
+	}
+	for id, t := range tallies {
+		breakdown = append(breakdown, model.VariantClicks{VariantID: &id, Name: names[id], Clicks: t.clicks,
+			UniqueVisitors: len(t.visitors)})
+	}
+
+	sort.Slice(breakdown, func(i, j int) bool {
+		return idOrder(breakdown[i].VariantID) < idOrder(breakdown[j].VariantID)
+	})
+	return breakdown, nil
+}
+
+// SourceBreakdown counts the link's clicks by the channel they came from, such as QR code scans,
+// most clicked first.
+func (v *visitService) SourceBreakdown(ctx context.Context, code string) ([]model.SourceClicks, error) {
+	visits, err := v.GetAnalytics(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, visit := range visits {
+		counts[visit.Source]++
+	}
+
+	breakdown := []model.SourceClicks{}
+	for source, n := range counts {
+		breakdown = append(breakdown, model.SourceClicks{Source: source, Clicks: n})
+	}
+
+	sort.Slice(breakdown, func(i, j int) bool {
+		if breakdown[i].Clicks != breakdown[j].Clicks {
+			return breakdown[i].Clicks > breakdown[j].Clicks
+		}
+		return breakdown[i].Source < breakdown[j].Source
+	})
+	return breakdown, nil
+}
+
+// LogPreview records a view of a link's preview page. Previews aren't clicks, so they don't count
+// against click quotas.
+func (v *visitService) LogPreview(ctx context.Context, visit model.Visit) error {
+	return v.store.LogPreview(ctx, visit)
+}
+
+// PreviewStats counts the link's preview views next to its clicks.
+func (v *visitService) PreviewStats(ctx context.Context, code string) (model.PreviewStats, error) {
+	visits, err := v.GetAnalytics(ctx, code)
+	if err != nil {
+		return model.PreviewStats{}, err
+	}
+
+	views, visitors, err := v.store.PreviewCounts(ctx, code)
+	if err != nil {
+		return model.PreviewStats{}, err
+	}
+
+	return model.PreviewStats{Code: code, Previews: views, UniqueVisitors: visitors, Clicks: len(visits)}, nil
+}
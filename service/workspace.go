@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/auth"
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/store"
+)
+
+type WorkspaceService interface {
+	List(ctx context.Context) ([]model.Workspace, error)
+	Create(ctx context.Context, req model.WorkspaceRequest) (model.Workspace, error)
+	Rename(ctx context.Context, id int64, req model.WorkspaceRequest) (model.Workspace, error)
+
+	Members(ctx context.Context, id int64) ([]model.Member, error)
+	SetMemberRole(ctx context.Context, id, userID int64, req model.MemberRoleRequest) error
+	RemoveMember(ctx context.Context, id, userID int64) error
+
+	Invite(ctx context.Context, id int64, req model.InvitationRequest) (model.Invitation, error)
+	Invitations(ctx context.Context, id int64) ([]model.Invitation, error)
+	RevokeInvitation(ctx context.Context, id, invitationID int64) error
+	AcceptInvitation(ctx context.Context, req model.AcceptInvitationRequest) (model.Workspace, error)
+}
+
+type workspaceService struct {
+	store         store.Workspace
+	invitationTTL time.Duration
+}
+
+// NewWorkspaceService manages workspaces and their members; invitations expire after invitationTTL.
+func NewWorkspaceService(s store.Workspace, invitationTTL time.Duration) WorkspaceService {
+	return &workspaceService{store: s, invitationTTL: invitationTTL}
+}
+
+var workspaceRoleRank = map[string]int{
+	model.WorkspaceViewer: 1,
+	model.WorkspaceEditor: 2,
+	model.WorkspaceAdmin:  3,
+	model.WorkspaceOwner:  4,
+}
+
+// hasRole reports whether role grants at least the rights of min.
+func hasRole(role, min string) bool {
+	return role != "" && workspaceRoleRank[role] >= workspaceRoleRank[min]
+}
+
+func normalizeWorkspaceRole(role string) (string, error) {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if _, ok := workspaceRoleRank[role]; !ok {
+		return "", fmt.Errorf("role must be owner, admin, editor or viewer")
+	}
+	return role, nil
+}
+
+// workspaceRole returns the caller's role in a workspace; platform admins act as owners
+// everywhere. Non-members get an empty role.
+func workspaceRole(ctx context.Context, s store.Workspace, p auth.Principal, id int64) (string, error) {
+	if p.IsAdmin() {
+		return model.WorkspaceOwner, nil
+	}
+
+	role, err := s.Role(ctx, id, p.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return role, err
+}
+
+// require checks the caller has at least role min in the workspace and returns their role.
+func (w *workspaceService) require(ctx context.Context, id int64, min string) (auth.Principal, string, error) {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return auth.Principal{}, "", ErrUnauthenticated
+	}
+
+	role, err := workspaceRole(ctx, w.store, p, id)
+	if err != nil {
+		return auth.Principal{}, "", err
+	}
+	if role == "" {
+		return auth.Principal{}, "", ErrNotMember
+	}
+	if !hasRole(role, min) {
+		return auth.Principal{}, "", ErrWorkspaceRole
+	}
+
+	return p, role, nil
+}
+
+func (w *workspaceService) List(ctx context.Context) ([]model.Workspace, error) {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	return w.store.ListForUser(ctx, p.UserID)
+}
+
+func (w *workspaceService) Create(ctx context.Context, req model.WorkspaceRequest) (model.Workspace, error) {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return model.Workspace{}, ErrUnauthenticated
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return model.Workspace{}, fmt.Errorf("workspace name is required")
+	}
+	if req.CodeStyle != nil {
+		if err := validCodeStyle(*req.CodeStyle); err != nil {
+			return model.Workspace{}, err
+		}
+	}
+
+	ws, err := w.store.Create(ctx, model.Workspace{Name: name, CreatedAt: time.Now()}, p.UserID)
+	if err != nil || req.CodeStyle == nil || *req.CodeStyle == "" {
+		return ws, err
+	}
+	ws.CodeStyle = *req.CodeStyle
+	return ws, w.store.SetCodeStyle(ctx, ws.ID, ws.CodeStyle)
+}
+
+func (w *workspaceService) Rename(ctx context.Context, id int64, req model.WorkspaceRequest) (model.Workspace, error) {
+	_, role, err := w.require(ctx, id, model.WorkspaceAdmin)
+	if err != nil {
+		return model.Workspace{}, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return model.Workspace{}, fmt.Errorf("workspace name is required")
+	}
+	if req.CodeStyle != nil {
+		if err := validCodeStyle(*req.CodeStyle); err != nil {
+			return model.Workspace{}, err
+		}
+	}
+
+	if err := w.store.Rename(ctx, id, name); err != nil {
+		return model.Workspace{}, err
+	}
+	if req.CodeStyle != nil {
+		if err := w.store.SetCodeStyle(ctx, id, *req.CodeStyle); err != nil {
+			return model.Workspace{}, err
+		}
+	}
+
+	ws, err := w.store.GetByID(ctx, id)
+	ws.Role = role
+	return ws, err
+}
+
+func (w *workspaceService) Members(ctx context.Context, id int64) ([]model.Member, error) {
+	if _, _, err := w.require(ctx, id, model.WorkspaceViewer); err != nil {
+		return nil, err
+	}
+
+	return w.store.Members(ctx, id)
+}
+
+// SetMemberRole changes a member's role. Only owners may grant or take away ownership, and the
+// last owner can't be demoted.
+func (w *workspaceService) SetMemberRole(ctx context.Context, id, userID int64, req model.MemberRoleRequest) error {
+	_, callerRole, err := w.require(ctx, id, model.WorkspaceAdmin)
+	if err != nil {
+		return err
+	}
+
+	role, err := normalizeWorkspaceRole(req.Role)
+	if err != nil {
+		return err
+	}
+
+	current, err := w.memberRole(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := w.checkOwnerChange(ctx, id, callerRole, current, role); err != nil {
+		return err
+	}
+
+	return w.store.SetRole(ctx, id, userID, role)
+}
+
+// RemoveMember removes a member; any member may leave on their own.
+func (w *workspaceService) RemoveMember(ctx context.Context, id, userID int64) error {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+
+	min := model.WorkspaceAdmin
+	if p.UserID == userID {
+		min = model.WorkspaceViewer
+	}
+
+	_, callerRole, err := w.require(ctx, id, min)
+	if err != nil {
+		return err
+	}
+
+	current, err := w.memberRole(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if p.UserID == userID {
+		// Leaving never needs more rights than the member already has.
+		callerRole = current
+	}
+	if err := w.checkOwnerChange(ctx, id, callerRole, current, ""); err != nil {
+		return err
+	}
+
+	return w.store.RemoveMember(ctx, id, userID)
+}
+
+func (w *workspaceService) memberRole(ctx context.Context, id, userID int64) (string, error) {
+	role, err := w.store.Role(ctx, id, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("user %d is not a member of this workspace", userID)
+	}
+	return role, err
+}
+
+// checkOwnerChange guards changes from role current to next ("" for removal).
+func (w *workspaceService) checkOwnerChange(ctx context.Context, id int64, callerRole, current, next string) error {
+	if current != model.WorkspaceOwner && next != model.WorkspaceOwner {
+		return nil
+	}
+	if callerRole != model.WorkspaceOwner {
+		return ErrWorkspaceRole
+	}
+	if current != model.WorkspaceOwner || next == model.WorkspaceOwner {
+		return nil
+	}
+
+	owners, err := w.store.CountOwners(ctx, id)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return fmt.Errorf("a workspace needs at least one owner")
+	}
+	return nil
+}
+
+// Invite creates an invitation for email. The returned token is only shown once.
+func (w *workspaceService) Invite(ctx context.Context, id int64, req model.InvitationRequest) (model.Invitation, error) {
+	p, callerRole, err := w.require(ctx, id, model.WorkspaceAdmin)
+	if err != nil {
+		return model.Invitation{}, err
+	}
+
+	email := normalizeEmail(req.Email)
+	if _, err := mail.ParseAddress(email); err != nil || email == "" {
+		return model.Invitation{}, fmt.Errorf("a valid email is required")
+	}
+
+	role, err := normalizeWorkspaceRole(req.Role)
+	if err != nil {
+		return model.Invitation{}, err
+	}
+	if role == model.WorkspaceOwner && callerRole != model.WorkspaceOwner {
+		return model.Invitation{}, ErrWorkspaceRole
+	}
+
+	token, err := auth.NewToken("inv_")
+	if err != nil {
+		return model.Invitation{}, err
+	}
+
+	now := time.Now()
+	inv, err := w.store.CreateInvitation(ctx, model.Invitation{
+		WorkspaceID: id,
+		Email:       email,
+		Role:        role,
+		InvitedBy:   p.UserID,
+		ExpiresAt:   now.Add(w.invitationTTL),
+		CreatedAt:   now,
+	}, auth.HashToken(token))
+	if err != nil {
+		return model.Invitation{}, err
+	}
+
+	inv.Token = token
+	return inv, nil
+}
+
+func (w *workspaceService) Invitations(ctx context.Context, id int64) ([]model.Invitation, error) {
+	if _, _, err := w.require(ctx, id, model.WorkspaceAdmin); err != nil {
+		return nil, err
+	}
+
+	return w.store.Invitations(ctx, id)
+}
+
+func (w *workspaceService) RevokeInvitation(ctx context.Context, id, invitationID int64) error {
+	if _, _, err := w.require(ctx, id, model.WorkspaceAdmin); err != nil {
+		return err
+	}
+
+	inv, err := w.store.GetInvitation(ctx, invitationID)
+	if err != nil || inv.WorkspaceID != id {
+		return fmt.Errorf("invitation not found")
+	}
+
+	return w.store.DeleteInvitation(ctx, invitationID)
+}
+
+// AcceptInvitation joins the caller to the invitation's workspace. It must be addressed to the
+// caller's email.
+func (w *workspaceService) AcceptInvitation(ctx context.Context, req model.AcceptInvitationRequest) (model.Workspace, error) {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return model.Workspace{}, ErrUnauthenticated
+	}
+
+	inv, err := w.store.GetInvitationByHash(ctx, auth.HashToken(strings.TrimSpace(req.Token)))
+	if err != nil || inv.Email != normalizeEmail(p.Email) {
+		return model.Workspace{}, fmt.Errorf("invitation not found")
+	}
+
+	now := time.Now()
+	if inv.AcceptedAt != nil || !inv.ExpiresAt.After(now) {
+		return model.Workspace{}, fmt.Errorf("invitation has expired or was already used")
+	}
+
+	if err := w.store.AcceptInvitation(ctx, inv, p.UserID, now); err != nil {
+		return model.Workspace{}, err
+	}
+
+	ws, err := w.store.GetByID(ctx, inv.WorkspaceID)
+	if err != nil {
+		return model.Workspace{}, err
+	}
+	ws.Role, err = w.store.Role(ctx, ws.ID, p.UserID)
+	return ws, err
+}
@@ -0,0 +1,283 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/auth"
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWorkspaceStore struct {
+	workspaces  map[int64]model.Workspace
+	members     map[int64]map[int64]string // workspace -> user -> role
+	invitations map[int64]model.Invitation
+	hashes      map[string]int64
+}
+
+func newMockWorkspaceStore() *mockWorkspaceStore {
+	return &mockWorkspaceStore{
+		workspaces:  make(map[int64]model.Workspace),
+		members:     make(map[int64]map[int64]string),
+		invitations: make(map[int64]model.Invitation),
+		hashes:      make(map[string]int64),
+	}
+}
+
+func (m *mockWorkspaceStore) Create(ctx context.Context, ws model.Workspace, ownerID int64) (model.Workspace, error) {
+	ws.ID = int64(len(m.workspaces) + 1)
+	m.workspaces[ws.ID] = ws
+	m.members[ws.ID] = map[int64]string{ownerID: model.WorkspaceOwner}
+	ws.Role = model.WorkspaceOwner
+	return ws, nil
+}
+
+func (m *mockWorkspaceStore) GetByID(ctx context.Context, id int64) (model.Workspace, error) {
+	ws, ok := m.workspaces[id]
+	if !ok {
+		return model.Workspace{}, sql.ErrNoRows
+	}
+	return ws, nil
+}
+
+func (m *mockWorkspaceStore) ListForUser(ctx context.Context, userID int64) ([]model.Workspace, error) {
+	var list []model.Workspace
+	for id, members := range m.members {
+		if role, ok := members[userID]; ok {
+			ws := m.workspaces[id]
+			ws.Role = role
+			list = append(list, ws)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockWorkspaceStore) Rename(ctx context.Context, id int64, name string) error {
+	ws := m.workspaces[id]
+	ws.Name = name
+	m.workspaces[id] = ws
+	return nil
+}
+
+func (m *mockWorkspaceStore) SetPlan(ctx context.Context, id int64, plan string) error {
+	ws := m.workspaces[id]
+	ws.Plan = plan
+	m.workspaces[id] = ws
+	return nil
+}
+
+func (m *mockWorkspaceStore) SetCodeStyle(ctx context.Context, id int64, style string) error {
+	ws := m.workspaces[id]
+	ws.CodeStyle = style
+	m.workspaces[id] = ws
+	return nil
+}
+
+func (m *mockWorkspaceStore) Role(ctx context.Context, workspaceID, userID int64) (string, error) {
+	role, ok := m.members[workspaceID][userID]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	return role, nil
+}
+
+func (m *mockWorkspaceStore) Roles(ctx context.Context, userID int64) (map[int64]string, error) {
+	roles := make(map[int64]string)
+	for id, members := range m.members {
+		if role, ok := members[userID]; ok {
+			roles[id] = role
+		}
+	}
+	return roles, nil
+}
+
+func (m *mockWorkspaceStore) Members(ctx context.Context, workspaceID int64) ([]model.Member, error) {
+	var list []model.Member
+	for userID, role := range m.members[workspaceID] {
+		list = append(list, model.Member{WorkspaceID: workspaceID, UserID: userID, Role: role})
+	}
+	return list, nil
+}
+
+func (m *mockWorkspaceStore) AddMember(ctx context.Context, workspaceID, userID int64, role string) error {
+	if m.members[workspaceID] == nil {
+		m.members[workspaceID] = make(map[int64]string)
+	}
+	m.members[workspaceID][userID] = role
+	return nil
+}
+
+func (m *mockWorkspaceStore) SetRole(ctx context.Context, workspaceID, userID int64, role string) error {
+	m.members[workspaceID][userID] = role
+	return nil
+}
+
+func (m *mockWorkspaceStore) RemoveMember(ctx context.Context, workspaceID, userID int64) error {
+	delete(m.members[workspaceID], userID)
+	return nil
+}
+
+func (m *mockWorkspaceStore) CountOwners(ctx context.Context, workspaceID int64) (int, error) {
+	n := 0
+	for _, role := range m.members[workspaceID] {
+		if role == model.WorkspaceOwner {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *mockWorkspaceStore) CreateInvitation(ctx context.Context, inv model.Invitation, tokenHash string) (model.Invitation, error) {
+	inv.ID = int64(len(m.invitations) + 1)
+	m.invitations[inv.ID] = inv
+	m.hashes[tokenHash] = inv.ID
+	return inv, nil
+}
+
+func (m *mockWorkspaceStore) GetInvitation(ctx context.Context, id int64) (model.Invitation, error) {
+	inv, ok := m.invitations[id]
+	if !ok {
+		return model.Invitation{}, sql.ErrNoRows
+	}
+	return inv, nil
+}
+
+func (m *mockWorkspaceStore) GetInvitationByHash(ctx context.Context, tokenHash string) (model.Invitation, error) {
+	id, ok := m.hashes[tokenHash]
+	if !ok {
+		return model.Invitation{}, sql.ErrNoRows
+	}
+	return m.invitations[id], nil
+}
+
+func (m *mockWorkspaceStore) Invitations(ctx context.Context, workspaceID int64) ([]model.Invitation, error) {
+	var list []model.Invitation
+	for _, inv := range m.invitations {
+		if inv.WorkspaceID == workspaceID {
+			list = append(list, inv)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockWorkspaceStore) DeleteInvitation(ctx context.Context, id int64) error {
+	delete(m.invitations, id)
+	return nil
+}
+
+func (m *mockWorkspaceStore) AcceptInvitation(ctx context.Context, inv model.Invitation, userID int64, at time.Time) error {
+	inv.AcceptedAt = &at
+	m.invitations[inv.ID] = inv
+	if _, ok := m.members[inv.WorkspaceID][userID]; !ok {
+		m.members[inv.WorkspaceID][userID] = inv.Role
+	}
+	return nil
+}
+
+func memberCtx(userID, workspaceID int64, email string) context.Context {
+	return auth.WithPrincipal(context.Background(),
+		auth.Principal{UserID: userID, Email: email, Role: auth.RoleUser, WorkspaceID: workspaceID})
+}
+
+func TestWorkspaceInvitationsAndRoles(t *testing.T) {
+	store := newMockWorkspaceStore()
+	svc := service.NewWorkspaceService(store, time.Hour)
+
+	owner := memberCtx(1, 0, "owner@example.com")
+	ws, err := svc.Create(owner, model.WorkspaceRequest{Name: "Marketing"})
+	require.NoError(t, err)
+
+	inv, err := svc.Invite(owner, ws.ID, model.InvitationRequest{Email: "Editor@Example.com", Role: "editor"})
+	require.NoError(t, err)
+	require.NotEmpty(t, inv.Token)
+
+	// Only the invited address can accept.
+	_, err = svc.AcceptInvitation(memberCtx(3, 0, "other@example.com"), model.AcceptInvitationRequest{Token: inv.Token})
+	assert.Error(t, err)
+
+	editor := memberCtx(2, 0, "editor@example.com")
+	joined, err := svc.AcceptInvitation(editor, model.AcceptInvitationRequest{Token: inv.Token})
+	require.NoError(t, err)
+	assert.Equal(t, model.WorkspaceEditor, joined.Role)
+
+	_, err = svc.AcceptInvitation(editor, model.AcceptInvitationRequest{Token: inv.Token})
+	assert.Error(t, err)
+
+	// Editors can't manage members.
+	_, err = svc.Invite(editor, ws.ID, model.InvitationRequest{Email: "x@example.com", Role: "viewer"})
+	assert.ErrorIs(t, err, service.ErrWorkspaceRole)
+	_, err = svc.Members(memberCtx(3, 0, "other@example.com"), ws.ID)
+	assert.ErrorIs(t, err, service.ErrNotMember)
+
+	// Admins can't hand out ownership; the last owner can't step down.
+	require.NoError(t, svc.SetMemberRole(owner, ws.ID, 2, model.MemberRoleRequest{Role: "admin"}))
+	assert.ErrorIs(t, svc.SetMemberRole(editor, ws.ID, 2, model.MemberRoleRequest{Role: "owner"}), service.ErrWorkspaceRole)
+	assert.Error(t, svc.SetMemberRole(owner, ws.ID, 1, model.MemberRoleRequest{Role: "viewer"}))
+	assert.Error(t, svc.RemoveMember(owner, ws.ID, 1))
+
+	// Anyone can leave.
+	require.NoError(t, svc.RemoveMember(editor, ws.ID, 2))
+	_, err = svc.Members(editor, ws.ID)
+	assert.ErrorIs(t, err, service.ErrNotMember)
+}
+
+func TestURLService_WorkspaceScoping(t *testing.T) {
+	workspaces := newMockWorkspaceStore()
+	team, _ := workspaces.Create(context.Background(), model.Workspace{Name: "team"}, 1)
+	other, _ := workspaces.Create(context.Background(), model.Workspace{Name: "other"}, 9)
+	workspaces.members[team.ID][2] = model.WorkspaceViewer
+	workspaces.members[other.ID][1] = model.WorkspaceEditor
+
+	urls := newMockStore()
+	svc := service.New(urls, service.WithWorkspaceStore(workspaces))
+
+	owner := memberCtx(1, team.ID, "owner@example.com")
+	viewer := memberCtx(2, team.ID, "viewer@example.com")
+	outsider := memberCtx(9, other.ID, "outsider@example.com")
+
+	link, err := svc.Shorten(owner, model.ShortenRequest{LongURL: "https://example.com", CustomCode: "team1",
+		Visibility: "private"})
+	require.NoError(t, err)
+	assert.Equal(t, team.ID, *link.WorkspaceID)
+
+	_, err = svc.Shorten(viewer, model.ShortenRequest{LongURL: "https://example.com"})
+	assert.ErrorIs(t, err, service.ErrWorkspaceRole)
+
+	// Listing defaults to the caller's workspace and includes private links of members.
+	all, err := svc.GetAll(viewer, model.URLFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"team1"}, codesOf(all))
+
+	all, err = svc.GetAll(outsider, model.URLFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, all)
+
+	_, err = svc.GetAll(outsider, model.URLFilter{WorkspaceID: &team.ID})
+	assert.ErrorIs(t, err, service.ErrNotMember)
+
+	// Viewers read analytics but can't change links.
+	assert.NoError(t, svc.AuthorizeAnalytics(viewer, "team1"))
+	assert.ErrorIs(t, svc.AuthorizeAnalytics(outsider, "team1"), service.ErrForbidden)
+	_, err = svc.Update(viewer, "team1", model.ShortenRequest{LongURL: "https://changed.com"})
+	assert.ErrorIs(t, err, service.ErrForbidden)
+
+	_, err = svc.Resolve(outsider, "team1", "")
+	assert.ErrorIs(t, err, service.ErrPrivateLink)
+
+	// Moving keeps the code, so visits recorded for it stay attached.
+	_, err = svc.Move(viewer, "team1", other.ID)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+
+	moved, err := svc.Move(owner, "team1", other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "team1", moved.Code)
+	assert.Equal(t, other.ID, *urls.urls["team1"].WorkspaceID)
+
+	assert.NoError(t, svc.AuthorizeAnalytics(outsider, "team1"))
+	assert.ErrorIs(t, svc.AuthorizeAnalytics(viewer, "team1"), service.ErrForbidden)
+}
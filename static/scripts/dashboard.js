@@ -34,7 +34,9 @@ document.addEventListener("DOMContentLoaded", async function () {
         `;
 
         myLinks.forEach(link => {
-            const shortURL = `${window.location.origin}/${link.code}`;
+            const shortURL = link.domain
+                ? `https://${link.domain}/${link.code.split("@")[0]}`
+                : `${window.location.origin}/${link.code}`;
             html += `
               <tr>
                 <td><a href="${shortURL}" target="_blank">${shortURL}</a></td>
@@ -36,7 +36,10 @@ document.addEventListener("DOMContentLoaded", function () {
             const data = await res.json();
 
             if (res.ok) {
-                const fullLink = `${window.location.origin}/${data.code}`;
+                // Links on branded domains have codes qualified as code@domain
+                const fullLink = data.domain
+                    ? `https://${data.domain}/${data.code.split("@")[0]}`
+                    : `${window.location.origin}/${data.code}`;
                 resultDiv.innerHTML = `
                     <p>✅ Short link created:</p>
                     <input type="text" value="${fullLink}" readonly style="width: 300px;" />
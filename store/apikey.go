@@ -0,0 +1,117 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/lib/pq"
+)
+
+type APIKey interface {
+	Create(ctx context.Context, key model.APIKey, hash string) (model.APIKey, error)
+	GetByID(ctx context.Context, id int64) (model.APIKey, error)
+	GetByHash(ctx context.Context, hash string) (model.APIKey, error)
+	ListByUser(ctx context.Context, userID int64) ([]model.APIKey, error)
+	Revoke(ctx context.Context, id int64, at time.Time) error
+	Rotate(ctx context.Context, oldID int64, key model.APIKey, hash string) (model.APIKey, error)
+	TouchLastUsed(ctx context.Context, id int64, at time.Time) error
+}
+
+type apiKeyStore struct {
+	db *sql.DB
+}
+
+func NewAPIKeyStore(db *sql.DB) APIKey {
+	return &apiKeyStore{db: db}
+}
+
+const apiKeyColumns = `id, user_id, name, prefix, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+func scanAPIKey(row interface{ Scan(...any) error }) (model.APIKey, error) {
+	var k model.APIKey
+	err := row.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, pq.Array(&k.Scopes), &k.ExpiresAt, &k.LastUsedAt,
+		&k.RevokedAt, &k.CreatedAt)
+	return k, err
+}
+
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func insertAPIKey(ctx context.Context, db queryRower, key model.APIKey, hash string) (model.APIKey, error) {
+	err := db.QueryRowContext(ctx,
+		`INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at, created_at)
+	 VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+		key.UserID, key.Name, key.Prefix, hash, pq.Array(key.Scopes), key.ExpiresAt, key.CreatedAt).Scan(&key.ID)
+	return key, err
+}
+
+func (s *apiKeyStore) Create(ctx context.Context, key model.APIKey, hash string) (model.APIKey, error) {
+	return insertAPIKey(ctx, s.db, key, hash)
+}
+
+func (s *apiKeyStore) GetByID(ctx context.Context, id int64) (model.APIKey, error) {
+	return scanAPIKey(s.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1`, id))
+}
+
+func (s *apiKeyStore) GetByHash(ctx context.Context, hash string) (model.APIKey, error) {
+	return scanAPIKey(s.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, hash))
+}
+
+func (s *apiKeyStore) ListByUser(ctx context.Context, userID int64) ([]model.APIKey, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []model.APIKey
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+func (s *apiKeyStore) Revoke(ctx context.Context, id int64, at time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`, at, id)
+	return err
+}
+
+// Rotate revokes oldID and creates its replacement in one transaction, so there is never a
+// moment with both or neither key valid.
+func (s *apiKeyStore) Rotate(ctx context.Context, oldID int64, key model.APIKey, hash string) (model.APIKey, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.APIKey{}, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`, key.CreatedAt, oldID)
+	if err != nil {
+		return model.APIKey{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return model.APIKey{}, sql.ErrNoRows
+	}
+
+	key, err = insertAPIKey(ctx, tx, key, hash)
+	if err != nil {
+		return model.APIKey{}, err
+	}
+
+	return key, tx.Commit()
+}
+
+func (s *apiKeyStore) TouchLastUsed(ctx context.Context, id int64, at time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = $1 WHERE id = $2`, at, id)
+	return err
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+)
+
+type Domain interface {
+	Create(ctx context.Context, d model.Domain) (model.Domain, error)
+	GetByID(ctx context.Context, id int64) (model.Domain, error)
+	GetByName(ctx context.Context, name string) (model.Domain, error)
+	ListForWorkspace(ctx context.Context, workspaceID int64) ([]model.Domain, error)
+	Update(ctx context.Context, d model.Domain) error
+	MarkVerified(ctx context.Context, id int64, at time.Time) error
+	Delete(ctx context.Context, id int64) error
+	// InUse reports whether any link is served from the domain.
+	InUse(ctx context.Context, name string) (bool, error)
+}
+
+type domainStore struct {
+	db *sql.DB
+}
+
+func NewDomainStore(db *sql.DB) Domain {
+	return &domainStore{db: db}
+}
+
+const domainColumns = `id, name, workspace_id, COALESCE(root_url, ''), COALESCE(not_found_url, ''), COALESCE(code_style, ''),
+	token, verified_at, created_at`
+
+func scanDomain(row interface{ Scan(...any) error }) (model.Domain, error) {
+	var d model.Domain
+	err := row.Scan(&d.ID, &d.Name, &d.WorkspaceID, &d.RootURL, &d.NotFoundURL, &d.CodeStyle, &d.Token, &d.VerifiedAt, &d.CreatedAt)
+	return d, err
+}
+
+func (s *domainStore) Create(ctx context.Context, d model.Domain) (model.Domain, error) {
+	err := s.db.QueryRowContext(ctx,
+		`INSERT INTO domains (name, workspace_id, root_url, not_found_url, code_style, token, created_at)
+	 VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), $6, $7) RETURNING id`,
+		d.Name, d.WorkspaceID, d.RootURL, d.NotFoundURL, d.CodeStyle, d.Token, d.CreatedAt).Scan(&d.ID)
+	return d, err
+}
+
+func (s *domainStore) GetByID(ctx context.Context, id int64) (model.Domain, error) {
+	return scanDomain(s.db.QueryRowContext(ctx, `SELECT `+domainColumns+` FROM domains WHERE id = $1`, id))
+}
+
+func (s *domainStore) GetByName(ctx context.Context, name string) (model.Domain, error) {
+	return scanDomain(s.db.QueryRowContext(ctx, `SELECT `+domainColumns+` FROM domains WHERE name = $1`, name))
+}
+
+func (s *domainStore) ListForWorkspace(ctx context.Context, workspaceID int64) ([]model.Domain, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+domainColumns+` FROM domains WHERE workspace_id = $1 ORDER BY name`, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var domains []model.Domain
+	for rows.Next() {
+		d, err := scanDomain(rows)
+		if err != nil {
+			return nil, err
+		}
+		domains = append(domains, d)
+	}
+	return domains, rows.Err()
+}
+
+func (s *domainStore) Update(ctx context.Context, d model.Domain) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE domains SET root_url = NULLIF($2, ''), not_found_url = NULLIF($3, ''), code_style = NULLIF($4, '') WHERE id = $1`,
+		d.ID, d.RootURL, d.NotFoundURL, d.CodeStyle)
+	return err
+}
+
+func (s *domainStore) MarkVerified(ctx context.Context, id int64, at time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE domains SET verified_at = $2 WHERE id = $1`, id, at)
+	return err
+}
+
+func (s *domainStore) Delete(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM domains WHERE id = $1`, id)
+	return err
+}
+
+func (s *domainStore) InUse(ctx context.Context, name string) (bool, error) {
+	var used bool
+	err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM urls WHERE domain = $1)`, name).Scan(&used)
+	return used, err
+}
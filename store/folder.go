@@ -0,0 +1,95 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+)
+
+type Folder interface {
+	Create(ctx context.Context, f model.Folder) (model.Folder, error)
+	GetAll(ctx context.Context) ([]model.Folder, error)
+	GetByID(ctx context.Context, id int64) (model.Folder, error)
+	Update(ctx context.Context, f model.Folder) error
+	Delete(ctx context.Context, id int64) error
+	Descendants(ctx context.Context, id int64) ([]int64, error)
+}
+
+type folderStore struct {
+	db *sql.DB
+}
+
+func NewFolderStore(db *sql.DB) Folder {
+	return &folderStore{db: db}
+}
+
+func (s *folderStore) Create(ctx context.Context, f model.Folder) (model.Folder, error) {
+	err := s.db.QueryRowContext(ctx,
+		`INSERT INTO folders (name, parent_id, created_at) VALUES ($1, $2, $3) RETURNING id`,
+		f.Name, f.ParentID, f.CreatedAt).Scan(&f.ID)
+	return f, err
+}
+
+func (s *folderStore) GetAll(ctx context.Context) ([]model.Folder, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, name, parent_id, created_at FROM folders ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var folders []model.Folder
+	for rows.Next() {
+		var f model.Folder
+		if err = rows.Scan(&f.ID, &f.Name, &f.ParentID, &f.CreatedAt); err != nil {
+			return nil, err
+		}
+		folders = append(folders, f)
+	}
+	return folders, rows.Err()
+}
+
+func (s *folderStore) GetByID(ctx context.Context, id int64) (model.Folder, error) {
+	var f model.Folder
+	err := s.db.QueryRowContext(ctx,
+		`SELECT id, name, parent_id, created_at FROM folders WHERE id = $1`, id).
+		Scan(&f.ID, &f.Name, &f.ParentID, &f.CreatedAt)
+	return f, err
+}
+
+func (s *folderStore) Update(ctx context.Context, f model.Folder) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE folders SET name = $1, parent_id = $2 WHERE id = $3`, f.Name, f.ParentID, f.ID)
+	return err
+}
+
+// Delete removes the folder together with its sub-folders; links inside are moved to the top level.
+func (s *folderStore) Delete(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM folders WHERE id = $1`, id)
+	return err
+}
+
+// Descendants returns id and the ids of every folder nested below it.
+func (s *folderStore) Descendants(ctx context.Context, id int64) ([]int64, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`WITH RECURSIVE tree AS (
+		SELECT id FROM folders WHERE id = $1
+		UNION ALL
+		SELECT f.id FROM folders f JOIN tree t ON f.parent_id = t.id
+	 )
+	 SELECT id FROM tree`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var fid int64
+		if err = rows.Scan(&fid); err != nil {
+			return nil, err
+		}
+		ids = append(ids, fid)
+	}
+	return ids, rows.Err()
+}
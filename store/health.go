@@ -0,0 +1,127 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+)
+
+type Health interface {
+	Upsert(ctx context.Context, code string, h model.LinkHealth) error
+	GetByCode(ctx context.Context, code string) (model.LinkHealth, error)
+	GetAllByCode(ctx context.Context) (map[string]model.LinkHealth, error)
+	// ListDue returns active links never checked or last checked before checkedBefore.
+	ListDue(ctx context.Context, checkedBefore time.Time, limit int) ([]model.URL, error)
+	Delete(ctx context.Context, code string) error
+}
+
+type healthStore struct {
+	db *sql.DB
+}
+
+func NewHealthStore(db *sql.DB) Health {
+	return &healthStore{db: db}
+}
+
+const healthColumns = `status, method, status_code, latency_ms, tls_expires_at, redirect_chain, error,
+	consecutive_failures, last_ok_at, checked_at`
+
+func scanHealth(row interface{ Scan(...any) error }, code *string, h *model.LinkHealth) error {
+	var chain string
+	dest := []any{&h.Status, &h.Method, &h.StatusCode, &h.LatencyMS, &h.TLSExpiresAt, &chain, &h.Error,
+		&h.ConsecutiveFailures, &h.LastOKAt, &h.CheckedAt}
+	if code != nil {
+		dest = append([]any{code}, dest...)
+	}
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(chain), &h.RedirectChain)
+}
+
+func (s *healthStore) Upsert(ctx context.Context, code string, h model.LinkHealth) error {
+	chain, err := json.Marshal(h.RedirectChain)
+	if err != nil {
+		return err
+	}
+	if h.RedirectChain == nil {
+		chain = []byte("[]")
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO link_health (code, `+healthColumns+`)
+	 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	 ON CONFLICT (code) DO UPDATE SET
+		status = EXCLUDED.status, method = EXCLUDED.method, status_code = EXCLUDED.status_code,
+		latency_ms = EXCLUDED.latency_ms, tls_expires_at = EXCLUDED.tls_expires_at,
+		redirect_chain = EXCLUDED.redirect_chain, error = EXCLUDED.error,
+		consecutive_failures = EXCLUDED.consecutive_failures, last_ok_at = EXCLUDED.last_ok_at,
+		checked_at = EXCLUDED.checked_at`,
+		code, h.Status, h.Method, h.StatusCode, h.LatencyMS, h.TLSExpiresAt, string(chain), h.Error,
+		h.ConsecutiveFailures, h.LastOKAt, h.CheckedAt)
+	return err
+}
+
+func (s *healthStore) GetByCode(ctx context.Context, code string) (model.LinkHealth, error) {
+	var h model.LinkHealth
+	row := s.db.QueryRowContext(ctx, `SELECT `+healthColumns+` FROM link_health WHERE code = $1`, code)
+	err := scanHealth(row, nil, &h)
+	return h, err
+}
+
+func (s *healthStore) GetAllByCode(ctx context.Context) (map[string]model.LinkHealth, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT code, `+healthColumns+` FROM link_health`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byCode := make(map[string]model.LinkHealth)
+	for rows.Next() {
+		var (
+			code string
+			h    model.LinkHealth
+		)
+		if err = scanHealth(rows, &code, &h); err != nil {
+			return nil, err
+		}
+		byCode[code] = h
+	}
+	return byCode, rows.Err()
+}
+
+// ListDue skips links that expired, haven't gone live yet or used up their clicks, oldest check
+// first.
+func (s *healthStore) ListDue(ctx context.Context, checkedBefore time.Time, limit int) ([]model.URL, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT u.code, u.long_url FROM urls u
+	 LEFT JOIN link_health h ON h.code = u.code
+	 WHERE (h.checked_at IS NULL OR h.checked_at < $1)
+	   AND (u.expires_at IS NULL OR u.expires_at > NOW())
+	   AND (u.activate_at IS NULL OR u.activate_at <= NOW())
+	   AND (u.remaining_clicks IS NULL OR u.remaining_clicks > 0)
+	 ORDER BY h.checked_at NULLS FIRST
+	 LIMIT $2`, checkedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var urls []model.URL
+	for rows.Next() {
+		var u model.URL
+		if err = rows.Scan(&u.Code, &u.LongURL); err != nil {
+			return nil, err
+		}
+		urls = append(urls, u)
+	}
+	return urls, rows.Err()
+}
+
+func (s *healthStore) Delete(ctx context.Context, code string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM link_health WHERE code = $1`, code)
+	return err
+}
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+)
+
+type Metadata interface {
+	Upsert(ctx context.Context, code string, meta model.LinkMetadata) error
+	GetByCode(ctx context.Context, code string) (model.LinkMetadata, error)
+	GetAllByCode(ctx context.Context) (map[string]model.LinkMetadata, error)
+	ListStale(ctx context.Context, fetchedBefore time.Time, limit int) ([]model.URL, error)
+	Delete(ctx context.Context, code string) error
+}
+
+type metadataStore struct {
+	db *sql.DB
+}
+
+func NewMetadataStore(db *sql.DB) Metadata {
+	return &metadataStore{db: db}
+}
+
+const metadataColumns = `title, description, og_title, og_description, og_image, og_site_name, og_type,
+	twitter_card, twitter_title, twitter_description, twitter_image, favicon_url, fetched_at, error`
+
+func scanMetadata(row interface{ Scan(...any) error }, code *string, m *model.LinkMetadata) error {
+	dest := []any{&m.Title, &m.Description, &m.OGTitle, &m.OGDescription, &m.OGImage, &m.OGSiteName, &m.OGType,
+		&m.TwitterCard, &m.TwitterTitle, &m.TwitterDescription, &m.TwitterImage, &m.FaviconURL, &m.FetchedAt, &m.Error}
+	if code != nil {
+		dest = append([]any{code}, dest...)
+	}
+	return row.Scan(dest...)
+}
+
+func (s *metadataStore) Upsert(ctx context.Context, code string, m model.LinkMetadata) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO url_metadata (code, `+metadataColumns+`)
+	 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	 ON CONFLICT (code) DO UPDATE SET
+		title = EXCLUDED.title, description = EXCLUDED.description,
+		og_title = EXCLUDED.og_title, og_description = EXCLUDED.og_description,
+		og_image = EXCLUDED.og_image, og_site_name = EXCLUDED.og_site_name, og_type = EXCLUDED.og_type,
+		twitter_card = EXCLUDED.twitter_card, twitter_title = EXCLUDED.twitter_title,
+		twitter_description = EXCLUDED.twitter_description, twitter_image = EXCLUDED.twitter_image,
+		favicon_url = EXCLUDED.favicon_url, fetched_at = EXCLUDED.fetched_at, error = EXCLUDED.error`,
+		code, m.Title, m.Description, m.OGTitle, m.OGDescription, m.OGImage, m.OGSiteName, m.OGType,
+		m.TwitterCard, m.TwitterTitle, m.TwitterDescription, m.TwitterImage, m.FaviconURL, m.FetchedAt, m.Error)
+	return err
+}
+
+func (s *metadataStore) GetByCode(ctx context.Context, code string) (model.LinkMetadata, error) {
+	var m model.LinkMetadata
+	row := s.db.QueryRowContext(ctx, `SELECT `+metadataColumns+` FROM url_metadata WHERE code = $1`, code)
+	err := scanMetadata(row, nil, &m)
+	return m, err
+}
+
+func (s *metadataStore) GetAllByCode(ctx context.Context) (map[string]model.LinkMetadata, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT code, `+metadataColumns+` FROM url_metadata`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byCode := make(map[string]model.LinkMetadata)
+	for rows.Next() {
+		var (
+			code string
+			m    model.LinkMetadata
+		)
+		if err = scanMetadata(rows, &code, &m); err != nil {
+			return nil, err
+		}
+		byCode[code] = m
+	}
+	return byCode, rows.Err()
+}
+
+// ListStale returns links whose metadata was never fetched or fetched before fetchedBefore,
+// oldest first.
+func (s *metadataStore) ListStale(ctx context.Context, fetchedBefore time.Time, limit int) ([]model.URL, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT u.code, u.long_url FROM urls u
+	 LEFT JOIN url_metadata m ON m.code = u.code
+	 WHERE (m.fetched_at IS NULL OR m.fetched_at < $1)
+	   AND (u.expires_at IS NULL OR u.expires_at > NOW())
+	 ORDER BY m.fetched_at NULLS FIRST
+	 LIMIT $2`, fetchedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var urls []model.URL
+	for rows.Next() {
+		var u model.URL
+		if err = rows.Scan(&u.Code, &u.LongURL); err != nil {
+			return nil, err
+		}
+		urls = append(urls, u)
+	}
+	return urls, rows.Err()
+}
+
+func (s *metadataStore) Delete(ctx context.Context, code string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM url_metadata WHERE code = $1`, code)
+	return err
+}
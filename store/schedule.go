@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+)
+
+type Schedule interface {
+	Create(ctx context.Context, change model.ScheduledChange) (model.ScheduledChange, error)
+	Pending(ctx context.Context, code string) ([]model.ScheduledChange, error)
+	// Cancel deletes a pending change of the link, sql.ErrNoRows if there is none with that ID.
+	Cancel(ctx context.Context, code string, id int64) error
+	DeleteAll(ctx context.Context, code string) error
+
+	// ApplyDue switches links to the latest of their changes due by now, for one link or for
+	// all of them when code is empty. It returns the new destinations by code.
+	ApplyDue(ctx context.Context, code string, now time.Time) (map[string]string, error)
+}
+
+type scheduleStore struct {
+	db *sql.DB
+}
+
+func NewScheduleStore(db *sql.DB) Schedule {
+	return &scheduleStore{db: db}
+}
+
+func (s *scheduleStore) Create(ctx context.Context, c model.ScheduledChange) (model.ScheduledChange, error) {
+	err := s.db.QueryRowContext(ctx,
+		`INSERT INTO scheduled_changes (code, long_url, change_at, created_at) VALUES ($1, $2, $3, $4) RETURNING id`,
+		c.Code, c.LongURL, c.At, c.CreatedAt).Scan(&c.ID)
+	return c, err
+}
+
+func (s *scheduleStore) Pending(ctx context.Context, code string) ([]model.ScheduledChange, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, code, long_url, change_at, created_at FROM scheduled_changes
+	 WHERE code = $1 AND applied_at IS NULL ORDER BY change_at, id`, code)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.ScheduledChange
+	for rows.Next() {
+		var c model.ScheduledChange
+		if err := rows.Scan(&c.ID, &c.Code, &c.LongURL, &c.At, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+func (s *scheduleStore) Cancel(ctx context.Context, code string, id int64) error {
+	result, err := s.db.ExecContext(ctx,
+		`DELETE FROM scheduled_changes WHERE id = $1 AND code = $2 AND applied_at IS NULL`, id, code)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (s *scheduleStore) DeleteAll(ctx context.Context, code string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM scheduled_changes WHERE code = $1`, code)
+	return err
+}
+
+// ApplyDue marks the due changes applied and updates the links in one statement. Concurrent
+// callers block on the locked change rows and then find nothing left to apply, so every change
+// is applied exactly once.
+func (s *scheduleStore) ApplyDue(ctx context.Context, code string, now time.Time) (map[string]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`WITH due AS (
+	     UPDATE scheduled_changes SET applied_at = $2
+	     WHERE applied_at IS NULL AND change_at <= $2 AND ($1 = '' OR code = $1)
+	     RETURNING id, code, long_url, change_at
+	 ), latest AS (
+	     SELECT DISTINCT ON (code) code, long_url FROM due ORDER BY code, change_at DESC, id DESC
+	 )
+	 UPDATE urls SET long_url = latest.long_url, long_url_hash = encode(sha256(convert_to(latest.long_url, 'UTF8')), 'hex')
+	 FROM latest WHERE urls.code = latest.code
+	 RETURNING urls.code, urls.long_url`, code, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[string]string)
+	for rows.Next() {
+		var c, longURL string
+		if err := rows.Scan(&c, &longURL); err != nil {
+			return nil, err
+		}
+		applied[c] = longURL
+	}
+	return applied, rows.Err()
+}
@@ -0,0 +1,202 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/lib/pq"
+)
+
+type Tag interface {
+	Create(ctx context.Context, name string) (model.Tag, error)
+	GetAll(ctx context.Context) ([]model.Tag, error)
+	GetByID(ctx context.Context, id int64) (model.Tag, error)
+	Rename(ctx context.Context, id int64, name string) error
+	Merge(ctx context.Context, targetID int64, sourceIDs []int64) error
+	Delete(ctx context.Context, id int64) error
+
+	Attach(ctx context.Context, code string, names []string) error
+	Detach(ctx context.Context, code string, names []string) error
+	DetachAll(ctx context.Context, code string) error
+	GetByCode(ctx context.Context, code string) ([]string, error)
+	GetAllByCode(ctx context.Context) (map[string][]string, error)
+	CodesByTag(ctx context.Context, name string) ([]string, error)
+}
+
+type tagStore struct {
+	db *sql.DB
+}
+
+func NewTagStore(db *sql.DB) Tag {
+	return &tagStore{db: db}
+}
+
+func (s *tagStore) Create(ctx context.Context, name string) (model.Tag, error) {
+	var t model.Tag
+	err := s.db.QueryRowContext(ctx,
+		`INSERT INTO tags (name, created_at) VALUES ($1, NOW()) RETURNING id, name, created_at`, name).
+		Scan(&t.ID, &t.Name, &t.CreatedAt)
+	return t, err
+}
+
+func (s *tagStore) GetAll(ctx context.Context) ([]model.Tag, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, name, created_at FROM tags ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []model.Tag
+	for rows.Next() {
+		var t model.Tag
+		if err = rows.Scan(&t.ID, &t.Name, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+func (s *tagStore) GetByID(ctx context.Context, id int64) (model.Tag, error) {
+	var t model.Tag
+	err := s.db.QueryRowContext(ctx,
+		`SELECT id, name, created_at FROM tags WHERE id = $1`, id).
+		Scan(&t.ID, &t.Name, &t.CreatedAt)
+	return t, err
+}
+
+// Rename only touches the tags row; links reference tags by id.
+func (s *tagStore) Rename(ctx context.Context, id int64, name string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE tags SET name = $1 WHERE id = $2`, name, id)
+	return err
+}
+
+// Merge moves every link tagged with one of sourceIDs onto targetID and drops the source tags.
+func (s *tagStore) Merge(ctx context.Context, targetID int64, sourceIDs []int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO url_tags (code, tag_id)
+	 SELECT code, $1 FROM url_tags WHERE tag_id = ANY($2)
+	 ON CONFLICT DO NOTHING`,
+		targetID, pq.Array(sourceIDs))
+	if err != nil {
+		return err
+	}
+
+	// url_tags rows of the source tags go away through ON DELETE CASCADE.
+	_, err = tx.ExecContext(ctx, `DELETE FROM tags WHERE id = ANY($1)`, pq.Array(sourceIDs))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *tagStore) Delete(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM tags WHERE id = $1`, id)
+	return err
+}
+
+// Attach links all names to code in one transaction, creating missing tags on the way.
+func (s *tagStore) Attach(ctx context.Context, code string, names []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, name := range names {
+		var id int64
+		err = tx.QueryRowContext(ctx,
+			`INSERT INTO tags (name, created_at) VALUES ($1, NOW())
+		 ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+		 RETURNING id`, name).Scan(&id)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO url_tags (code, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, code, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *tagStore) Detach(ctx context.Context, code string, names []string) error {
+	_, err := s.db.ExecContext(ctx,
+		`DELETE FROM url_tags
+	 WHERE code = $1 AND tag_id IN (SELECT id FROM tags WHERE name = ANY($2))`,
+		code, pq.Array(names))
+	return err
+}
+
+func (s *tagStore) DetachAll(ctx context.Context, code string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM url_tags WHERE code = $1`, code)
+	return err
+}
+
+func (s *tagStore) GetByCode(ctx context.Context, code string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT t.name FROM url_tags ut JOIN tags t ON t.id = ut.tag_id WHERE ut.code = $1 ORDER BY t.name`, code)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func (s *tagStore) GetAllByCode(ctx context.Context) (map[string][]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT ut.code, t.name FROM url_tags ut JOIN tags t ON t.id = ut.tag_id ORDER BY t.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byCode := make(map[string][]string)
+	for rows.Next() {
+		var code, name string
+		if err = rows.Scan(&code, &name); err != nil {
+			return nil, err
+		}
+		byCode[code] = append(byCode[code], name)
+	}
+	return byCode, rows.Err()
+}
+
+func (s *tagStore) CodesByTag(ctx context.Context, name string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT ut.code FROM url_tags ut JOIN tags t ON t.id = ut.tag_id WHERE t.name = $1`, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var codes []string
+	for rows.Next() {
+		var code string
+		if err = rows.Scan(&code); err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, rows.Err()
+}
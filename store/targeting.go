@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/lib/pq"
+)
+
+type Targeting interface {
+	Rules(ctx context.Context, code string) ([]model.TargetingRule, error)
+	// Replace swaps all rules of a link at once and returns them with their IDs.
+	Replace(ctx context.Context, code string, rules []model.TargetingRule) ([]model.TargetingRule, error)
+	DeleteAll(ctx context.Context, code string) error
+}
+
+type targetingStore struct {
+	db *sql.DB
+}
+
+func NewTargetingStore(db *sql.DB) Targeting {
+	return &targetingStore{db: db}
+}
+
+func (s *targetingStore) Rules(ctx context.Context, code string) ([]model.TargetingRule, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, code, priority, countries, languages, long_url FROM targeting_rules
+	 WHERE code = $1 ORDER BY priority, id`, code)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []model.TargetingRule
+	for rows.Next() {
+		var r model.TargetingRule
+		err := rows.Scan(&r.ID, &r.Code, &r.Priority, pq.Array(&r.Countries), pq.Array(&r.Languages), &r.LongURL)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
+
+func (s *targetingStore) Replace(ctx context.Context, code string, rules []model.TargetingRule) ([]model.TargetingRule, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM targeting_rules WHERE code = $1`, code); err != nil {
+		return nil, err
+	}
+
+	for i := range rules {
+		r := &rules[i]
+		err := tx.QueryRowContext(ctx,
+			`INSERT INTO targeting_rules (code, priority, countries, languages, long_url)
+		 VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+			code, r.Priority, pq.Array(r.Countries), pq.Array(r.Languages), r.LongURL).Scan(&r.ID)
+		if err != nil {
+			return nil, err
+		}
+		r.Code = code
+	}
+
+	return rules, tx.Commit()
+}
+
+func (s *targetingStore) DeleteAll(ctx context.Context, code string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM targeting_rules WHERE code = $1`, code)
+	return err
+}
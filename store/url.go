@@ -2,16 +2,33 @@ package store
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"github.com/Kritvi0208/ShortEdge/model"
+	"time"
 )
 
+// ErrCodeTaken is returned by Create when another link already has the code.
+var ErrCodeTaken = errors.New("code is already taken")
+
 type URL interface {
 	Create(ctx context.Context, url model.URL) error
 	GetAll(ctx context.Context) ([]model.URL, error)
 	GetByCode(ctx context.Context, code string) (model.URL, error)
 	Update(ctx context.Context, code string, updated model.URL) error
 	Delete(ctx context.Context, code string) error
+
+	// TakeClick uses up one of a click-limited link's remaining clicks, reporting false once
+	// none are left.
+	TakeClick(ctx context.Context, code string) (remaining int64, ok bool, err error)
+	// SetMaxClicks changes a link's click limit, keeping the clicks already used; nil removes it.
+	SetMaxClicks(ctx context.Context, code string, limit *int64) (remaining *int64, err error)
+	// SetDisabled stops a link from redirecting, or with a nil time lets it redirect again.
+	SetDisabled(ctx context.Context, code string, at *time.Time, reason string) error
+	// GetByLongURL finds the owner's links to exactly longURL, oldest first.
+	GetByLongURL(ctx context.Context, ownerID int64, longURL string) ([]model.URL, error)
 }
 
 type urlStore struct {
@@ -22,15 +39,63 @@ func NewURLStore(db *sql.DB) URL {
 	return &urlStore{db: db}
 }
 
+const urlColumns = `code, long_url, created_at, visibility, expires_at, folder_id, owner_id, COALESCE(created_by, ''), workspace_id, COALESCE(password_hash, ''),
+	max_clicks, remaining_clicks, activate_at, COALESCE(ios_app_url, ''), COALESCE(ios_store_url, ''),
+	COALESCE(android_app_url, ''), COALESCE(android_store_url, ''), COALESCE(domain, ''), disabled_at, COALESCE(disabled_reason, '')`
+
+func scanURL(row interface{ Scan(...any) error }) (model.URL, error) {
+	var u model.URL
+	var ios, android model.AppLink
+	err := row.Scan(&u.Code, &u.LongURL, &u.CreatedAt, &u.Visibility, &u.ExpiresAt, &u.FolderID, &u.OwnerID, &u.CreatedBy,
+		&u.WorkspaceID, &u.PasswordHash, &u.MaxClicks, &u.RemainingClicks, &u.ActivateAt, &ios.AppURL, &ios.StoreURL,
+		&android.AppURL, &android.StoreURL, &u.Domain, &u.DisabledAt, &u.DisabledReason)
+	u.PasswordProtected = u.PasswordHash != ""
+	if ios != (model.AppLink{}) {
+		u.IOS = &ios
+	}
+	if android != (model.AppLink{}) {
+		u.Android = &android
+	}
+	return u, err
+}
+
+// longURLHash is what the long_url_hash column indexes destinations by.
+func longURLHash(longURL string) string {
+	sum := sha256.Sum256([]byte(longURL))
+	return hex.EncodeToString(sum[:])
+}
+
+// appURLs flattens an optional app link into its columns.
+func appURLs(a *model.AppLink) (string, string) {
+	if a == nil {
+		return "", ""
+	}
+	return a.AppURL, a.StoreURL
+}
+
 func (s *urlStore) Create(ctx context.Context, url model.URL) error {
-	_, err := s.db.ExecContext(ctx,
-		`INSERT INTO urls (code, long_url, created_at, visibility, expires_at) VALUES ($1, $2, $3, $4, $5)`,
-		url.Code, url.LongURL, url.CreatedAt, url.Visibility, url.ExpiresAt)
-	return err
+	iosApp, iosStore := appURLs(url.IOS)
+	androidApp, androidStore := appURLs(url.Android)
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO urls (code, long_url, created_at, visibility, expires_at, folder_id, owner_id, created_by, workspace_id, password_hash,
+	 max_clicks, remaining_clicks, activate_at, ios_app_url, ios_store_url, android_app_url, android_store_url, domain, long_url_hash)
+	 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), $11, $11, $12,
+	 NULLIF($13, ''), NULLIF($14, ''), NULLIF($15, ''), NULLIF($16, ''), NULLIF($17, ''), $18)
+	 ON CONFLICT DO NOTHING`,
+		url.Code, url.LongURL, url.CreatedAt, url.Visibility, url.ExpiresAt, url.FolderID, url.OwnerID, url.CreatedBy,
+		url.WorkspaceID, url.PasswordHash, url.MaxClicks, url.ActivateAt, iosApp, iosStore, androidApp, androidStore, url.Domain,
+		longURLHash(url.LongURL))
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrCodeTaken
+	}
+	return nil
 }
 
 func (s *urlStore) GetAll(ctx context.Context) ([]model.URL, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT code, long_url, created_at, visibility, expires_at FROM urls`)
+	rows, err := s.db.QueryContext(ctx, `SELECT `+urlColumns+` FROM urls`)
 	if err != nil {
 		return nil, err
 	}
@@ -38,8 +103,7 @@ func (s *urlStore) GetAll(ctx context.Context) ([]model.URL, error) {
 
 	var urls []model.URL
 	for rows.Next() {
-		var u model.URL
-		err = rows.Scan(&u.Code, &u.LongURL, &u.CreatedAt, &u.Visibility, &u.ExpiresAt)
+		u, err := scanURL(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -49,18 +113,18 @@ func (s *urlStore) GetAll(ctx context.Context) ([]model.URL, error) {
 }
 
 func (s *urlStore) GetByCode(ctx context.Context, code string) (model.URL, error) {
-	var u model.URL
-	err := s.db.QueryRowContext(ctx,
-		`SELECT code, long_url, created_at, visibility, expires_at FROM urls WHERE code = $1`, code).
-		Scan(&u.Code, &u.LongURL, &u.CreatedAt, &u.Visibility, &u.ExpiresAt)
-
-	return u, err
+	return scanURL(s.db.QueryRowContext(ctx, `SELECT `+urlColumns+` FROM urls WHERE code = $1`, code))
 }
 
 func (s *urlStore) Update(ctx context.Context, code string, updated model.URL) error {
+	iosApp, iosStore := appURLs(updated.IOS)
+	androidApp, androidStore := appURLs(updated.Android)
 	_, err := s.db.ExecContext(ctx,
-		`UPDATE urls SET long_url = $1, visibility = $2, expires_at = $3 WHERE code = $4`,
-		updated.LongURL, updated.Visibility, updated.ExpiresAt, code)
+		`UPDATE urls SET long_url = $1, visibility = $2, expires_at = $3, folder_id = $4, workspace_id = $5,
+	 password_hash = NULLIF($6, ''), activate_at = $7, ios_app_url = NULLIF($8, ''), ios_store_url = NULLIF($9, ''),
+	 android_app_url = NULLIF($10, ''), android_store_url = NULLIF($11, ''), long_url_hash = $12 WHERE code = $13`,
+		updated.LongURL, updated.Visibility, updated.ExpiresAt, updated.FolderID, updated.WorkspaceID,
+		updated.PasswordHash, updated.ActivateAt, iosApp, iosStore, androidApp, androidStore, longURLHash(updated.LongURL), code)
 	return err
 }
 
@@ -69,3 +133,54 @@ func (s *urlStore) Delete(ctx context.Context, code string) error {
 		`DELETE FROM urls WHERE code = $1`, code)
 	return err
 }
+
+// TakeClick decrements in a single conditional UPDATE, so concurrent redirects can't use the
+// same click twice.
+func (s *urlStore) TakeClick(ctx context.Context, code string) (int64, bool, error) {
+	var remaining int64
+	err := s.db.QueryRowContext(ctx,
+		`UPDATE urls SET remaining_clicks = remaining_clicks - 1
+	 WHERE code = $1 AND remaining_clicks > 0 RETURNING remaining_clicks`, code).Scan(&remaining)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	return remaining, err == nil, err
+}
+
+func (s *urlStore) SetMaxClicks(ctx context.Context, code string, limit *int64) (*int64, error) {
+	var remaining *int64
+	err := s.db.QueryRowContext(ctx,
+		`UPDATE urls SET remaining_clicks = CASE
+	     WHEN $2::bigint IS NULL THEN NULL
+	     WHEN max_clicks IS NULL THEN $2::bigint
+	     ELSE GREATEST($2::bigint - (max_clicks - remaining_clicks), 0)
+	 END, max_clicks = $2::bigint
+	 WHERE code = $1 RETURNING remaining_clicks`, code, limit).Scan(&remaining)
+	return remaining, err
+}
+
+func (s *urlStore) SetDisabled(ctx context.Context, code string, at *time.Time, reason string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE urls SET disabled_at = $1, disabled_reason = NULLIF($2, '') WHERE code = $3`, at, reason, code)
+	return err
+}
+
+func (s *urlStore) GetByLongURL(ctx context.Context, ownerID int64, longURL string) ([]model.URL, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+urlColumns+` FROM urls
+	 WHERE long_url_hash = $1 AND owner_id = $2 AND long_url = $3 ORDER BY created_at`,
+		longURLHash(longURL), ownerID, longURL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var urls []model.URL
+	for rows.Next() {
+		u, err := scanURL(rows)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, u)
+	}
+	return urls, rows.Err()
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type Usage interface {
+	// Increment adds one to a counter unless that would take it past limit (0 means unlimited).
+	// It reports whether the counter was incremented.
+	Increment(ctx context.Context, workspaceID int64, metric string, period time.Time, limit int64) (bool, error)
+	Decrement(ctx context.Context, workspaceID int64, metric string, period time.Time) error
+	Get(ctx context.Context, workspaceID int64, metric string, period time.Time) (int64, error)
+}
+
+type usageStore struct {
+	db *sql.DB
+}
+
+func NewUsageStore(db *sql.DB) Usage {
+	return &usageStore{db: db}
+}
+
+// Increment checks and bumps the counter in one statement, so concurrent requests can't both take
+// the last unit of a quota.
+func (s *usageStore) Increment(ctx context.Context, workspaceID int64, metric string, period time.Time, limit int64) (bool, error) {
+	var count int64
+	err := s.db.QueryRowContext(ctx,
+		`INSERT INTO usage_counters (workspace_id, metric, period, count) VALUES ($1, $2, $3, 1)
+	 ON CONFLICT (workspace_id, metric, period) DO UPDATE SET count = usage_counters.count + 1
+	 WHERE $4::bigint = 0 OR usage_counters.count < $4::bigint
+	 RETURNING count`, workspaceID, metric, period, limit).Scan(&count)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *usageStore) Decrement(ctx context.Context, workspaceID int64, metric string, period time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE usage_counters SET count = GREATEST(count - 1, 0)
+	 WHERE workspace_id = $1 AND metric = $2 AND period = $3`, workspaceID, metric, period)
+	return err
+}
+
+func (s *usageStore) Get(ctx context.Context, workspaceID int64, metric string, period time.Time) (int64, error) {
+	var count int64
+	err := s.db.QueryRowContext(ctx,
+		`SELECT count FROM usage_counters WHERE workspace_id = $1 AND metric = $2 AND period = $3`,
+		workspaceID, metric, period).Scan(&count)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return count, err
+}
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+)
+
+type User interface {
+	Create(ctx context.Context, u model.User) (model.User, error)
+	GetByID(ctx context.Context, id int64) (model.User, error)
+	GetByEmail(ctx context.Context, email string) (model.User, error)
+	SetRole(ctx context.Context, id int64, role string) error
+
+	CreateSession(ctx context.Context, tokenHash string, userID int64, expiresAt time.Time) error
+	GetSessionUser(ctx context.Context, tokenHash string) (model.User, error)
+	DeleteSession(ctx context.Context, tokenHash string) error
+
+	GetByIdentity(ctx context.Context, issuer, subject string) (model.User, error)
+	LinkIdentity(ctx context.Context, issuer, subject string, userID int64) error
+}
+
+type userStore struct {
+	db *sql.DB
+}
+
+func NewUserStore(db *sql.DB) User {
+	return &userStore{db: db}
+}
+
+const userColumns = `id, email, password_hash, role, created_at, default_workspace_id`
+
+func scanUser(row interface{ Scan(...any) error }) (model.User, error) {
+	var u model.User
+	err := row.Scan(&u.ID, &u.Email, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.DefaultWorkspaceID)
+	return u, err
+}
+
+// Create inserts the user together with a personal workspace they own.
+func (s *userStore) Create(ctx context.Context, u model.User) (model.User, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.User{}, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO users (email, password_hash, role, created_at) VALUES ($1, $2, $3, $4) RETURNING id`,
+		u.Email, u.PasswordHash, u.Role, u.CreatedAt).Scan(&u.ID)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	ws, err := insertWorkspace(ctx, tx, model.Workspace{Name: u.Email, CreatedAt: u.CreatedAt}, u.ID)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE users SET default_workspace_id = $1 WHERE id = $2`, ws.ID, u.ID); err != nil {
+		return model.User{}, err
+	}
+	u.DefaultWorkspaceID = &ws.ID
+
+	return u, tx.Commit()
+}
+
+func (s *userStore) GetByID(ctx context.Context, id int64) (model.User, error) {
+	return scanUser(s.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, id))
+}
+
+func (s *userStore) GetByEmail(ctx context.Context, email string) (model.User, error) {
+	return scanUser(s.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE email = $1`, email))
+}
+
+func (s *userStore) SetRole(ctx context.Context, id int64, role string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE users SET role = $1 WHERE id = $2`, role, id)
+	return err
+}
+
+func (s *userStore) CreateSession(ctx context.Context, tokenHash string, userID int64, expiresAt time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO sessions (token_hash, user_id, created_at, expires_at) VALUES ($1, $2, NOW(), $3)`,
+		tokenHash, userID, expiresAt)
+	return err
+}
+
+// GetSessionUser returns the owner of an unexpired session.
+func (s *userStore) GetSessionUser(ctx context.Context, tokenHash string) (model.User, error) {
+	return scanUser(s.db.QueryRowContext(ctx,
+		`SELECT u.id, u.email, u.password_hash, u.role, u.created_at, u.default_workspace_id
+	 FROM sessions s JOIN users u ON u.id = s.user_id
+	 WHERE s.token_hash = $1 AND s.expires_at > NOW()`, tokenHash))
+}
+
+func (s *userStore) DeleteSession(ctx context.Context, tokenHash string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE token_hash = $1`, tokenHash)
+	return err
+}
+
+// GetByIdentity returns the user an identity provider account is linked to.
+func (s *userStore) GetByIdentity(ctx context.Context, issuer, subject string) (model.User, error) {
+	return scanUser(s.db.QueryRowContext(ctx,
+		`SELECT u.id, u.email, u.password_hash, u.role, u.created_at, u.default_workspace_id
+	 FROM user_identities i JOIN users u ON u.id = i.user_id
+	 WHERE i.issuer = $1 AND i.subject = $2`, issuer, subject))
+}
+
+func (s *userStore) LinkIdentity(ctx context.Context, issuer, subject string, userID int64) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO user_identities (issuer, subject, user_id, created_at) VALUES ($1, $2, $3, NOW())
+	 ON CONFLICT (issuer, subject) DO NOTHING`, issuer, subject, userID)
+	return err
+}
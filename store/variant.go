@@ -0,0 +1,95 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/lib/pq"
+)
+
+type Variant interface {
+	// Variants lists the link's variants; archived ones are only included with archived set.
+	Variants(ctx context.Context, code string, archived bool) ([]model.Variant, error)
+	// Save updates variants with an ID, inserts the others and archives those not listed, all
+	// at once, and returns the link's variants.
+	Save(ctx context.Context, code string, variants []model.Variant) ([]model.Variant, error)
+	DeleteAll(ctx context.Context, code string) error
+}
+
+type variantStore struct {
+	db *sql.DB
+}
+
+func NewVariantStore(db *sql.DB) Variant {
+	return &variantStore{db: db}
+}
+
+func (s *variantStore) Variants(ctx context.Context, code string, archived bool) ([]model.Variant, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, code, name, long_url, weight FROM link_variants
+	 WHERE code = $1 AND ($2 OR archived_at IS NULL) ORDER BY id`, code, archived)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var variants []model.Variant
+	for rows.Next() {
+		var v model.Variant
+		if err := rows.Scan(&v.ID, &v.Code, &v.Name, &v.LongURL, &v.Weight); err != nil {
+			return nil, err
+		}
+		variants = append(variants, v)
+	}
+	return variants, rows.Err()
+}
+
+func (s *variantStore) Save(ctx context.Context, code string, variants []model.Variant) ([]model.Variant, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	keep := []int64{}
+	for i := range variants {
+		v := &variants[i]
+		if v.ID == 0 {
+			err = tx.QueryRowContext(ctx,
+				`INSERT INTO link_variants (code, name, long_url, weight) VALUES ($1, $2, $3, $4) RETURNING id`,
+				code, v.Name, v.LongURL, v.Weight).Scan(&v.ID)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			res, err := tx.ExecContext(ctx,
+				`UPDATE link_variants SET name = $3, long_url = $4, weight = $5
+			 WHERE id = $1 AND code = $2 AND archived_at IS NULL`,
+				v.ID, code, v.Name, v.LongURL, v.Weight)
+			if err != nil {
+				return nil, err
+			}
+			if n, _ := res.RowsAffected(); n == 0 {
+				return nil, fmt.Errorf("variant %d not found", v.ID)
+			}
+		}
+		v.Code = code
+		keep = append(keep, v.ID)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE link_variants SET archived_at = NOW()
+	 WHERE code = $1 AND archived_at IS NULL AND NOT (id = ANY($2))`, code, pq.Array(keep))
+	if err != nil {
+		return nil, err
+	}
+
+	return variants, tx.Commit()
+}
+
+func (s *variantStore) DeleteAll(ctx context.Context, code string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM link_variants WHERE code = $1`, code)
+	return err
+}
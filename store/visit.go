@@ -6,11 +6,18 @@ import (
 	"time"
 
 	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/lib/pq"
 )
 
 type Visit interface {
 	LogVisit(ctx context.Context, v model.Visit) error
 	GetAnalytics(ctx context.Context, code string) ([]model.Visit, error)
+	GetAnalyticsForCodes(ctx context.Context, codes []string) ([]model.Visit, error)
+
+	// LogPreview records a view of the link's preview page, which isn't a click.
+	LogPreview(ctx context.Context, v model.Visit) error
+	// PreviewCounts returns the number of preview views and of distinct visitors among them.
+	PreviewCounts(ctx context.Context, code string) (views, visitors int, err error)
 }
 
 type visitStore struct {
@@ -23,9 +30,9 @@ func NewVisitStore(db *sql.DB) Visit {
 
 func (s *visitStore) LogVisit(ctx context.Context, v model.Visit) error {
 	result, err := s.db.ExecContext(ctx,
-		`INSERT INTO visits (code, timestamp, ip, country, browser, device)
-	 VALUES ($1, $2, $3, $4, $5, $6)`,
-		v.Code, v.Timestamp.Format(time.RFC3339), v.IP, v.Country, v.Browser, v.Device)
+		`INSERT INTO visits (code, timestamp, ip, country, browser, device, rule_id, variant_id, visitor_key, source)
+	 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), NULLIF($10, ''))`,
+		v.Code, v.Timestamp.Format(time.RFC3339), v.IP, v.Country, v.Browser, v.Device, v.RuleID, v.VariantID, v.VisitorKey, v.Source)
 
 	if err != nil {
 		println("❌ Error logging visit:", err.Error())
@@ -40,7 +47,7 @@ func (s *visitStore) LogVisit(ctx context.Context, v model.Visit) error {
 
 func (s *visitStore) GetAnalytics(ctx context.Context, code string) ([]model.Visit, error) {
 	rows, err := s.db.QueryContext(ctx,
-		`SELECT timestamp, ip, country, browser, device FROM visits WHERE url_id = $1`, code)
+		`SELECT timestamp, ip, country, browser, device, rule_id, variant_id, COALESCE(visitor_key, ''), COALESCE(source, '') FROM visits WHERE code = $1`, code)
 	if err != nil {
 		return nil, err
 	}
@@ -49,7 +56,7 @@ func (s *visitStore) GetAnalytics(ctx context.Context, code string) ([]model.Vis
 	var visits []model.Visit
 	for rows.Next() {
 		var v model.Visit
-		err = rows.Scan(&v.Timestamp, &v.IP, &v.Country, &v.Browser, &v.Device)
+		err = rows.Scan(&v.Timestamp, &v.IP, &v.Country, &v.Browser, &v.Device, &v.RuleID, &v.VariantID, &v.VisitorKey, &v.Source)
 		if err != nil {
 			return nil, err
 		}
@@ -59,3 +66,41 @@ func (s *visitStore) GetAnalytics(ctx context.Context, code string) ([]model.Vis
 
 	return visits, nil
 }
+
+func (s *visitStore) GetAnalyticsForCodes(ctx context.Context, codes []string) ([]model.Visit, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT code, timestamp, ip, country, browser, device, rule_id, variant_id, COALESCE(visitor_key, ''), COALESCE(source, '') FROM visits WHERE code = ANY($1) ORDER BY timestamp`,
+		pq.Array(codes))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var visits []model.Visit
+	for rows.Next() {
+		var v model.Visit
+		err = rows.Scan(&v.Code, &v.Timestamp, &v.IP, &v.Country, &v.Browser, &v.Device, &v.RuleID, &v.VariantID, &v.VisitorKey, &v.Source)
+		if err != nil {
+			return nil, err
+		}
+		visits = append(visits, v)
+	}
+
+	return visits, nil
+}
+
+func (s *visitStore) LogPreview(ctx context.Context, v model.Visit) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO preview_views (code, timestamp, ip, country, browser, device, visitor_key)
+	 VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))`,
+		v.Code, v.Timestamp.Format(time.RFC3339), v.IP, v.Country, v.Browser, v.Device, v.VisitorKey)
+	return err
+}
+
+func (s *visitStore) PreviewCounts(ctx context.Context, code string) (int, int, error) {
+	var views, visitors int
+	err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*), COUNT(DISTINCT visitor_key) FROM preview_views WHERE code = $1`, code).
+		Scan(&views, &visitors)
+	return views, visitors, err
+}
@@ -0,0 +1,119 @@
+package store_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Kritvi0208/ShortEdge/model"
+	"github.com/Kritvi0208/ShortEdge/service"
+	"github.com/Kritvi0208/ShortEdge/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// anyLink lets every caller read every link's analytics.
+type anyLink struct{}
+
+func (anyLink) AuthorizeAnalytics(ctx context.Context, code string) error { return nil }
+
+func (anyLink) AnalyticsCodes(ctx context.Context, filter model.URLFilter) ([]string, error) {
+	return nil, nil
+}
+
+var visitColumns = []string{"timestamp", "ip", "country", "browser", "device", "rule_id", "variant_id", "visitor_key", "source"}
+
+// expectVisits makes the mock answer the visits query of code with rows.
+func expectVisits(mock sqlmock.Sqlmock, code string, rows ...[]driver.Value) {
+	result := sqlmock.NewRows(visitColumns)
+	for _, r := range rows {
+		result.AddRow(r...)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("FROM visits WHERE code = $1")).WithArgs(code).WillReturnRows(result)
+}
+
+func TestGetAnalytics_RuleBreakdown(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	now := time.Now()
+	expectVisits(mock, "shop",
+		[]driver.Value{now, "203.0.113.1", "DE", "Firefox", "Desktop", int64(1), nil, "a", ""},
+		[]driver.Value{now, "203.0.113.2", "DE", "Firefox", "Desktop", int64(1), nil, "b", ""},
+		[]driver.Value{now, "203.0.113.3", "US", "Chrome", "Mobile", nil, nil, "c", ""},
+	)
+
+	visits := service.NewVisitService(store.NewVisitStore(db), anyLink{})
+	breakdown, err := visits.RuleBreakdown(context.Background(), "shop")
+	require.NoError(t, err)
+	rule := int64(1)
+	assert.Equal(t, []model.RuleClicks{{RuleID: &rule, Clicks: 2}, {Clicks: 1}}, breakdown)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAnalytics_VariantBreakdown(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	now := time.Now()
+	expectVisits(mock, "launch",
+		[]driver.Value{now, "203.0.113.1", "DE", "Firefox", "Desktop", nil, int64(1), "a", ""},
+		[]driver.Value{now, "203.0.113.1", "DE", "Firefox", "Desktop", nil, int64(1), "a", ""},
+		[]driver.Value{now, "203.0.113.2", "US", "Chrome", "Mobile", nil, int64(2), "b", ""},
+	)
+
+	visits := service.NewVisitService(store.NewVisitStore(db), anyLink{})
+	breakdown, err := visits.VariantBreakdown(context.Background(), "launch")
+	require.NoError(t, err)
+	first, second := int64(1), int64(2)
+	assert.Equal(t, []model.VariantClicks{
+		{VariantID: &first, Clicks: 2, UniqueVisitors: 1},
+		{VariantID: &second, Clicks: 1, UniqueVisitors: 1},
+	}, breakdown)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPreviewStats_CountsClicks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	now := time.Now()
+	expectVisits(mock, "launch",
+		[]driver.Value{now, "203.0.113.1", "DE", "Firefox", "Desktop", nil, nil, "a", ""},
+		[]driver.Value{now, "203.0.113.2", "US", "Chrome", "Mobile", nil, nil, "b", ""},
+	)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM preview_views WHERE code = $1")).
+		WithArgs("launch").
+		WillReturnRows(sqlmock.NewRows([]string{"count", "visitors"}).AddRow(5, 3))
+
+	visits := service.NewVisitService(store.NewVisitStore(db), anyLink{})
+	stats, err := visits.PreviewStats(context.Background(), "launch")
+	require.NoError(t, err)
+	assert.Equal(t, model.PreviewStats{Code: "launch", Previews: 5, UniqueVisitors: 3, Clicks: 2}, stats)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAnalytics_SourceBreakdown(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	now := time.Now()
+	expectVisits(mock, "launch",
+		[]driver.Value{now, "203.0.113.1", "DE", "Firefox", "Desktop", nil, nil, "a", "qr"},
+		[]driver.Value{now, "203.0.113.2", "US", "Chrome", "Mobile", nil, nil, "b", "qr"},
+		[]driver.Value{now, "203.0.113.3", "IN", "Safari", "Mobile", nil, nil, "c", ""},
+	)
+
+	visits := service.NewVisitService(store.NewVisitStore(db), anyLink{})
+	breakdown, err := visits.SourceBreakdown(context.Background(), "launch")
+	require.NoError(t, err)
+	assert.Equal(t, []model.SourceClicks{{Source: "qr", Clicks: 2}, {Source: "", Clicks: 1}}, breakdown)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,281 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Kritvi0208/ShortEdge/model"
+)
+
+type Workspace interface {
+	Create(ctx context.Context, ws model.Workspace, ownerID int64) (model.Workspace, error)
+	GetByID(ctx context.Context, id int64) (model.Workspace, error)
+	ListForUser(ctx context.Context, userID int64) ([]model.Workspace, error)
+	Rename(ctx context.Context, id int64, name string) error
+	SetPlan(ctx context.Context, id int64, plan string) error
+	SetCodeStyle(ctx context.Context, id int64, style string) error
+
+	// Role returns the user's role in the workspace, sql.ErrNoRows if they aren't a member.
+	Role(ctx context.Context, workspaceID, userID int64) (string, error)
+	Roles(ctx context.Context, userID int64) (map[int64]string, error)
+	Members(ctx context.Context, workspaceID int64) ([]model.Member, error)
+	AddMember(ctx context.Context, workspaceID, userID int64, role string) error
+	SetRole(ctx context.Context, workspaceID, userID int64, role string) error
+	RemoveMember(ctx context.Context, workspaceID, userID int64) error
+	CountOwners(ctx context.Context, workspaceID int64) (int, error)
+
+	CreateInvitation(ctx context.Context, inv model.Invitation, tokenHash string) (model.Invitation, error)
+	GetInvitation(ctx context.Context, id int64) (model.Invitation, error)
+	GetInvitationByHash(ctx context.Context, tokenHash string) (model.Invitation, error)
+	Invitations(ctx context.Context, workspaceID int64) ([]model.Invitation, error)
+	DeleteInvitation(ctx context.Context, id int64) error
+	AcceptInvitation(ctx context.Context, inv model.Invitation, userID int64, at time.Time) error
+}
+
+type workspaceStore struct {
+	db *sql.DB
+}
+
+func NewWorkspaceStore(db *sql.DB) Workspace {
+	return &workspaceStore{db: db}
+}
+
+type execQueryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func insertWorkspace(ctx context.Context, db execQueryer, ws model.Workspace, ownerID int64) (model.Workspace, error) {
+	err := db.QueryRowContext(ctx,
+		`INSERT INTO workspaces (name, created_at) VALUES ($1, $2) RETURNING id`, ws.Name, ws.CreatedAt).Scan(&ws.ID)
+	if err != nil {
+		return model.Workspace{}, err
+	}
+
+	_, err = db.ExecContext(ctx,
+		`INSERT INTO workspace_members (workspace_id, user_id, role, created_at) VALUES ($1, $2, $3, $4)`,
+		ws.ID, ownerID, model.WorkspaceOwner, ws.CreatedAt)
+	ws.Role = model.WorkspaceOwner
+	return ws, err
+}
+
+// Create inserts a workspace with ownerID as its first owner.
+func (s *workspaceStore) Create(ctx context.Context, ws model.Workspace, ownerID int64) (model.Workspace, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.Workspace{}, err
+	}
+	defer tx.Rollback()
+
+	ws, err = insertWorkspace(ctx, tx, ws, ownerID)
+	if err != nil {
+		return model.Workspace{}, err
+	}
+
+	return ws, tx.Commit()
+}
+
+func (s *workspaceStore) GetByID(ctx context.Context, id int64) (model.Workspace, error) {
+	var ws model.Workspace
+	err := s.db.QueryRowContext(ctx, `SELECT id, name, COALESCE(plan, ''), COALESCE(code_style, ''), created_at FROM workspaces WHERE id = $1`, id).
+		Scan(&ws.ID, &ws.Name, &ws.Plan, &ws.CodeStyle, &ws.CreatedAt)
+	return ws, err
+}
+
+func (s *workspaceStore) ListForUser(ctx context.Context, userID int64) ([]model.Workspace, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT w.id, w.name, COALESCE(w.plan, ''), COALESCE(w.code_style, ''), w.created_at, m.role
+	 FROM workspace_members m JOIN workspaces w ON w.id = m.workspace_id
+	 WHERE m.user_id = $1 ORDER BY w.name`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.Workspace
+	for rows.Next() {
+		var ws model.Workspace
+		if err := rows.Scan(&ws.ID, &ws.Name, &ws.Plan, &ws.CodeStyle, &ws.CreatedAt, &ws.Role); err != nil {
+			return nil, err
+		}
+		list = append(list, ws)
+	}
+	return list, rows.Err()
+}
+
+func (s *workspaceStore) Rename(ctx context.Context, id int64, name string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE workspaces SET name = $1 WHERE id = $2`, name, id)
+	return err
+}
+
+func (s *workspaceStore) SetPlan(ctx context.Context, id int64, plan string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE workspaces SET plan = NULLIF($1, '') WHERE id = $2`, plan, id)
+	return err
+}
+
+func (s *workspaceStore) SetCodeStyle(ctx context.Context, id int64, style string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE workspaces SET code_style = NULLIF($1, '') WHERE id = $2`, style, id)
+	return err
+}
+
+func (s *workspaceStore) Role(ctx context.Context, workspaceID, userID int64) (string, error) {
+	var role string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT role FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`, workspaceID, userID).
+		Scan(&role)
+	return role, err
+}
+
+// Roles maps every workspace the user belongs to onto their role in it.
+func (s *workspaceStore) Roles(ctx context.Context, userID int64) (map[int64]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT workspace_id, role FROM workspace_members WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := make(map[int64]string)
+	for rows.Next() {
+		var (
+			id   int64
+			role string
+		)
+		if err := rows.Scan(&id, &role); err != nil {
+			return nil, err
+		}
+		roles[id] = role
+	}
+	return roles, rows.Err()
+}
+
+func (s *workspaceStore) Members(ctx context.Context, workspaceID int64) ([]model.Member, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT m.workspace_id, m.user_id, u.email, m.role, m.created_at
+	 FROM workspace_members m JOIN users u ON u.id = m.user_id
+	 WHERE m.workspace_id = $1 ORDER BY u.email`, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []model.Member
+	for rows.Next() {
+		var m model.Member
+		if err := rows.Scan(&m.WorkspaceID, &m.UserID, &m.Email, &m.Role, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// AddMember adds a user to a workspace, or changes their role if they already are a member.
+func (s *workspaceStore) AddMember(ctx context.Context, workspaceID, userID int64, role string) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO workspace_members (workspace_id, user_id, role, created_at) VALUES ($1, $2, $3, NOW())
+	 ON CONFLICT (workspace_id, user_id) DO UPDATE SET role = EXCLUDED.role`, workspaceID, userID, role)
+	return err
+}
+
+func (s *workspaceStore) SetRole(ctx context.Context, workspaceID, userID int64, role string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE workspace_members SET role = $1 WHERE workspace_id = $2 AND user_id = $3`, role, workspaceID, userID)
+	return err
+}
+
+func (s *workspaceStore) RemoveMember(ctx context.Context, workspaceID, userID int64) error {
+	_, err := s.db.ExecContext(ctx,
+		`DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`, workspaceID, userID)
+	return err
+}
+
+func (s *workspaceStore) CountOwners(ctx context.Context, workspaceID int64) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM workspace_members WHERE workspace_id = $1 AND role = $2`,
+		workspaceID, model.WorkspaceOwner).Scan(&n)
+	return n, err
+}
+
+const invitationColumns = `id, workspace_id, email, role, invited_by, expires_at, accepted_at, created_at`
+
+func scanInvitation(row interface{ Scan(...any) error }) (model.Invitation, error) {
+	var inv model.Invitation
+	err := row.Scan(&inv.ID, &inv.WorkspaceID, &inv.Email, &inv.Role, &inv.InvitedBy, &inv.ExpiresAt,
+		&inv.AcceptedAt, &inv.CreatedAt)
+	return inv, err
+}
+
+func (s *workspaceStore) CreateInvitation(ctx context.Context, inv model.Invitation, tokenHash string) (model.Invitation, error) {
+	err := s.db.QueryRowContext(ctx,
+		`INSERT INTO workspace_invitations (workspace_id, email, role, token_hash, invited_by, expires_at, created_at)
+	 VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+		inv.WorkspaceID, inv.Email, inv.Role, tokenHash, inv.InvitedBy, inv.ExpiresAt, inv.CreatedAt).Scan(&inv.ID)
+	return inv, err
+}
+
+func (s *workspaceStore) GetInvitation(ctx context.Context, id int64) (model.Invitation, error) {
+	return scanInvitation(s.db.QueryRowContext(ctx,
+		`SELECT `+invitationColumns+` FROM workspace_invitations WHERE id = $1`, id))
+}
+
+func (s *workspaceStore) GetInvitationByHash(ctx context.Context, tokenHash string) (model.Invitation, error) {
+	return scanInvitation(s.db.QueryRowContext(ctx,
+		`SELECT `+invitationColumns+` FROM workspace_invitations WHERE token_hash = $1`, tokenHash))
+}
+
+func (s *workspaceStore) Invitations(ctx context.Context, workspaceID int64) ([]model.Invitation, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+invitationColumns+` FROM workspace_invitations WHERE workspace_id = $1 ORDER BY created_at DESC`,
+		workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.Invitation
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, inv)
+	}
+	return list, rows.Err()
+}
+
+func (s *workspaceStore) DeleteInvitation(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM workspace_invitations WHERE id = $1`, id)
+	return err
+}
+
+// AcceptInvitation marks the invitation used and adds the member in one transaction. Users who
+// are already members keep their role.
+func (s *workspaceStore) AcceptInvitation(ctx context.Context, inv model.Invitation, userID int64, at time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE workspace_invitations SET accepted_at = $1 WHERE id = $2 AND accepted_at IS NULL`, at, inv.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO workspace_members (workspace_id, user_id, role, created_at) VALUES ($1, $2, $3, $4)
+	 ON CONFLICT (workspace_id, user_id) DO NOTHING`,
+		inv.WorkspaceID, userID, inv.Role, at)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,154 @@
+// Package urlnorm validates link destinations and brings them into one canonical form, so the
+// same page isn't stored under several spellings and malformed URLs are caught on creation.
+package urlnorm
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// DefaultMaxLength is the longest URL accepted unless configured otherwise; longer ones break
+// in some browsers, proxies and QR codes.
+const DefaultMaxLength = 2048
+
+var (
+	ErrEmpty        = errors.New("is required")
+	ErrTooLong      = errors.New("is too long")
+	ErrInvalid      = errors.New("is not a valid URL")
+	ErrNotAbsolute  = errors.New("must be an absolute URL with a scheme, such as https://")
+	ErrInvalidHost  = errors.New("has an invalid host")
+	ErrInvalidPort  = errors.New("has an invalid port")
+	ErrSelfReferral = errors.New("points back at ShortEdge, which would redirect in a loop")
+)
+
+type Config struct {
+	MaxLength        int      // 0 means DefaultMaxLength
+	StripDefaultPort bool     // drop :80 from http and :443 from https URLs
+	StripFragment    bool     // drop #fragments, which only matter to the page itself
+	OwnHosts         []string // hosts or base URLs ShortEdge answers on; destinations can't point there
+}
+
+type Normalizer struct {
+	maxLength        int
+	stripDefaultPort bool
+	stripFragment    bool
+	own              map[string]bool
+}
+
+func New(cfg Config) *Normalizer {
+	if cfg.MaxLength <= 0 {
+		cfg.MaxLength = DefaultMaxLength
+	}
+
+	n := &Normalizer{
+		maxLength:        cfg.MaxLength,
+		stripDefaultPort: cfg.StripDefaultPort,
+		stripFragment:    cfg.StripFragment,
+		own:              make(map[string]bool),
+	}
+	for _, host := range cfg.OwnHosts {
+		// Base URLs such as https://sho.rt are fine too
+		if u, err := url.Parse(host); err == nil && u.Host != "" {
+			host = u.Host
+		}
+		if host, err := Host(host); err == nil && host != "" {
+			n.own[host] = true
+		}
+	}
+	return n
+}
+
+// Host brings a host name into the form Normalize gives it: lowercase, punycode for
+// international names and without a port or trailing dot.
+func Host(host string) (string, error) {
+	host = strings.TrimSpace(host)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.Trim(host, "[]"), ".")
+
+	if ip := net.ParseIP(host); ip != nil {
+		return strings.ToLower(host), nil
+	}
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		return "", err
+	}
+	return ascii, nil
+}
+
+// Normalize validates raw as an absolute URL and returns its canonical form: lowercase scheme
+// and host, international host names in punycode and, as configured, without default ports and
+// fragments. Errors describe the problem for a field's error message, e.g. "is too long".
+func (n *Normalizer) Normalize(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", ErrEmpty
+	}
+	if len(raw) > n.maxLength {
+		return "", fmt.Errorf("%w, the limit is %d characters", ErrTooLong, n.maxLength)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", ErrInvalid
+	}
+	if u.Scheme == "" {
+		return "", ErrNotAbsolute
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+
+	// Opaque URLs such as mailto:team@example.com have no host to normalize
+	if u.Opaque != "" {
+		return n.finish(u)
+	}
+	if u.Host == "" {
+		return "", ErrNotAbsolute
+	}
+
+	host, err := Host(u.Hostname())
+	if err != nil || host == "" {
+		return "", ErrInvalidHost
+	}
+	if n.own[host] {
+		return "", ErrSelfReferral
+	}
+
+	port := u.Port()
+	if port != "" {
+		if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+			return "", ErrInvalidPort
+		}
+	}
+	if n.stripDefaultPort && ((u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443")) {
+		port = ""
+	}
+
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	u.Host = host
+
+	return n.finish(u)
+}
+
+func (n *Normalizer) finish(u *url.URL) (string, error) {
+	if n.stripFragment {
+		u.Fragment, u.RawFragment = "", ""
+	}
+
+	s := u.String()
+	if len(s) > n.maxLength {
+		return "", fmt.Errorf("%w, the limit is %d characters", ErrTooLong, n.maxLength)
+	}
+	return s, nil
+}
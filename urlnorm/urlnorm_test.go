@@ -0,0 +1,60 @@
+package urlnorm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	n := New(Config{StripDefaultPort: true, StripFragment: true, OwnHosts: []string{"sho.rt", "https://Links.Example.com:8443"}})
+
+	tests := []struct {
+		raw, want string
+	}{
+		{"  HTTPS://Example.COM/Path?Q=1  ", "https://example.com/Path?Q=1"},
+		{"http://example.com:80/a", "http://example.com/a"},
+		{"https://example.com:443/a", "https://example.com/a"},
+		{"https://example.com:80/a", "https://example.com:80/a"},
+		{"http://example.com:8080/a#section", "http://example.com:8080/a"},
+		{"https://Bücher.example/straße", "https://xn--bcher-kva.example/stra%C3%9Fe"},
+		{"https://xn--bcher-kva.example/", "https://xn--bcher-kva.example/"},
+		{"http://[2001:DB8::1]:80/", "http://[2001:db8::1]/"},
+		{"https://example.com./", "https://example.com/"},
+		{"MAILTO:team@example.com", "mailto:team@example.com"},
+	}
+	for _, tt := range tests {
+		got, err := n.Normalize(tt.raw)
+		require.NoError(t, err, tt.raw)
+		assert.Equal(t, tt.want, got, tt.raw)
+	}
+
+	errs := []struct {
+		raw  string
+		want error
+	}{
+		{"", ErrEmpty},
+		{"   ", ErrEmpty},
+		{"example.com/page", ErrNotAbsolute},
+		{"/relative/path", ErrNotAbsolute},
+		{"https://", ErrNotAbsolute},
+		{"http://exa mple.com/", ErrInvalid},
+		{"https://bad_host.example/", ErrInvalidHost},
+		{"https://example.com:99999/", ErrInvalidPort},
+		{"https://sho.rt/abc", ErrSelfReferral},
+		{"http://SHO.RT:80/abc", ErrSelfReferral},
+		{"https://links.example.com/abc", ErrSelfReferral},
+		{"https://example.com/" + strings.Repeat("a", DefaultMaxLength), ErrTooLong},
+	}
+	for _, tt := range errs {
+		_, err := n.Normalize(tt.raw)
+		assert.ErrorIs(t, err, tt.want, tt.raw)
+	}
+
+	keep := New(Config{})
+	got, err := keep.Normalize("https://Example.com:443/a#top")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com:443/a#top", got, "ports and fragments stay unless configured")
+}